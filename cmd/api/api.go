@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -21,7 +22,22 @@ func main() {
 	}
 
 	// Инициализируем конфигурацию
-	cfg := config.New()
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Проверяем конфигурацию до подключения к зависимостям
+	if err := cfg.Validate(); err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Problems {
+				log.Printf("Config problem: %s: %s", problem.Env, problem.Message)
+			}
+			log.Fatalf("Refusing to start in %s environment: %d config problem(s)", cfg.AppEnv, len(validationErr.Problems))
+		}
+		log.Fatalf("Refusing to start: %v", err)
+	}
 
 	// Создаем логгер
 	logger, err := config.NewLogger(cfg)
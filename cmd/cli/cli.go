@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/jwtkeys"
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/mailer"
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/storage"
+	"github.com/TeDenis/bukhindor-backend/internal/app"
 	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/monitoring"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 	"github.com/spf13/cobra"
@@ -18,6 +27,11 @@ func main() {
 
 	// Добавляем команды
 	rootCmd.AddCommand(migrateCmd())
+	rootCmd.AddCommand(usersCmd())
+	rootCmd.AddCommand(permissionsCmd())
+	rootCmd.AddCommand(keysCmd())
+	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(maintenanceCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
@@ -42,7 +56,10 @@ func migrateUpCmd() *cobra.Command {
 		Use:   "up",
 		Short: "Применить все миграции",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := config.New()
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
 			return runMigrations(cfg, "up")
 		},
 	}
@@ -53,7 +70,10 @@ func migrateDownCmd() *cobra.Command {
 		Use:   "down",
 		Short: "Откатить последнюю миграцию",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := config.New()
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
 			return runMigrations(cfg, "down")
 		},
 	}
@@ -64,12 +84,91 @@ func migrateStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Показать статус миграций",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := config.New()
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
 			return runMigrations(cfg, "status")
 		},
 	}
 }
 
+func usersCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "users",
+		Short: "Управление пользователями",
+	}
+
+	cmd.AddCommand(usersSetRoleCmd())
+
+	return cmd
+}
+
+func usersSetRoleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role <email> <admin|user|guest>",
+		Short: "Назначить роль пользователю (например, первого администратора)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			role := domain.UserRole(args[1])
+			if !app.IsValidRole(role) {
+				return fmt.Errorf("unknown role %q", args[1])
+			}
+
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
+
+			logger, err := config.NewLogger(cfg)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = logger.Sync() }()
+
+			ctx := cmd.Context()
+			db, err := storage.ConnectPostgres(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer db.Close()
+
+			// Redis нужен, чтобы отозвать токены пользователя с прежней ролью
+			redisClient, err := storage.ConnectRedis(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to Redis: %w", err)
+			}
+			defer func() { _ = redisClient.Close() }()
+
+			store := storage.NewService(db, redisClient, cfg, logger)
+			user, err := store.GetUserByEmail(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("user %s not found", args[0])
+			}
+
+			// Сервис собирается так же, как в API, чтобы смена роли не падала на nil зависимостях
+			keys, err := jwtkeys.Load(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load JWT keys: %w", err)
+			}
+			mailService, err := mailer.NewService(cfg, logger)
+			if err != nil {
+				return fmt.Errorf("failed to create mailer: %w", err)
+			}
+			// Дожидаемся отправки писем, поставленных в очередь во время смены роли
+			defer func() { _ = mailService.Close(context.Background()) }()
+
+			service := auth.NewService(store, store, store, store, store, store, store, store, store, store, mailService, keys, monitoring.NewMetrics(logger), cfg.PasswordPolicy(), cfg, logger)
+			if _, err := service.SetUserRole(ctx, auth.SetUserRoleInput{UserID: user.ID, Role: role}); err != nil {
+				return err
+			}
+
+			log.Printf("Role %s assigned to %s", role, args[0])
+			return nil
+		},
+	}
+}
+
 func runMigrations(cfg *config.Config, command string) error {
 	// Подключаемся к базе данных PostgreSQL через stdlib драйвер pgx
 	dsn := cfg.GetPostgresDSN()
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+func configCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "config",
+		Short: "Работа с конфигурацией",
+	}
+
+	cmd.AddCommand(configPrintCmd())
+
+	return cmd
+}
+
+func configPrintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "print",
+		Short: "Показать действующую конфигурацию (секреты скрыты)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Как и API, учитываем .env из текущего каталога
+			_ = godotenv.Load()
+
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
+
+			for _, setting := range cfg.Settings() {
+				fmt.Printf("%s=%s\n", setting.Env, setting.Value)
+			}
+
+			if err := cfg.Validate(); err != nil {
+				var validationErr *config.ValidationError
+				if errors.As(err, &validationErr) {
+					for _, problem := range validationErr.Problems {
+						log.Printf("Config problem: %s: %s", problem.Env, problem.Message)
+					}
+				}
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/jwtkeys"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func keysCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "keys",
+		Short: "Управление ключами подписи JWT в JWT_KEYS_DIR",
+	}
+
+	cmd.AddCommand(keysListCmd())
+	cmd.AddCommand(keysRotateCmd())
+
+	return cmd
+}
+
+func keysListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Показать ключи; * отмечает ключ подписи",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
+			if cfg.JWTKeysDir == "" {
+				return errors.New("JWT_KEYS_DIR is not set, tokens are signed with HS256 and JWT_SECRET")
+			}
+
+			keySet, err := jwtkeys.Load(cfg)
+			if err != nil {
+				return err
+			}
+			keys, err := jwtkeys.LoadDir(cfg.JWTKeysDir)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				mark := " "
+				if key.ID == keySet.SigningKey().ID {
+					mark = "*"
+				}
+				usage := "verify"
+				if key.CanSign() {
+					usage = "sign, verify"
+				}
+				fmt.Printf("%s %s\t%s\t%s\n", mark, key.ID, key.Method.Alg(), usage)
+			}
+			return nil
+		},
+	}
+}
+
+func keysRotateCmd() *cobra.Command {
+	var alg string
+	var keep int
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Создать новый ключ; подписывать им начинают после смены JWT_SIGNING_KEY_ID на всех репликах",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
+			if cfg.JWTKeysDir == "" {
+				return errors.New("JWT_KEYS_DIR is not set")
+			}
+
+			// Ключ, которым подписывают сейчас; пусто, если ключей еще нет
+			var previous string
+			if keySet, loadErr := jwtkeys.Load(cfg); loadErr == nil {
+				previous = keySet.SigningKey().ID
+			}
+
+			kid, err := jwtkeys.Generate(cfg.JWTKeysDir, alg, time.Now())
+			if err != nil {
+				return err
+			}
+			log.Printf("Key %s (%s) created in %s", kid, alg, cfg.JWTKeysDir)
+
+			// Ключ, закрепленный через JWT_SIGNING_KEY_ID, не удаляем, иначе сервис не запустится.
+			// Без закрепления сохраняем прежний ключ подписи, чтобы его можно было закрепить
+			keepKeyID := cfg.JWTSigningKeyID
+			if keepKeyID == "" {
+				keepKeyID = previous
+			}
+			removed, err := jwtkeys.Prune(cfg.JWTKeysDir, keep, keepKeyID)
+			for _, id := range removed {
+				log.Printf("Key %s removed", id)
+			}
+			if err != nil {
+				return err
+			}
+
+			// Новый ключ должен появиться у всех реплик раньше, чем какая-либо из них начнет им подписывать
+			if cfg.JWTSigningKeyID != "" {
+				log.Printf("Key %s is used for verification only while JWT_SIGNING_KEY_ID=%s", kid, cfg.JWTSigningKeyID)
+			} else {
+				log.Printf("JWT_SIGNING_KEY_ID is not set: each replica starts signing with %s after restart, replicas without the key reject those tokens", kid)
+				if previous != "" {
+					log.Printf("Pin the current key first: JWT_SIGNING_KEY_ID=%s", previous)
+				}
+			}
+			log.Printf("1. Deploy key %s to all replicas and restart them", kid)
+			log.Printf("2. Set JWT_SIGNING_KEY_ID=%s and restart the replicas again", kid)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&alg, "alg", "RS256", "Алгоритм нового ключа (RS256, ES256, EdDSA)")
+	cmd.Flags().IntVar(&keep, "keep", 0, "Сколько последних ключей оставить (0 - не удалять); держите ключи не меньше срока жизни refresh токенов")
+
+	return cmd
+}
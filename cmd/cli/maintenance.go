@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/storage"
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/service/maintenance"
+	"github.com/spf13/cobra"
+)
+
+func maintenanceCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "maintenance",
+		Short: "Задачи обслуживания базы данных",
+	}
+
+	cmd.AddCommand(maintenanceRunCmd())
+
+	return cmd
+}
+
+func maintenanceRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("run <%s>", strings.Join(maintenance.Jobs(), "|")),
+		Short: "Выполнить задачу обслуживания вне расписания",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.New()
+			if err != nil {
+				return err
+			}
+
+			logger, err := config.NewLogger(cfg)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = logger.Sync() }()
+
+			db, err := storage.ConnectPostgres(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer db.Close()
+
+			// Задачам нужен только PostgreSQL; блокировка не даст выполнить задачу параллельно с репликами API
+			store := storage.NewService(db, nil, cfg, logger)
+			service := maintenance.NewService(store, store, store, store, store, store, nil, cfg, logger)
+
+			if err := service.Run(cmd.Context(), args[0]); err != nil {
+				if errors.Is(err, app.ErrJobNotFound) {
+					return fmt.Errorf("unknown job %q, available: %s", args[0], strings.Join(maintenance.Jobs(), ", "))
+				}
+				return err
+			}
+
+			log.Printf("Job %s completed", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/storage"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/authz"
+	"github.com/spf13/cobra"
+)
+
+func permissionsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "permissions",
+		Short: "Управление правами доступа ролей и пользователей",
+	}
+
+	cmd.AddCommand(permissionsListCmd())
+	cmd.AddCommand(permissionsChangeCmd("grant", "Выдать право роли или пользователю"))
+	cmd.AddCommand(permissionsChangeCmd("revoke", "Отозвать право у роли или пользователя"))
+
+	return cmd
+}
+
+func permissionsListCmd() *cobra.Command {
+	var role, email string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Показать права: все известные, роли (--role) или пользователя (--user)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAuthz(cmd.Context(), func(ctx context.Context, service *authz.Service, store *storage.Service) error {
+				var permissions []domain.Permission
+				var err error
+
+				switch {
+				case role != "":
+					permissions, err = service.RolePermissions(ctx, domain.UserRole(role))
+				case email != "":
+					user, lookupErr := store.GetUserByEmail(ctx, email)
+					if lookupErr != nil {
+						return fmt.Errorf("user %s not found", email)
+					}
+					permissions, err = service.EffectivePermissions(ctx, user.ID, user.Role)
+				default:
+					permissions, err = service.ListPermissions(ctx)
+				}
+				if err != nil {
+					return err
+				}
+
+				for _, permission := range permissions {
+					fmt.Println(permission)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "", "Роль (admin, user, guest)")
+	cmd.Flags().StringVar(&email, "user", "", "Email пользователя")
+
+	return cmd
+}
+
+func permissionsChangeCmd(action, short string) *cobra.Command {
+	var role, email string
+
+	cmd := &cobra.Command{
+		Use:   action + " <permission> (--role <role> | --user <email>)",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (role == "") == (email == "") {
+				return errors.New("exactly one of --role or --user must be set")
+			}
+			permission := domain.Permission(strings.TrimSpace(args[0]))
+
+			return withAuthz(cmd.Context(), func(ctx context.Context, service *authz.Service, store *storage.Service) error {
+				if role != "" {
+					if action == "grant" {
+						return service.GrantRolePermission(ctx, domain.UserRole(role), permission)
+					}
+					return service.RevokeRolePermission(ctx, domain.UserRole(role), permission)
+				}
+
+				user, err := store.GetUserByEmail(ctx, email)
+				if err != nil {
+					return fmt.Errorf("user %s not found", email)
+				}
+				if action == "grant" {
+					return service.GrantUserPermission(ctx, user.ID, permission)
+				}
+				return service.RevokeUserPermission(ctx, user.ID, permission)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "", "Роль (admin, user, guest)")
+	cmd.Flags().StringVar(&email, "user", "", "Email пользователя")
+
+	return cmd
+}
+
+// withAuthz подключается к PostgreSQL и Redis и передает сервис прав доступа в fn
+func withAuthz(ctx context.Context, fn func(ctx context.Context, service *authz.Service, store *storage.Service) error) error {
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+
+	logger, err := config.NewLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logger.Sync() }()
+
+	db, err := storage.ConnectPostgres(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	// Redis нужен, чтобы сбросить кеш прав и изменения применились без ожидания TTL
+	redisClient, err := storage.ConnectRedis(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	defer func() { _ = redisClient.Close() }()
+
+	store := storage.NewService(db, redisClient, cfg, logger)
+	return fn(ctx, authz.NewService(store, store, cfg, logger), store)
+}
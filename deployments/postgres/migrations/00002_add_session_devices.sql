@@ -0,0 +1,22 @@
+-- +goose Up
+-- Привязка сессий к устройствам: одна активная сессия на пару (пользователь, устройство)
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS device_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS app_type VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS app_version VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Старые сессии не привязаны к устройству и не могут быть обновлены новым кодом
+DELETE FROM user_sessions WHERE device_id = '';
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_device ON user_sessions(user_id, device_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_sessions_user_device;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS app_version;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS app_type;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS device_id;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Семейства ротации refresh токенов: каждый refresh помечает предыдущую сессию ротированной
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS family_id VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP NULL;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS replaced_by VARCHAR(36) NOT NULL DEFAULT '';
+
+-- Существующие сессии становятся началом собственного семейства
+UPDATE user_sessions SET family_id = id WHERE family_id = '';
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_family_id ON user_sessions(family_id);
+
+-- Журнал событий безопасности
+CREATE TABLE IF NOT EXISTS security_events (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS security_events;
+DROP INDEX IF EXISTS idx_user_sessions_family_id;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS replaced_by;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS rotated_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS family_id;
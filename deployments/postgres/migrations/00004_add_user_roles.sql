@@ -0,0 +1,11 @@
+-- +goose Up
+-- Роль пользователя для разграничения доступа (admin, user, guest)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user', 'guest'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- Справочник прав доступа в формате "ресурс:действие"
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Права, выданные ролям
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(20) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role, permission),
+    FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+-- Права, выданные отдельным пользователям сверх прав их роли
+CREATE TABLE IF NOT EXISTS user_permissions (
+    user_id VARCHAR(36) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, permission),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'Просмотр пользователей'),
+    ('users:write', 'Создание, изменение и удаление пользователей'),
+    ('sessions:revoke', 'Завершение сессий других пользователей')
+ON CONFLICT (name) DO NOTHING;
+
+-- Администраторы сохраняют доступ, который раньше давала роль admin
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'sessions:revoke')
+ON CONFLICT (role, permission) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_permissions;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Статус пользователя (active, inactive, banned) вместо флага is_active
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS ban_reason VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_until TIMESTAMP NULL; -- NULL для бессрочной блокировки
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_by VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP NULL;
+
+UPDATE users SET status = CASE WHEN is_active THEN 'active' ELSE 'inactive' END;
+
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'inactive', 'banned'));
+ALTER TABLE users DROP COLUMN IF EXISTS is_active;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+
+-- Право блокировать пользователей
+INSERT INTO permissions (name, description) VALUES
+    ('users:ban', 'Блокировка и разблокировка пользователей')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:ban')
+ON CONFLICT (role, permission) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'users:ban';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT TRUE;
+UPDATE users SET is_active = (status = 'active');
+
+DROP INDEX IF EXISTS idx_users_status;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_by;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_until;
+ALTER TABLE users DROP COLUMN IF EXISTS ban_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
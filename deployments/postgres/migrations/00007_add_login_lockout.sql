@@ -0,0 +1,10 @@
+-- +goose Up
+-- Счетчик неудачных попыток входа и временная блокировка входа
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_failed_login_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL; -- NULL, если вход не заблокирован
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS last_failed_login_at;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_attempts;
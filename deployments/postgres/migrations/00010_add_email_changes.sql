@@ -0,0 +1,19 @@
+-- +goose Up
+-- Смена email: новый адрес подтверждается ссылкой, старый получает ссылку отмены
+CREATE TABLE IF NOT EXISTS email_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA256 токена подтверждения из письма на новый адрес
+    cancel_token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA256 токена отмены из письма на старый адрес
+    expires_at TIMESTAMP NOT NULL,
+    used BOOLEAN DEFAULT FALSE, -- Запрос подтвержден или отменен
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_changes_user_id ON email_changes(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_changes_expires_at ON email_changes(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_changes;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Хеши прежних паролей пользователя для запрета их повторного использования
+CREATE TABLE IF NOT EXISTS password_history (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_created ON password_history(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_history;
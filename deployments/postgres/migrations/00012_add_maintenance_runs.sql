@@ -0,0 +1,9 @@
+-- +goose Up
+-- Время последнего запуска задач обслуживания: реплика пропускает задачу, уже выполненную другой репликой в текущем интервале расписания
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    job VARCHAR(100) PRIMARY KEY,
+    last_run_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS maintenance_runs;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Адрес, на который отправлено письмо подтверждения: после смены email старые токены не подтверждают новый адрес
+ALTER TABLE email_verifications ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+UPDATE email_verifications v SET email = u.email FROM users u WHERE u.id = v.user_id AND v.email IS NULL;
+
+ALTER TABLE email_verifications ALTER COLUMN email SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE email_verifications DROP COLUMN IF EXISTS email;
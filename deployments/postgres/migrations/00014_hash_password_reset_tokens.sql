@@ -0,0 +1,20 @@
+-- +goose Up
+-- Токены сброса пароля хранятся как SHA256, как токены подтверждения и смены email
+ALTER TABLE password_resets ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64);
+
+UPDATE password_resets SET token_hash = encode(sha256(token::bytea), 'hex') WHERE token_hash IS NULL;
+
+ALTER TABLE password_resets ALTER COLUMN token_hash SET NOT NULL;
+ALTER TABLE password_resets ADD CONSTRAINT password_resets_token_hash_key UNIQUE (token_hash);
+
+DROP INDEX IF EXISTS idx_password_resets_token;
+ALTER TABLE password_resets DROP COLUMN IF EXISTS token;
+
+-- +goose Down
+-- Исходные токены по хешу не восстановить, поэтому незавершенные запросы удаляются
+DELETE FROM password_resets;
+
+ALTER TABLE password_resets ADD COLUMN IF NOT EXISTS token VARCHAR(255) UNIQUE NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_password_resets_token ON password_resets(token);
+
+ALTER TABLE password_resets DROP COLUMN IF EXISTS token_hash;
@@ -0,0 +1,94 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Формат kid новых ключей: лексикографический порядок совпадает с хронологическим
+const keyIDLayout = "20060102T150405Z"
+
+// Generate создает закрытый ключ для алгоритма alg (RS256, ES256, EdDSA) и сохраняет его в dir.
+// Возвращает kid нового ключа
+func Generate(dir, alg string, now time.Time) (string, error) {
+	var private crypto.Signer
+	var err error
+
+	switch alg {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, minRSABits)
+	case "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	kid := now.UTC().Format(keyIDLayout)
+	path := filepath.Join(dir, kid+keyFileExt)
+
+	// O_EXCL не дает перезаписать ключ при двух ротациях в одну секунду
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("key %s already exists", kid)
+		}
+		return "", err
+	}
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+
+	return kid, file.Close()
+}
+
+// Prune удаляет самые старые ключи каталога, оставляя keep последних; ключ protect не удаляется никогда
+func Prune(dir string, keep int, protect string) ([]string, error) {
+	keys, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if keep <= 0 || len(keys) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, key := range keys[:len(keys)-keep] {
+		if key.ID == protect {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, key.ID+keyFileExt)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, key.ID)
+	}
+
+	return removed, nil
+}
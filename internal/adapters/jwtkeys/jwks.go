@@ -0,0 +1,72 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+)
+
+// JWKS возвращает открытые ключи проверки; секрет HS256 не публикуется
+func (s *KeySet) JWKS() domain.JSONWebKeySet {
+	set := domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if jwk, ok := toJWK(s.keys[id]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// toJWK преобразует открытую часть ключа в JWK
+func toJWK(key *Key) (domain.JSONWebKey, bool) {
+	jwk := domain.JSONWebKey{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Method.Alg(),
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhKey, err := pub.ECDH()
+		if err != nil {
+			return jwk, false
+		}
+		// Несжатая точка: 0x04 || X || Y
+		point := ecdhKey.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = encode(point[1 : 1+size])
+		jwk.Y = encode(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encode(pub)
+	default:
+		return jwk, false
+	}
+
+	return jwk, true
+}
+
+// encode кодирует байты в base64url без выравнивания
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,110 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publicKeyFromJWK восстанавливает открытый ключ из JWK так, как это делает клиент
+func publicKeyFromJWK(t *testing.T, jwk domain.JSONWebKey) interface{} {
+	t.Helper()
+	decode := func(value string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		require.NoError(t, err)
+		return data
+	}
+
+	switch jwk.KeyType {
+	case "RSA":
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(decode(jwk.N)),
+			E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64()),
+		}
+	case "EC":
+		require.Equal(t, "P-256", jwk.Curve)
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(decode(jwk.X)),
+			Y:     new(big.Int).SetBytes(decode(jwk.Y)),
+		}
+	case "OKP":
+		require.Equal(t, "Ed25519", jwk.Curve)
+		return ed25519.PublicKey(decode(jwk.X))
+	default:
+		t.Fatalf("unexpected key type %q", jwk.KeyType)
+		return nil
+	}
+}
+
+func TestToJWK(t *testing.T) {
+	tests := []struct {
+		name    string
+		alg     string
+		wantKty string
+		wantCrv string
+	}{
+		{name: "RS256", alg: "RS256", wantKty: "RSA"},
+		{name: "ES256", alg: "ES256", wantKty: "EC", wantCrv: "P-256"},
+		{name: "EdDSA", alg: "EdDSA", wantKty: "OKP", wantCrv: "Ed25519"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, kid := generateKeyDir(t, tt.alg)
+			set, err := Load(&config.Config{JWTKeysDir: dir})
+			require.NoError(t, err)
+
+			jwk, ok := toJWK(set.keys[kid])
+			require.True(t, ok)
+			assert.Equal(t, kid, jwk.KeyID)
+			assert.Equal(t, "sig", jwk.Use)
+			assert.Equal(t, tt.alg, jwk.Algorithm)
+			assert.Equal(t, tt.wantKty, jwk.KeyType)
+			assert.Equal(t, tt.wantCrv, jwk.Curve)
+
+			// Токен, подписанный сервером, проверяется ключом, восстановленным из JWKS
+			signed, err := set.Sign(jwt.MapClaims{"sub": "user-1"})
+			require.NoError(t, err)
+			public := publicKeyFromJWK(t, jwk)
+			token, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return public, nil })
+			require.NoError(t, err)
+			assert.True(t, token.Valid)
+		})
+	}
+
+	t.Run("HS256 secret is not exported", func(t *testing.T) {
+		_, ok := toJWK(&Key{Method: jwt.SigningMethodHS256, private: []byte("secret"), public: []byte("secret")})
+		assert.False(t, ok)
+	})
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	var kids []string
+	for i, alg := range []string{"EdDSA", "RS256", "ES256"} {
+		kid, err := Generate(dir, alg, time.Date(2025, 1, 1+i, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		kids = append(kids, kid)
+	}
+
+	set, err := Load(&config.Config{JWTKeysDir: dir})
+	require.NoError(t, err)
+
+	jwks := set.JWKS()
+	require.Len(t, jwks.Keys, 3)
+	for i, jwk := range jwks.Keys {
+		assert.Equal(t, kids[i], jwk.KeyID, "keys are sorted by kid")
+	}
+}
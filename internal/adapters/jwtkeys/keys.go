@@ -0,0 +1,188 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Расширение файлов ключей в JWT_KEYS_DIR; имя файла без расширения - kid
+const keyFileExt = ".pem"
+
+// Минимальный размер RSA ключа
+const minRSABits = 2048
+
+// Key представляет ключ подписи или проверки JWT
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private crypto.PrivateKey // nil для ключей, оставленных только для проверки
+	public  crypto.PublicKey
+}
+
+// CanSign сообщает, есть ли у ключа закрытая часть
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// KeySet представляет ключ подписи и все ключи, которыми проверяются токены
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// Load загружает ключи из JWT_KEYS_DIR или, если каталог не задан, использует HS256 с JWT_SECRET
+func Load(cfg *config.Config) (*KeySet, error) {
+	if cfg.JWTKeysDir == "" {
+		secret := []byte(cfg.JWTSecret)
+		key := &Key{Method: jwt.SigningMethodHS256, private: secret, public: secret}
+		return &KeySet{signing: key, keys: map[string]*Key{"": key}}, nil
+	}
+
+	keys, err := LoadDir(cfg.JWTKeysDir)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		set.keys[key.ID] = key
+		// kid, созданные командой keys rotate, упорядочены по времени - подписываем самым новым
+		if cfg.JWTSigningKeyID == "" && key.CanSign() {
+			set.signing = key
+		}
+	}
+
+	if cfg.JWTSigningKeyID != "" {
+		set.signing = set.keys[cfg.JWTSigningKeyID]
+		if set.signing == nil {
+			return nil, fmt.Errorf("signing key %q not found in %s", cfg.JWTSigningKeyID, cfg.JWTKeysDir)
+		}
+	}
+	if set.signing == nil || !set.signing.CanSign() {
+		return nil, fmt.Errorf("no private signing key in %s", cfg.JWTKeysDir)
+	}
+
+	return set, nil
+}
+
+// LoadDir читает все PEM ключи каталога, отсортированные по kid
+func LoadDir(dir string) ([]*Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+		}
+
+		key, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", path, err)
+		}
+		key.ID = strings.TrimSuffix(filepath.Base(path), keyFileExt)
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Sign подписывает claims текущим ключом и указывает его kid в заголовке
+func (s *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.ID != "" {
+		token.Header["kid"] = s.signing.ID
+	}
+	return token.SignedString(s.signing.private)
+}
+
+// Keyfunc возвращает ключ проверки по kid токена, сверяя алгоритм с типом ключа
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
+
+// SigningKey возвращает текущий ключ подписи
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// parseKey разбирает закрытый (PKCS#8, PKCS#1, SEC 1) или открытый (PKIX) ключ из PEM
+func parseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = signer
+		key.public = signer.Public()
+	} else {
+		key.public = parsed
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 requires a P-256 key")
+		}
+		key.Method = jwt.SigningMethodES256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.public)
+	}
+
+	return key, nil
+}
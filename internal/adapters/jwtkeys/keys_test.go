@@ -0,0 +1,281 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateKeyDir создает каталог с ключом alg и возвращает каталог и kid ключа
+func generateKeyDir(t *testing.T, alg string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	kid, err := Generate(dir, alg, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+	return dir, kid
+}
+
+// encodePEM кодирует DER в PEM блок заданного типа
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func TestParseKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	require.NoError(t, err)
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8 := func(key interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return encodePEM("PRIVATE KEY", der)
+	}
+	pkix := func(key interface{}) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		return encodePEM("PUBLIC KEY", der)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantMethod  jwt.SigningMethod
+		wantCanSign bool
+		wantErr     string
+	}{
+		{name: "RSA PKCS#8", data: pkcs8(rsaKey), wantMethod: jwt.SigningMethodRS256, wantCanSign: true},
+		{name: "RSA PKCS#1", data: encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), wantMethod: jwt.SigningMethodRS256, wantCanSign: true},
+		{name: "RSA public key", data: pkix(&rsaKey.PublicKey), wantMethod: jwt.SigningMethodRS256},
+		{name: "EC PKCS#8", data: pkcs8(ecKey), wantMethod: jwt.SigningMethodES256, wantCanSign: true},
+		{name: "EC SEC 1", data: encodePEM("EC PRIVATE KEY", sec1), wantMethod: jwt.SigningMethodES256, wantCanSign: true},
+		{name: "EC public key", data: pkix(&ecKey.PublicKey), wantMethod: jwt.SigningMethodES256},
+		{name: "Ed25519 PKCS#8", data: pkcs8(edPrivate), wantMethod: jwt.SigningMethodEdDSA, wantCanSign: true},
+		{name: "Ed25519 public key", data: pkix(edPublic), wantMethod: jwt.SigningMethodEdDSA},
+		{name: "RSA key too small", data: pkcs8(smallRSAKey), wantErr: "at least 2048 bits"},
+		{name: "EC key on wrong curve", data: pkcs8(p384Key), wantErr: "P-256"},
+		{name: "not PEM", data: []byte("not a key"), wantErr: "no PEM block"},
+		{name: "unsupported PEM block", data: encodePEM("CERTIFICATE", []byte{1, 2, 3}), wantErr: "unsupported PEM block"},
+		{name: "corrupted DER", data: encodePEM("PRIVATE KEY", []byte{1, 2, 3}), wantErr: "asn1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parseKey(tt.data)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMethod, key.Method)
+			assert.Equal(t, tt.wantCanSign, key.CanSign())
+			assert.NotNil(t, key.public)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("HS256 with JWT_SECRET when no keys dir", func(t *testing.T) {
+		set, err := Load(&config.Config{JWTSecret: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodHS256, set.SigningKey().Method)
+		assert.Empty(t, set.SigningKey().ID)
+		assert.Empty(t, set.JWKS().Keys, "HS256 secret must not be published")
+	})
+
+	t.Run("newest private key signs by default", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := Generate(dir, "ES256", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		newest, err := Generate(dir, "EdDSA", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		set, err := Load(&config.Config{JWTKeysDir: dir})
+		require.NoError(t, err)
+		assert.Equal(t, newest, set.SigningKey().ID)
+		assert.Len(t, set.JWKS().Keys, 2)
+	})
+
+	t.Run("explicit signing key id", func(t *testing.T) {
+		dir := t.TempDir()
+		older, err := Generate(dir, "ES256", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		_, err = Generate(dir, "ES256", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		set, err := Load(&config.Config{JWTKeysDir: dir, JWTSigningKeyID: older})
+		require.NoError(t, err)
+		assert.Equal(t, older, set.SigningKey().ID)
+	})
+
+	t.Run("unknown signing key id", func(t *testing.T) {
+		dir, _ := generateKeyDir(t, "ES256")
+		_, err := Load(&config.Config{JWTKeysDir: dir, JWTSigningKeyID: "missing"})
+		assert.ErrorContains(t, err, `signing key "missing" not found`)
+	})
+
+	t.Run("only public keys", func(t *testing.T) {
+		dir := t.TempDir()
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "old"+keyFileExt), encodePEM("PUBLIC KEY", der), 0o600))
+
+		_, err = Load(&config.Config{JWTKeysDir: dir})
+		assert.ErrorContains(t, err, "no private signing key")
+	})
+
+	t.Run("invalid key file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bad"+keyFileExt), []byte("garbage"), 0o600))
+
+		_, err := Load(&config.Config{JWTKeysDir: dir})
+		assert.ErrorContains(t, err, "invalid key")
+	})
+}
+
+func TestKeySet_Sign(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  string
+	}{
+		{name: "RS256", alg: "RS256"},
+		{name: "ES256", alg: "ES256"},
+		{name: "EdDSA", alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, kid := generateKeyDir(t, tt.alg)
+			set, err := Load(&config.Config{JWTKeysDir: dir})
+			require.NoError(t, err)
+
+			signed, err := set.Sign(jwt.MapClaims{"sub": "user-1"})
+			require.NoError(t, err)
+
+			token, err := jwt.Parse(signed, set.Keyfunc)
+			require.NoError(t, err)
+			assert.True(t, token.Valid)
+			assert.Equal(t, tt.alg, token.Method.Alg())
+			assert.Equal(t, kid, token.Header["kid"])
+
+			subject, err := token.Claims.GetSubject()
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", subject)
+		})
+	}
+
+	t.Run("HS256 round-trip without kid", func(t *testing.T) {
+		set, err := Load(&config.Config{JWTSecret: "secret"})
+		require.NoError(t, err)
+
+		signed, err := set.Sign(jwt.MapClaims{"sub": "user-1"})
+		require.NoError(t, err)
+
+		token, err := jwt.Parse(signed, set.Keyfunc)
+		require.NoError(t, err)
+		assert.NotContains(t, token.Header, "kid")
+	})
+}
+
+func TestKeySet_Keyfunc(t *testing.T) {
+	dir, kid := generateKeyDir(t, "RS256")
+	set, err := Load(&config.Config{JWTKeysDir: dir})
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(set.keys[kid].public)
+	require.NoError(t, err)
+	publicPEM := encodePEM("PUBLIC KEY", publicDER)
+
+	// Ключ с тем же kid из другого каталога: подпись не должна приниматься
+	otherDir, otherKid := generateKeyDir(t, "RS256")
+	require.Equal(t, kid, otherKid)
+	otherSet, err := Load(&config.Config{JWTKeysDir: otherDir})
+	require.NoError(t, err)
+
+	sign := func(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "attacker"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name:  "valid token",
+			token: func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, kid, set.signing.private) },
+		},
+		{
+			// Классическая подмена алгоритма: HMAC с открытым ключом RSA в качестве секрета
+			name:    "HS256 with kid of asymmetric key",
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, kid, publicPEM) },
+			wantErr: "unexpected signing method",
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodNone, kid, jwt.UnsafeAllowNoneSignatureType)
+			},
+			wantErr: "unexpected signing method",
+		},
+		{
+			name:    "unknown kid",
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, "unknown", set.signing.private) },
+			wantErr: `unknown signing key "unknown"`,
+		},
+		{
+			name:    "missing kid",
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, "", set.signing.private) },
+			wantErr: `unknown signing key ""`,
+		},
+		{
+			name:    "signed by another key with the same kid",
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, kid, otherSet.signing.private) },
+			wantErr: "verification error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.Parse(tt.token(t), set.Keyfunc)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, token.Valid)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// fileSender складывает письма в локальный каталог в формате maildir (для разработки и тестов)
+type fileSender struct {
+	dir string
+}
+
+// newFileSender создает файловый драйвер и подкаталоги maildir
+func newFileSender(dir string) (*fileSender, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, err
+		}
+	}
+	return &fileSender{dir: dir}, nil
+}
+
+// send записывает письмо в tmp и атомарно переносит в new
+func (d *fileSender) send(_ context.Context, msg *message) error {
+	name := msg.id + ".eml"
+	tmpPath := filepath.Join(d.dir, "tmp", name)
+
+	if err := os.WriteFile(tmpPath, msg.raw, 0o640); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(d.dir, "new", name))
+}
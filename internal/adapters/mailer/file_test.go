@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+
+	_, err := newFileSender(dir)
+	require.NoError(t, err)
+
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		require.NoError(t, err, sub)
+		assert.True(t, info.IsDir(), sub)
+	}
+
+	// Повторная инициализация существующего каталога не ошибка
+	_, err = newFileSender(dir)
+	assert.NoError(t, err)
+}
+
+func TestFileSender_send(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, dir string)
+		wantErr bool
+	}{
+		{name: "message moved to new"},
+		{
+			name: "tmp directory removed",
+			prepare: func(t *testing.T, dir string) {
+				require.NoError(t, os.RemoveAll(filepath.Join(dir, "tmp")))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			snd, err := newFileSender(dir)
+			require.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare(t, dir)
+			}
+
+			msg, err := buildMessage("Bukhindor <no-reply@bukhindor.com>", "user@example.com", "User", "Тема", "Текст письма", "<p>HTML</p>")
+			require.NoError(t, err)
+
+			err = snd.send(context.Background(), msg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			raw, err := os.ReadFile(filepath.Join(dir, "new", msg.id+".eml"))
+			require.NoError(t, err)
+			assert.Equal(t, msg.raw, raw)
+			assert.True(t, strings.Contains(string(raw), "To: \"User\" <user@example.com>"))
+
+			// Во время записи письмо лежит в tmp и переносится в new только целиком
+			pending, err := os.ReadDir(filepath.Join(dir, "tmp"))
+			require.NoError(t, err)
+			assert.Empty(t, pending)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+)
+
+// message представляет готовое к доставке письмо
+type message struct {
+	id       string
+	from     string // Адрес отправителя для SMTP конверта
+	to       string // Адрес получателя для SMTP конверта
+	template string
+	raw      []byte // Письмо в формате RFC 5322
+	attempt  int
+}
+
+// buildMessage собирает multipart/alternative письмо с текстовой и HTML версиями
+func buildMessage(from, to, toName, subject, text, html string) (*message, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	toAddr, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+	toAddr.Name = toName
+
+	id := app.GenerateUUID()
+	domainPart := fromAddr.Address[strings.LastIndex(fromAddr.Address, "@")+1:]
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", fromAddr.String())
+	header("To", toAddr.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+id+"@"+domainPart+">")
+	header("MIME-Version", "1.0")
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+
+	if err := writePart(mw, "text/plain", text); err != nil {
+		return nil, err
+	}
+	if html != "" {
+		if err := writePart(mw, "text/html", html); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &message{
+		id:   id,
+		from: fromAddr.Address,
+		to:   toAddr.Address,
+		raw:  buf.Bytes(),
+	}, nil
+}
+
+// writePart добавляет часть письма в кодировке quoted-printable
+func writePart(mw *multipart.Writer, contentType, body string) error {
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
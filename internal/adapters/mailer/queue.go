@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Параметры повторных попыток доставки
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+)
+
+// Ошибки постановки письма в очередь
+var (
+	ErrQueueFull = errors.New("mail queue is full")
+	ErrClosed    = errors.New("mailer is closed")
+)
+
+// Send формирует письмо по шаблону и ставит его в очередь на асинхронную отправку
+func (s *Service) Send(_ context.Context, email domain.Email) error {
+	subject, text, html, err := s.render(email)
+	if err != nil {
+		s.logger.Error("Failed to render email", zap.Error(err), zap.String("template", string(email.Template)))
+		return err
+	}
+
+	msg, err := buildMessage(s.config.MailFrom, email.To, email.ToName, subject, text, html)
+	if err != nil {
+		s.logger.Error("Failed to build email", zap.Error(err), zap.String("template", string(email.Template)))
+		return err
+	}
+	msg.template = string(email.Template)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	select {
+	case s.queue <- msg:
+		s.logger.Debug("Email queued", zap.String("message_id", msg.id), zap.String("template", msg.template))
+		return nil
+	default:
+		s.logger.Error("Mail queue is full, email dropped", zap.String("template", msg.template))
+		return ErrQueueFull
+	}
+}
+
+// Close прекращает прием писем и дожидается отправки очереди или истечения ctx
+func (s *Service) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		// Прерываем ожидание повторных попыток и текущие отправки
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// start запускает воркеры очереди
+func (s *Service) start() {
+	for i := 0; i < workerCount; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for msg := range s.queue {
+				s.deliver(msg)
+			}
+		}()
+	}
+}
+
+// deliver отправляет письмо с повторными попытками и экспоненциальной задержкой
+func (s *Service) deliver(msg *message) {
+	delay := s.retryDelay
+	for {
+		msg.attempt++
+		err := s.sender.send(s.ctx, msg)
+		if err == nil {
+			s.logger.Info("Email sent",
+				zap.String("message_id", msg.id),
+				zap.String("template", msg.template),
+				zap.Int("attempt", msg.attempt),
+			)
+			return
+		}
+
+		if msg.attempt > s.config.MailMaxRetries {
+			s.logger.Error("Email delivery failed, giving up",
+				zap.Error(err),
+				zap.String("message_id", msg.id),
+				zap.String("template", msg.template),
+				zap.Int("attempts", msg.attempt),
+			)
+			return
+		}
+
+		s.logger.Warn("Email delivery failed, will retry",
+			zap.Error(err),
+			zap.String("message_id", msg.id),
+			zap.Int("attempt", msg.attempt),
+			zap.Duration("delay", delay),
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+			s.logger.Error("Email delivery aborted on shutdown", zap.String("message_id", msg.id))
+			return
+		}
+
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSender запоминает попытки доставки и отклоняет первые failures из них
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	attempts []int
+	sent     []*message
+	block    chan struct{} // Если задан, send ждет его закрытия
+}
+
+func (f *fakeSender) send(ctx context.Context, msg *message) error {
+	if f.block != nil {
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts = append(f.attempts, msg.attempt)
+	if len(f.attempts) <= f.failures {
+		return errors.New("smtp unavailable")
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func (f *fakeSender) result() ([]int, []*message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.attempts...), append([]*message(nil), f.sent...)
+}
+
+// newTestService создает сервис с фейковым драйвером и мгновенными повторными попытками
+func newTestService(t *testing.T, snd sender, queueSize, maxRetries int) *Service {
+	t.Helper()
+	cfg := &config.Config{
+		MailFrom:          "Bukhindor <no-reply@bukhindor.com>",
+		MailDefaultLocale: "ru",
+		MailQueueSize:     queueSize,
+		MailMaxRetries:    maxRetries,
+	}
+	s, err := newService(snd, cfg, zap.NewNop())
+	require.NoError(t, err)
+	s.retryDelay = time.Millisecond
+	return s
+}
+
+// resetEmail возвращает письмо сброса пароля для получателя to
+func resetEmail(to string) domain.Email {
+	return domain.Email{
+		To:       to,
+		ToName:   "User",
+		Template: domain.EmailTemplatePasswordReset,
+		Locale:   "en",
+		Data:     map[string]any{"ResetURL": "https://example.com/reset", "ExpiresHours": 1},
+	}
+}
+
+func TestService_Send(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     domain.Email
+		queueSize int
+		prefill   int // Писем в очереди до отправки
+		closed    bool
+		wantErr   error
+		wantAny   bool // Ожидается ошибка без конкретного значения
+		wantQueue int
+	}{
+		{name: "queued", email: resetEmail("user@example.com"), queueSize: 1, wantQueue: 1},
+		{name: "queue full", email: resetEmail("user@example.com"), queueSize: 1, prefill: 1, wantErr: ErrQueueFull, wantQueue: 1},
+		{name: "closed", email: resetEmail("user@example.com"), queueSize: 1, closed: true, wantErr: ErrClosed},
+		{name: "unknown template", email: domain.Email{To: "user@example.com", Template: "missing"}, queueSize: 1, wantAny: true},
+		{name: "missing template data", email: domain.Email{To: "user@example.com", Template: domain.EmailTemplatePasswordReset}, queueSize: 1, wantAny: true},
+		{name: "invalid recipient", email: resetEmail("not an address"), queueSize: 1, wantAny: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Воркеры не запускаются, поэтому письма остаются в очереди
+			s := newTestService(t, &fakeSender{}, tt.queueSize, 0)
+			for i := 0; i < tt.prefill; i++ {
+				require.NoError(t, s.Send(context.Background(), resetEmail("other@example.com")))
+			}
+			if tt.closed {
+				require.NoError(t, s.Close(context.Background()))
+			}
+
+			err := s.Send(context.Background(), tt.email)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantAny:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+			if !tt.closed {
+				assert.Len(t, s.queue, tt.wantQueue)
+			}
+		})
+	}
+}
+
+func TestService_deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		maxRetries   int
+		wantAttempts []int
+		wantSent     bool
+	}{
+		{name: "sent on first attempt", failures: 0, maxRetries: 3, wantAttempts: []int{1}, wantSent: true},
+		{name: "sent after retries", failures: 2, maxRetries: 3, wantAttempts: []int{1, 2, 3}, wantSent: true},
+		{name: "last allowed retry succeeds", failures: 3, maxRetries: 3, wantAttempts: []int{1, 2, 3, 4}, wantSent: true},
+		{name: "gives up after max retries", failures: 10, maxRetries: 2, wantAttempts: []int{1, 2, 3}},
+		{name: "no retries", failures: 1, maxRetries: 0, wantAttempts: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snd := &fakeSender{failures: tt.failures}
+			s := newTestService(t, snd, 1, tt.maxRetries)
+			s.start()
+
+			require.NoError(t, s.Send(context.Background(), resetEmail("user@example.com")))
+			require.NoError(t, s.Close(context.Background()))
+
+			attempts, sent := snd.result()
+			assert.Equal(t, tt.wantAttempts, attempts)
+			if tt.wantSent {
+				require.Len(t, sent, 1)
+				assert.Equal(t, "user@example.com", sent[0].to)
+				assert.Equal(t, "no-reply@bukhindor.com", sent[0].from)
+				assert.Equal(t, string(domain.EmailTemplatePasswordReset), sent[0].template)
+			} else {
+				assert.Empty(t, sent)
+			}
+		})
+	}
+}
+
+func TestService_Close(t *testing.T) {
+	t.Run("drains queue", func(t *testing.T) {
+		snd := &fakeSender{}
+		s := newTestService(t, snd, 10, 0)
+		s.start()
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, s.Send(context.Background(), resetEmail("user@example.com")))
+		}
+		require.NoError(t, s.Close(context.Background()))
+
+		_, sent := snd.result()
+		assert.Len(t, sent, 5)
+		assert.ErrorIs(t, s.Send(context.Background(), resetEmail("user@example.com")), ErrClosed)
+	})
+
+	t.Run("deadline aborts pending delivery", func(t *testing.T) {
+		snd := &fakeSender{block: make(chan struct{})}
+		s := newTestService(t, snd, 1, 5)
+		s.start()
+
+		require.NoError(t, s.Send(context.Background(), resetEmail("user@example.com")))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.Close(ctx), context.DeadlineExceeded)
+
+		_, sent := snd.result()
+		assert.Empty(t, sent)
+	})
+
+	t.Run("repeated close", func(t *testing.T) {
+		s := newTestService(t, &fakeSender{}, 1, 0)
+		s.start()
+
+		require.NoError(t, s.Close(context.Background()))
+		assert.NoError(t, s.Close(context.Background()))
+	})
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	htmltemplate "html/template"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// Количество воркеров, отправляющих письма из очереди
+const workerCount = 2
+
+// sender представляет драйвер доставки готового письма
+type sender interface {
+	send(ctx context.Context, msg *message) error
+}
+
+// Service представляет сервис отправки писем
+type Service struct {
+	sender     sender
+	queue      chan *message
+	retryDelay time.Duration // Задержка перед первой повторной попыткой, далее удваивается
+	textTpls   map[string]*template.Template
+	htmlTpls   map[string]*htmltemplate.Template
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	mu         sync.RWMutex // Защищает закрытие очереди от конкурентных Send
+	closed     bool
+	config     *config.Config
+	logger     *zap.Logger
+}
+
+// NewService создает сервис отправки писем с драйвером из конфигурации и запускает воркеры очереди
+func NewService(cfg *config.Config, logger *zap.Logger) (*Service, error) {
+	var snd sender
+	switch cfg.MailDriver {
+	case "smtp":
+		snd = newSMTPSender(cfg)
+	case "file":
+		fileSnd, err := newFileSender(cfg.MailSpoolDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init mail spool: %w", err)
+		}
+		snd = fileSnd
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.MailDriver)
+	}
+
+	s, err := newService(snd, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	s.start()
+
+	return s, nil
+}
+
+// newService создает сервис с заданным драйвером доставки, не запуская воркеры очереди
+func newService(snd sender, cfg *config.Config, logger *zap.Logger) (*Service, error) {
+	textTpls, htmlTpls, err := parseTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mail templates: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		sender:     snd,
+		queue:      make(chan *message, cfg.MailQueueSize),
+		retryDelay: retryBaseDelay,
+		textTpls:   textTpls,
+		htmlTpls:   htmlTpls,
+		ctx:        ctx,
+		cancel:     cancel,
+		config:     cfg,
+		logger:     logger,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+)
+
+// smtpSender доставляет письма через SMTP сервер
+type smtpSender struct {
+	host        string
+	port        string
+	username    string
+	password    string
+	timeout     time.Duration
+	implicitTLS bool
+}
+
+// newSMTPSender создает SMTP драйвер
+func newSMTPSender(cfg *config.Config) *smtpSender {
+	return &smtpSender{
+		host:        cfg.SMTPHost,
+		port:        cfg.SMTPPort,
+		username:    cfg.SMTPUsername,
+		password:    cfg.SMTPPassword,
+		timeout:     cfg.SMTPTimeout,
+		implicitTLS: cfg.SMTPPort == "465",
+	}
+}
+
+// send отправляет письмо, ограничивая весь SMTP диалог таймаутом
+func (d *smtpSender) send(ctx context.Context, msg *message) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(d.host, d.port)
+	tlsConfig := &tls.Config{ServerName: d.host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if d.implicitTLS {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, d.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if !d.implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", d.username, d.password, d.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(msg.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,117 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	htmltemplate "html/template"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+)
+
+// Название приложения, подставляемое в шаблоны
+const appName = "Bukhindor"
+
+// Шаблоны писем: templates/<locale>/<name>.txt содержит блоки "subject" и "text",
+// templates/<locale>/<name>.html - HTML версию письма
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// parseTemplates загружает встроенные шаблоны писем, ключ карты - "<locale>/<name>"
+func parseTemplates() (map[string]*template.Template, map[string]*htmltemplate.Template, error) {
+	textTpls := make(map[string]*template.Template)
+	htmlTpls := make(map[string]*htmltemplate.Template)
+
+	err := fs.WalkDir(templatesFS, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		locale := path.Base(path.Dir(p))
+		ext := path.Ext(p)
+		key := locale + "/" + strings.TrimSuffix(path.Base(p), ext)
+
+		content, err := templatesFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		switch ext {
+		case ".txt":
+			tpl, err := template.New(key).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			textTpls[key] = tpl
+		case ".html":
+			tpl, err := htmltemplate.New(key).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			htmlTpls[key] = tpl
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textTpls, htmlTpls, nil
+}
+
+// render формирует тему, текстовую и HTML версии письма
+func (s *Service) render(email domain.Email) (subject, text, html string, err error) {
+	key := s.templateKey(email)
+	textTpl, ok := s.textTpls[key]
+	if !ok {
+		return "", "", "", fmt.Errorf("mail template not found: %s", key)
+	}
+
+	data := map[string]any{
+		"AppName": appName,
+		"Name":    email.ToName,
+	}
+	for k, v := range email.Data {
+		data[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := textTpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("render subject %s: %w", key, err)
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := textTpl.ExecuteTemplate(&buf, "text", data); err != nil {
+		return "", "", "", fmt.Errorf("render text %s: %w", key, err)
+	}
+	text = strings.TrimSpace(buf.String())
+
+	// HTML версия необязательна
+	if htmlTpl, ok := s.htmlTpls[key]; ok {
+		buf.Reset()
+		if err := htmlTpl.Execute(&buf, data); err != nil {
+			return "", "", "", fmt.Errorf("render html %s: %w", key, err)
+		}
+		html = buf.String()
+	}
+
+	return subject, text, html, nil
+}
+
+// templateKey выбирает шаблон для локали письма с откатом на локаль по умолчанию
+func (s *Service) templateKey(email domain.Email) string {
+	for _, locale := range []string{email.Locale, s.config.MailDefaultLocale, "ru"} {
+		key := strings.ToLower(locale) + "/" + string(email.Template)
+		if _, ok := s.textTpls[key]; ok {
+			return key
+		}
+	}
+	return "ru/" + string(email.Template)
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_render(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultLocale string
+		email         domain.Email
+		wantSubject   string
+		wantText      string
+		wantErr       bool
+	}{
+		{
+			name:          "requested locale",
+			defaultLocale: "ru",
+			email:         domain.Email{ToName: "Ann", Template: domain.EmailTemplateWelcome, Locale: "en"},
+			wantSubject:   "Welcome to Bukhindor!",
+			wantText:      "Hello, Ann!",
+		},
+		{
+			name:          "locale is case-insensitive",
+			defaultLocale: "ru",
+			email:         domain.Email{ToName: "Ann", Template: domain.EmailTemplateWelcome, Locale: "EN"},
+			wantSubject:   "Welcome to Bukhindor!",
+		},
+		{
+			name:          "unknown locale falls back to default",
+			defaultLocale: "en",
+			email:         domain.Email{ToName: "Ann", Template: domain.EmailTemplateWelcome, Locale: "de"},
+			wantSubject:   "Welcome to Bukhindor!",
+		},
+		{
+			name:          "unknown default locale falls back to ru",
+			defaultLocale: "de",
+			email:         domain.Email{ToName: "Ann", Template: domain.EmailTemplateWelcome},
+			wantText:      "Здравствуйте, Ann!",
+		},
+		{
+			name:          "template data is substituted",
+			defaultLocale: "en",
+			email: domain.Email{
+				Template: domain.EmailTemplatePasswordReset,
+				Data:     map[string]any{"ResetURL": "https://example.com/reset?token=abc", "ExpiresHours": 2},
+			},
+			wantText: "https://example.com/reset?token=abc",
+		},
+		{
+			name:          "unknown template",
+			defaultLocale: "ru",
+			email:         domain.Email{Template: "missing"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, &fakeSender{}, 1, 0)
+			s.config.MailDefaultLocale = tt.defaultLocale
+
+			subject, text, html, err := s.render(tt.email)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantSubject != "" {
+				assert.Equal(t, tt.wantSubject, subject)
+			}
+			if tt.wantText != "" {
+				assert.Contains(t, text, tt.wantText)
+			}
+			assert.NotEmpty(t, html)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// ConnectPostgres подключается к базе данных PostgreSQL через pgxpool
+func ConnectPostgres(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.New(ctx, cfg.GetPostgresDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// ConnectRedis подключается к Redis
+func ConnectRedis(ctx context.Context, cfg *config.Config) (*redis.Client, error) {
+	// Парсим Redis URL
+	opt, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Устанавливаем пароль если есть
+	if cfg.RedisPassword != "" {
+		opt.Password = cfg.RedisPassword
+	}
+
+	// Устанавливаем номер БД
+	opt.DB = cfg.RedisDB
+
+	client := redis.NewClient(opt)
+
+	// Проверяем соединение
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// emailChangeColumns колонки таблицы email_changes в порядке сканирования
+var emailChangeColumns = []string{
+	"id", "user_id", "new_email", "token_hash", "cancel_token_hash", "expires_at", "used", "created_at",
+}
+
+// CreateEmailChange создает новый запрос на смену email
+func (s *Service) CreateEmailChange(ctx context.Context, change *domain.EmailChange) error {
+	query, args, err := squirrel.Insert("email_changes").
+		Columns(emailChangeColumns...).
+		Values(change.ID, change.UserID, change.NewEmail, change.TokenHash, change.CancelTokenHash, change.ExpiresAt.Format("2006-01-02 15:04:05"), change.Used, change.CreatedAt.Format("2006-01-02 15:04:05")).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build create email change query", zap.Error(err))
+		return err
+	}
+
+	_, err = s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to create email change", zap.Error(err), zap.String("user_id", change.UserID))
+		return err
+	}
+
+	s.logger.Info("Email change created", zap.String("change_id", change.ID), zap.String("user_id", change.UserID))
+	return nil
+}
+
+// GetEmailChangeByTokenHash получает запрос на смену email по хешу токена подтверждения
+func (s *Service) GetEmailChangeByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailChange, error) {
+	return s.getEmailChange(ctx, squirrel.Eq{"token_hash": tokenHash})
+}
+
+// GetEmailChangeByCancelTokenHash получает запрос на смену email по хешу токена отмены
+func (s *Service) GetEmailChangeByCancelTokenHash(ctx context.Context, cancelTokenHash string) (*domain.EmailChange, error) {
+	return s.getEmailChange(ctx, squirrel.Eq{"cancel_token_hash": cancelTokenHash})
+}
+
+// getEmailChange получает запрос на смену email по условию
+func (s *Service) getEmailChange(ctx context.Context, where squirrel.Eq) (*domain.EmailChange, error) {
+	query, args, err := squirrel.Select(emailChangeColumns...).
+		From("email_changes").
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build get email change query", zap.Error(err))
+		return nil, err
+	}
+
+	var change domain.EmailChange
+	err = s.conn(ctx).QueryRow(ctx, query, args...).Scan(
+		&change.ID,
+		&change.UserID,
+		&change.NewEmail,
+		&change.TokenHash,
+		&change.CancelTokenHash,
+		&change.ExpiresAt,
+		&change.Used,
+		&change.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logger.Debug("Email change not found")
+			return nil, domain.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get email change", zap.Error(err))
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// MarkEmailChangeAsUsed помечает запрос на смену email подтвержденным или отмененным.
+// Условие used = false гарантирует однократное использование при конкурентных запросах
+func (s *Service) MarkEmailChangeAsUsed(ctx context.Context, id string) error {
+	query, args, err := squirrel.Update("email_changes").
+		Set("used", true).
+		Where(squirrel.Eq{"id": id, "used": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build mark email change as used query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to mark email change as used", zap.Error(err), zap.String("change_id", id))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("Email change not found or already used", zap.String("change_id", id))
+		return domain.ErrEmailChangeUsed
+	}
+
+	return nil
+}
+
+// CancelEmailChanges отменяет все незавершенные запросы пользователя на смену email
+func (s *Service) CancelEmailChanges(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Update("email_changes").
+		Set("used", true).
+		Where(squirrel.Eq{"user_id": userID, "used": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build cancel email changes query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to cancel email changes", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredEmailChanges удаляет истекшие запросы на смену email
+func (s *Service) DeleteExpiredEmailChanges(ctx context.Context) error {
+	query, args, err := squirrel.Delete("email_changes").
+		Where(squirrel.Lt{"expires_at": time.Now().Format("2006-01-02 15:04:05")}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete expired email changes query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to delete expired email changes", zap.Error(err))
+		return err
+	}
+
+	if rowsAffected := tag.RowsAffected(); rowsAffected > 0 {
+		s.logger.Info("Expired email changes deleted", zap.Int64("count", rowsAffected))
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// CreateEmailVerification создает новый запрос на подтверждение email
+func (s *Service) CreateEmailVerification(ctx context.Context, verification *domain.EmailVerification) error {
+	query, args, err := squirrel.Insert("email_verifications").
+		Columns("id", "user_id", "email", "token_hash", "expires_at", "used", "created_at").
+		Values(verification.ID, verification.UserID, verification.Email, verification.TokenHash, verification.ExpiresAt.Format("2006-01-02 15:04:05"), verification.Used, verification.CreatedAt.Format("2006-01-02 15:04:05")).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build create email verification query", zap.Error(err))
+		return err
+	}
+
+	_, err = s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to create email verification", zap.Error(err), zap.String("user_id", verification.UserID))
+		return err
+	}
+
+	s.logger.Info("Email verification created", zap.String("verification_id", verification.ID), zap.String("user_id", verification.UserID))
+	return nil
+}
+
+// GetEmailVerificationByTokenHash получает запрос на подтверждение email по хешу токена
+func (s *Service) GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	query, args, err := squirrel.Select("id", "user_id", "email", "token_hash", "expires_at", "used", "created_at").
+		From("email_verifications").
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build get email verification query", zap.Error(err))
+		return nil, err
+	}
+
+	var verification domain.EmailVerification
+	err = s.conn(ctx).QueryRow(ctx, query, args...).Scan(
+		&verification.ID,
+		&verification.UserID,
+		&verification.Email,
+		&verification.TokenHash,
+		&verification.ExpiresAt,
+		&verification.Used,
+		&verification.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logger.Debug("Email verification not found")
+			return nil, domain.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get email verification", zap.Error(err))
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// MarkEmailVerificationAsUsed помечает запрос на подтверждение email использованным.
+// Условие used = false гарантирует однократное использование при конкурентных запросах
+func (s *Service) MarkEmailVerificationAsUsed(ctx context.Context, id string) error {
+	query, args, err := squirrel.Update("email_verifications").
+		Set("used", true).
+		Where(squirrel.Eq{"id": id, "used": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build mark email verification as used query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to mark email verification as used", zap.Error(err), zap.String("verification_id", id))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("Email verification not found or already used", zap.String("verification_id", id))
+		return domain.ErrEmailVerificationUsed
+	}
+
+	return nil
+}
+
+// DeleteEmailVerifications удаляет все неиспользованные запросы пользователя на подтверждение email
+func (s *Service) DeleteEmailVerifications(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Delete("email_verifications").
+		Where(squirrel.Eq{"user_id": userID, "used": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete email verifications query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to delete email verifications", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredEmailVerifications удаляет истекшие запросы на подтверждение email
+func (s *Service) DeleteExpiredEmailVerifications(ctx context.Context) error {
+	query, args, err := squirrel.Delete("email_verifications").
+		Where(squirrel.Lt{"expires_at": time.Now().Format("2006-01-02 15:04:05")}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete expired email verifications query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to delete expired email verifications", zap.Error(err))
+		return err
+	}
+
+	if rowsAffected := tag.RowsAffected(); rowsAffected > 0 {
+		s.logger.Info("Expired email verifications deleted", zap.Int64("count", rowsAffected))
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateEmailVerification(t *testing.T) {
+	const query = `INSERT INTO email_verifications \(id,user_id,email,token_hash,expires_at,used,created_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\)`
+	errDB := errors.New("db unavailable")
+	verification := &domain.EmailVerification{
+		ID:        "v1",
+		UserID:    "u1",
+		Email:     "anna@example.com",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "created with recipient email",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("v1", "u1", "anna@example.com", "hash", pgxmock.AnyArg(), false, pgxmock.AnyArg()).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("v1", "u1", "anna@example.com", "hash", pgxmock.AnyArg(), false, pgxmock.AnyArg()).
+					WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.CreateEmailVerification(context.Background(), verification)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_GetEmailVerificationByTokenHash(t *testing.T) {
+	const query = `SELECT id, user_id, email, token_hash, expires_at, used, created_at FROM email_verifications WHERE token_hash = \$1`
+	columns := []string{"id", "user_id", "email", "token_hash", "expires_at", "used", "created_at"}
+	expiresAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		want    *domain.EmailVerification
+		wantErr error
+	}{
+		{
+			name: "found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").
+					WillReturnRows(pgxmock.NewRows(columns).AddRow("v1", "u1", "anna@example.com", "hash", expiresAt, false, createdAt))
+			},
+			want: &domain.EmailVerification{
+				ID:        "v1",
+				UserID:    "u1",
+				Email:     "anna@example.com",
+				TokenHash: "hash",
+				ExpiresAt: expiresAt,
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "not found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").WillReturnError(pgx.ErrNoRows)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			got, err := s.GetEmailVerificationByTokenHash(context.Background(), "hash")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_DeleteEmailVerifications(t *testing.T) {
+	const query = `DELETE FROM email_verifications WHERE used = \$1 AND user_id = \$2`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "pending verifications deleted",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(false, "u1").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(false, "u1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.DeleteEmailVerifications(context.Background(), "u1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -12,9 +12,21 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
-	UpdateUser(ctx context.Context, user *domain.User) error
+	ListUsers(ctx context.Context, filter domain.UserListFilter) ([]*domain.User, int, error)
+	UpdateUser(ctx context.Context, userID string, update domain.UserUpdate) error
+	UpdateUserStatus(ctx context.Context, user *domain.User) error
+	UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error
 	DeleteUser(ctx context.Context, id string) error
 	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+	RecordFailedLogin(ctx context.Context, userID string, resetAfter time.Duration) (int, error)
+	LockUser(ctx context.Context, userID string, until time.Time) error
+	ResetFailedLogins(ctx context.Context, userID string) error
+	SetTOTPSecret(ctx context.Context, userID, secret string) error
+	EnableTOTP(ctx context.Context, userID string, step int64) error
+	DisableTOTP(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error
+	UpdateUserEmail(ctx context.Context, userID, email string) error
 }
 
 // SessionRepository определяет интерфейс для работы с сессиями
@@ -23,21 +35,86 @@ type SessionRepository interface {
 	GetSessionByID(ctx context.Context, id string) (*domain.UserSession, error)
 	GetSessionsByUserID(ctx context.Context, userID string) ([]*domain.UserSession, error)
 	DeleteSession(ctx context.Context, id string) error
+	DeleteSessionsByUserID(ctx context.Context, userID string) error
+	DeleteSessionsByDevice(ctx context.Context, userID, deviceID string) error
+	DeleteSessionsByFamily(ctx context.Context, familyID string) error
+	MarkSessionRotated(ctx context.Context, id, replacedBy string) error
 	DeleteExpiredSessions(ctx context.Context) error
+	CountActiveSessions(ctx context.Context) (int, error)
 }
 
 // PasswordResetRepository определяет интерфейс для работы со сбросом паролей
 type PasswordResetRepository interface {
 	CreatePasswordReset(ctx context.Context, reset *domain.PasswordReset) error
-	GetPasswordResetByToken(ctx context.Context, token string) (*domain.PasswordReset, error)
+	GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordReset, error)
 	MarkPasswordResetAsUsed(ctx context.Context, id string) error
+	CancelPasswordResets(ctx context.Context, userID string) error
 	DeleteExpiredPasswordResets(ctx context.Context) error
 }
 
+// PasswordHistoryRepository определяет интерфейс для истории паролей
+type PasswordHistoryRepository interface {
+	AddPasswordHistory(ctx context.Context, userID, passwordHash string, keep int) error
+	GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+// EmailVerificationRepository определяет интерфейс для работы с подтверждением email
+type EmailVerificationRepository interface {
+	CreateEmailVerification(ctx context.Context, verification *domain.EmailVerification) error
+	GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error)
+	MarkEmailVerificationAsUsed(ctx context.Context, id string) error
+	DeleteEmailVerifications(ctx context.Context, userID string) error
+	DeleteExpiredEmailVerifications(ctx context.Context) error
+}
+
+// EmailChangeRepository определяет интерфейс для работы со сменой email
+type EmailChangeRepository interface {
+	CreateEmailChange(ctx context.Context, change *domain.EmailChange) error
+	GetEmailChangeByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailChange, error)
+	GetEmailChangeByCancelTokenHash(ctx context.Context, cancelTokenHash string) (*domain.EmailChange, error)
+	MarkEmailChangeAsUsed(ctx context.Context, id string) error
+	CancelEmailChanges(ctx context.Context, userID string) error
+	DeleteExpiredEmailChanges(ctx context.Context) error
+}
+
+// RecoveryCodeRepository определяет интерфейс для кодов восстановления двухфакторной аутентификации
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userID string) error
+}
+
+// SecurityEventRepository определяет интерфейс для журнала событий безопасности
+type SecurityEventRepository interface {
+	CreateSecurityEvent(ctx context.Context, event *domain.SecurityEvent) error
+}
+
+// PermissionRepository определяет интерфейс для хранения прав доступа
+type PermissionRepository interface {
+	ListPermissions(ctx context.Context) ([]domain.Permission, error)
+	GetRolePermissions(ctx context.Context, role domain.UserRole) ([]domain.Permission, error)
+	GetUserPermissions(ctx context.Context, userID string) ([]domain.Permission, error)
+	GrantRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error
+	RevokeRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error
+	GrantUserPermission(ctx context.Context, userID string, permission domain.Permission) error
+	RevokeUserPermission(ctx context.Context, userID string, permission domain.Permission) error
+}
+
 // RedisRepository определяет интерфейс для работы с Redis
 type RedisRepository interface {
-	SetRefreshToken(ctx context.Context, userID, refreshToken string, expiration time.Duration) error
-	GetRefreshToken(ctx context.Context, userID string) (string, error)
-	DeleteRefreshToken(ctx context.Context, userID string) error
+	SetRefreshToken(ctx context.Context, userID, deviceID, refreshToken string, expiration time.Duration) error
+	GetRefreshToken(ctx context.Context, userID, deviceID string) (string, error)
+	DeleteRefreshToken(ctx context.Context, userID, deviceID string) error
 	DeleteAllUserRefreshTokens(ctx context.Context, userID string) error
+	RevokeAccessToken(ctx context.Context, tokenID string, ttl time.Duration) error
+	RevokeSessionTokens(ctx context.Context, sessionIDs []string, ttl time.Duration) error
+	SetTokensValidAfter(ctx context.Context, userID string, validAfter time.Time, ttl time.Duration) error
+	GetAccessTokenStatus(ctx context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error)
+	GetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) ([]domain.Permission, bool, error)
+	SetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string, permissions []domain.Permission, ttl time.Duration) error
+	DeleteCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) error
+	SetUserBan(ctx context.Context, userID string, ttl time.Duration) error
+	DeleteUserBan(ctx context.Context, userID string) error
+	AllowRequest(ctx context.Context, key string, limit int, window time.Duration) (*domain.RateLimitResult, error)
 }
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// GetMaintenanceRun возвращает время последнего запуска задачи обслуживания; нулевое время - задача еще не запускалась
+func (s *Service) GetMaintenanceRun(ctx context.Context, job string) (time.Time, error) {
+	var lastRun time.Time
+
+	err := s.conn(ctx).QueryRow(ctx, `SELECT last_run_at FROM maintenance_runs WHERE job = $1`, job).Scan(&lastRun)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		s.logger.Error("Failed to get maintenance run", zap.Error(err), zap.String("job", job))
+		return time.Time{}, err
+	}
+
+	return lastRun, nil
+}
+
+// SetMaintenanceRun сохраняет время последнего запуска задачи обслуживания
+func (s *Service) SetMaintenanceRun(ctx context.Context, job string, runAt time.Time) error {
+	query := `INSERT INTO maintenance_runs (job, last_run_at) VALUES ($1, $2)
+		ON CONFLICT (job) DO UPDATE SET last_run_at = EXCLUDED.last_run_at`
+
+	if _, err := s.conn(ctx).Exec(ctx, query, job, runAt); err != nil {
+		s.logger.Error("Failed to save maintenance run", zap.Error(err), zap.String("job", job))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetMaintenanceRun(t *testing.T) {
+	const query = `SELECT last_run_at FROM maintenance_runs WHERE job = \$1`
+	lastRun := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		want    time.Time
+		wantErr error
+	}{
+		{
+			name: "last run found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("expired_sessions").WillReturnRows(pgxmock.NewRows([]string{"last_run_at"}).AddRow(lastRun))
+			},
+			want: lastRun,
+		},
+		{
+			name: "never ran",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("expired_sessions").WillReturnError(pgx.ErrNoRows)
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("expired_sessions").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			got, err := s.GetMaintenanceRun(context.Background(), "expired_sessions")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got))
+		})
+	}
+}
+
+func TestService_SetMaintenanceRun(t *testing.T) {
+	s, db := newMockService(t)
+	runAt := time.Now()
+	db.ExpectExec(`INSERT INTO maintenance_runs \(job, last_run_at\) VALUES \(\$1, \$2\)\s+ON CONFLICT \(job\) DO UPDATE SET last_run_at = EXCLUDED.last_run_at`).
+		WithArgs("expired_sessions", runAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	assert.NoError(t, s.SetMaintenanceRun(context.Background(), "expired_sessions", runAt))
+}
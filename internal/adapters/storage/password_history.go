@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"go.uber.org/zap"
+)
+
+// AddPasswordHistory сохраняет хеш прежнего пароля и оставляет в истории пользователя не более keep последних записей
+func (s *Service) AddPasswordHistory(ctx context.Context, userID, passwordHash string, keep int) error {
+	query, args, err := squirrel.Insert("password_history").
+		Columns("id", "user_id", "password_hash", "created_at").
+		Values(app.GenerateUUID(), userID, passwordHash, time.Now().Format("2006-01-02 15:04:05")).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build add password history query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to add password history", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	// Записи старше keep последних больше не участвуют в проверке
+	trimQuery := `DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+		SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2
+	)`
+
+	if _, err = s.conn(ctx).Exec(ctx, trimQuery, userID, keep); err != nil {
+		s.logger.Error("Failed to trim password history", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// GetPasswordHistory возвращает хеши limit последних прежних паролей пользователя, начиная с самого нового
+func (s *Service) GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	query, args, err := squirrel.Select("password_hash").
+		From("password_history").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build get password history query", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := s.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to get password history", zap.Error(err), zap.String("user_id", userID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([]string, 0, limit)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			s.logger.Error("Failed to scan password history", zap.Error(err))
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.logger.Error("Failed to iterate password history", zap.Error(err))
+		return nil, err
+	}
+
+	return hashes, nil
+}
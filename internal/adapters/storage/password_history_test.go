@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_AddPasswordHistory(t *testing.T) {
+	const (
+		insertQuery = `INSERT INTO password_history \(id,user_id,password_hash,created_at\) VALUES \(\$1,\$2,\$3,\$4\)`
+		// Оставляем keep последних записей пользователя, остальные удаляем
+		trimQuery = `DELETE FROM password_history WHERE user_id = \$1 AND id NOT IN \(\s*SELECT id FROM password_history WHERE user_id = \$1 ORDER BY created_at DESC, id DESC LIMIT \$2\s*\)`
+	)
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "added and trimmed to keep",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(insertQuery).WithArgs(pgxmock.AnyArg(), "u1", "old-hash", pgxmock.AnyArg()).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				db.ExpectExec(trimQuery).WithArgs("u1", 4).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			},
+		},
+		{
+			name: "insert fails",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(insertQuery).WithArgs(pgxmock.AnyArg(), "u1", "old-hash", pgxmock.AnyArg()).WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+		{
+			name: "trim fails",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(insertQuery).WithArgs(pgxmock.AnyArg(), "u1", "old-hash", pgxmock.AnyArg()).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				db.ExpectExec(trimQuery).WithArgs("u1", 4).WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.AddPasswordHistory(context.Background(), "u1", "old-hash", 4)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_GetPasswordHistory(t *testing.T) {
+	const query = `SELECT password_hash FROM password_history WHERE user_id = \$1 ORDER BY created_at DESC, id DESC LIMIT 4`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "newest first",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1").
+					WillReturnRows(pgxmock.NewRows([]string{"password_hash"}).AddRow("hash-2").AddRow("hash-1"))
+			},
+			want: []string{"hash-2", "hash-1"},
+		},
+		{
+			name: "no history",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1").WillReturnRows(pgxmock.NewRows([]string{"password_hash"}))
+			},
+			want: []string{},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+		{
+			name: "iteration error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1").
+					WillReturnRows(pgxmock.NewRows([]string{"password_hash"}).AddRow("hash-2").RowError(0, errDB))
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			got, err := s.GetPasswordHistory(context.Background(), "u1", 4)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -13,8 +13,8 @@ import (
 // CreatePasswordReset создает новый запрос на сброс пароля
 func (s *Service) CreatePasswordReset(ctx context.Context, reset *domain.PasswordReset) error {
 	query, args, err := squirrel.Insert("password_resets").
-		Columns("id", "user_id", "token", "expires_at", "used", "created_at").
-		Values(reset.ID, reset.UserID, reset.Token, reset.ExpiresAt.Format("2006-01-02 15:04:05"), reset.Used, reset.CreatedAt.Format("2006-01-02 15:04:05")).
+		Columns("id", "user_id", "token_hash", "expires_at", "used", "created_at").
+		Values(reset.ID, reset.UserID, reset.TokenHash, reset.ExpiresAt.Format("2006-01-02 15:04:05"), reset.Used, reset.CreatedAt.Format("2006-01-02 15:04:05")).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
@@ -23,7 +23,7 @@ func (s *Service) CreatePasswordReset(ctx context.Context, reset *domain.Passwor
 		return err
 	}
 
-	_, err = s.db.Exec(ctx, query, args...)
+	_, err = s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to create password reset", zap.Error(err), zap.String("user_id", reset.UserID))
 		return err
@@ -33,11 +33,11 @@ func (s *Service) CreatePasswordReset(ctx context.Context, reset *domain.Passwor
 	return nil
 }
 
-// GetPasswordResetByToken получает запрос на сброс пароля по токену
-func (s *Service) GetPasswordResetByToken(ctx context.Context, token string) (*domain.PasswordReset, error) {
-	query, args, err := squirrel.Select("id", "user_id", "token", "expires_at", "used", "created_at").
+// GetPasswordResetByTokenHash получает запрос на сброс пароля по хешу токена
+func (s *Service) GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordReset, error) {
+	query, args, err := squirrel.Select("id", "user_id", "token_hash", "expires_at", "used", "created_at").
 		From("password_resets").
-		Where(squirrel.Eq{"token": token}).
+		Where(squirrel.Eq{"token_hash": tokenHash}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
@@ -47,10 +47,10 @@ func (s *Service) GetPasswordResetByToken(ctx context.Context, token string) (*d
 	}
 
 	var reset domain.PasswordReset
-	err = s.db.QueryRow(ctx, query, args...).Scan(
+	err = s.conn(ctx).QueryRow(ctx, query, args...).Scan(
 		&reset.ID,
 		&reset.UserID,
-		&reset.Token,
+		&reset.TokenHash,
 		&reset.ExpiresAt,
 		&reset.Used,
 		&reset.CreatedAt,
@@ -58,21 +58,22 @@ func (s *Service) GetPasswordResetByToken(ctx context.Context, token string) (*d
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			s.logger.Debug("Password reset not found", zap.String("token", token))
+			s.logger.Debug("Password reset not found")
 			return nil, domain.ErrUserNotFound
 		}
-		s.logger.Error("Failed to get password reset by token", zap.Error(err), zap.String("token", token))
+		s.logger.Error("Failed to get password reset by token", zap.Error(err))
 		return nil, err
 	}
 
 	return &reset, nil
 }
 
-// MarkPasswordResetAsUsed помечает запрос на сброс пароля как использованный
+// MarkPasswordResetAsUsed помечает запрос на сброс пароля как использованный.
+// Условие used = false гарантирует однократное использование при конкурентных запросах
 func (s *Service) MarkPasswordResetAsUsed(ctx context.Context, id string) error {
 	query, args, err := squirrel.Update("password_resets").
 		Set("used", true).
-		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"id": id, "used": false}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
@@ -81,7 +82,7 @@ func (s *Service) MarkPasswordResetAsUsed(ctx context.Context, id string) error
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to mark password reset as used", zap.Error(err), zap.String("reset_id", id))
 		return err
@@ -90,14 +91,35 @@ func (s *Service) MarkPasswordResetAsUsed(ctx context.Context, id string) error
 	rowsAffected := int64(tag.RowsAffected())
 
 	if rowsAffected == 0 {
-		s.logger.Debug("Password reset not found for marking as used", zap.String("reset_id", id))
-		return domain.ErrUserNotFound
+		s.logger.Debug("Password reset not found or already used", zap.String("reset_id", id))
+		return domain.ErrPasswordResetUsed
 	}
 
 	s.logger.Info("Password reset marked as used", zap.String("reset_id", id))
 	return nil
 }
 
+// CancelPasswordResets помечает использованными все незавершенные запросы пользователя на сброс пароля
+func (s *Service) CancelPasswordResets(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Update("password_resets").
+		Set("used", true).
+		Where(squirrel.Eq{"user_id": userID, "used": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build cancel password resets query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to cancel password resets", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
 // DeleteExpiredPasswordResets удаляет истекшие запросы на сброс пароля
 func (s *Service) DeleteExpiredPasswordResets(ctx context.Context) error {
 	query, args, err := squirrel.Delete("password_resets").
@@ -110,7 +132,7 @@ func (s *Service) DeleteExpiredPasswordResets(ctx context.Context) error {
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to delete expired password resets", zap.Error(err))
 		return err
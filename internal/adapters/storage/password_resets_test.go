@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreatePasswordReset(t *testing.T) {
+	const query = `INSERT INTO password_resets \(id,user_id,token_hash,expires_at,used,created_at\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6\)`
+	errDB := errors.New("db unavailable")
+	reset := &domain.PasswordReset{
+		ID:        "r1",
+		UserID:    "u1",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "created with token hash",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("r1", "u1", "hash", pgxmock.AnyArg(), false, pgxmock.AnyArg()).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("r1", "u1", "hash", pgxmock.AnyArg(), false, pgxmock.AnyArg()).
+					WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.CreatePasswordReset(context.Background(), reset)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_GetPasswordResetByTokenHash(t *testing.T) {
+	const query = `SELECT id, user_id, token_hash, expires_at, used, created_at FROM password_resets WHERE token_hash = \$1`
+	columns := []string{"id", "user_id", "token_hash", "expires_at", "used", "created_at"}
+	expiresAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		want    *domain.PasswordReset
+		wantErr error
+	}{
+		{
+			name: "found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").
+					WillReturnRows(pgxmock.NewRows(columns).AddRow("r1", "u1", "hash", expiresAt, false, createdAt))
+			},
+			want: &domain.PasswordReset{
+				ID:        "r1",
+				UserID:    "u1",
+				TokenHash: "hash",
+				ExpiresAt: expiresAt,
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "not found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").WillReturnError(pgx.ErrNoRows)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("hash").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			got, err := s.GetPasswordResetByTokenHash(context.Background(), "hash")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_CancelPasswordResets(t *testing.T) {
+	const query = `UPDATE password_resets SET used = \$1 WHERE used = \$2 AND user_id = \$3`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "pending resets cancelled",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(true, false, "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+			},
+		},
+		{
+			name: "no pending resets",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(true, false, "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(true, false, "u1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.CancelPasswordResets(context.Background(), "u1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
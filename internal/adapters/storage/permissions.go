@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// pgForeignKeyViolation код ошибки PostgreSQL при нарушении внешнего ключа
+const pgForeignKeyViolation = "23503"
+
+// ListPermissions возвращает справочник всех известных прав
+func (s *Service) ListPermissions(ctx context.Context) ([]domain.Permission, error) {
+	query, args, err := squirrel.Select("name").
+		From("permissions").
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build list permissions query", zap.Error(err))
+		return nil, err
+	}
+
+	return s.queryPermissions(ctx, query, args...)
+}
+
+// GetRolePermissions возвращает права, выданные роли
+func (s *Service) GetRolePermissions(ctx context.Context, role domain.UserRole) ([]domain.Permission, error) {
+	query, args, err := squirrel.Select("permission").
+		From("role_permissions").
+		Where(squirrel.Eq{"role": string(role)}).
+		OrderBy("permission").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build get role permissions query", zap.Error(err))
+		return nil, err
+	}
+
+	return s.queryPermissions(ctx, query, args...)
+}
+
+// GetUserPermissions возвращает права, выданные пользователю напрямую (без учета роли)
+func (s *Service) GetUserPermissions(ctx context.Context, userID string) ([]domain.Permission, error) {
+	query, args, err := squirrel.Select("permission").
+		From("user_permissions").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("permission").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build get user permissions query", zap.Error(err))
+		return nil, err
+	}
+
+	return s.queryPermissions(ctx, query, args...)
+}
+
+// GrantRolePermission выдает право роли; повторная выдача не является ошибкой
+func (s *Service) GrantRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error {
+	query, args, err := squirrel.Insert("role_permissions").
+		Columns("role", "permission").
+		Values(string(role), string(permission)).
+		Suffix("ON CONFLICT (role, permission) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build grant role permission query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrPermissionNotFound
+		}
+		s.logger.Error("Failed to grant role permission", zap.Error(err), zap.String("role", string(role)))
+		return err
+	}
+
+	s.logger.Info("Permission granted to role", zap.String("role", string(role)), zap.String("permission", string(permission)))
+	return nil
+}
+
+// RevokeRolePermission отзывает право у роли
+func (s *Service) RevokeRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error {
+	query, args, err := squirrel.Delete("role_permissions").
+		Where(squirrel.Eq{"role": string(role), "permission": string(permission)}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build revoke role permission query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to revoke role permission", zap.Error(err), zap.String("role", string(role)))
+		return err
+	}
+
+	s.logger.Info("Permission revoked from role", zap.String("role", string(role)), zap.String("permission", string(permission)))
+	return nil
+}
+
+// GrantUserPermission выдает право пользователю; повторная выдача не является ошибкой
+func (s *Service) GrantUserPermission(ctx context.Context, userID string, permission domain.Permission) error {
+	query, args, err := squirrel.Insert("user_permissions").
+		Columns("user_id", "permission").
+		Values(userID, string(permission)).
+		Suffix("ON CONFLICT (user_id, permission) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build grant user permission query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			if strings.Contains(pgErr.ConstraintName, "user_id") {
+				return domain.ErrUserNotFound
+			}
+			return domain.ErrPermissionNotFound
+		}
+		s.logger.Error("Failed to grant user permission", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Permission granted to user", zap.String("user_id", userID), zap.String("permission", string(permission)))
+	return nil
+}
+
+// RevokeUserPermission отзывает право, выданное пользователю напрямую
+func (s *Service) RevokeUserPermission(ctx context.Context, userID string, permission domain.Permission) error {
+	query, args, err := squirrel.Delete("user_permissions").
+		Where(squirrel.Eq{"user_id": userID, "permission": string(permission)}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build revoke user permission query", zap.Error(err))
+		return err
+	}
+
+	if _, err = s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to revoke user permission", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Permission revoked from user", zap.String("user_id", userID), zap.String("permission", string(permission)))
+	return nil
+}
+
+// queryPermissions выполняет запрос, возвращающий одну колонку с названиями прав
+func (s *Service) queryPermissions(ctx context.Context, query string, args ...interface{}) ([]domain.Permission, error) {
+	rows, err := s.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to query permissions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make([]domain.Permission, 0)
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			s.logger.Error("Failed to scan permission", zap.Error(err))
+			return nil, err
+		}
+		permissions = append(permissions, domain.Permission(permission))
+	}
+
+	if err = rows.Err(); err != nil {
+		s.logger.Error("Failed to iterate permissions", zap.Error(err))
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// isForeignKeyViolation проверяет, является ли ошибка нарушением внешнего ключа
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation
+}
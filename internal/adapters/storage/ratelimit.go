@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// slidingWindowScript атомарно учитывает запрос в скользящем окне (sorted set с временем запросов).
+// Отклоненные запросы не записываются, поэтому не продлевают блокировку
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+// AllowRequest учитывает запрос по ключу key и проверяет лимит limit запросов за window
+func (s *Service) AllowRequest(ctx context.Context, key string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+
+	values, err := slidingWindowScript.Run(ctx, s.redis,
+		[]string{app.RateLimitPrefix + key},
+		now, window.Milliseconds(), limit, app.GenerateUUID(),
+	).Int64Slice()
+	if err != nil {
+		s.logger.Error("Failed to check rate limit", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return &domain.RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  max(limit-int(values[1]), 0),
+		ResetAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -2,73 +2,245 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-// SetRefreshToken сохраняет refresh токен в Redis
-func (s *Service) SetRefreshToken(ctx context.Context, userID, refreshToken string, expiration time.Duration) error {
-	key := fmt.Sprintf("%s%s", app.RefreshTokenPrefix, userID)
+// refreshTokensKey возвращает ключ хеша refresh токенов пользователя (поле - устройство)
+func refreshTokensKey(userID string) string {
+	return app.RefreshTokensPrefix + userID
+}
+
+// SetRefreshToken сохраняет refresh токен устройства пользователя в Redis.
+// Срок жизни продлевается для всего хеша: просроченные токены других устройств
+// все равно отсекаются по exp и сессии в PostgreSQL
+func (s *Service) SetRefreshToken(ctx context.Context, userID, deviceID, refreshToken string, expiration time.Duration) error {
+	key := refreshTokensKey(userID)
 
-	err := s.redis.Set(ctx, key, refreshToken, expiration).Err()
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, deviceID, refreshToken)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to set refresh token in Redis", zap.Error(err), zap.String("user_id", userID))
+		s.logger.Error("Failed to set refresh token in Redis", zap.Error(err), zap.String("user_id", userID), zap.String("device_id", deviceID))
 		return err
 	}
 
-	s.logger.Debug("Refresh token set in Redis", zap.String("user_id", userID))
+	s.logger.Debug("Refresh token set in Redis", zap.String("user_id", userID), zap.String("device_id", deviceID))
 	return nil
 }
 
-// GetRefreshToken получает refresh токен из Redis
-func (s *Service) GetRefreshToken(ctx context.Context, userID string) (string, error) {
-	key := fmt.Sprintf("%s%s", app.RefreshTokenPrefix, userID)
-
-	token, err := s.redis.Get(ctx, key).Result()
+// GetRefreshToken получает refresh токен устройства пользователя из Redis
+func (s *Service) GetRefreshToken(ctx context.Context, userID, deviceID string) (string, error) {
+	token, err := s.redis.HGet(ctx, refreshTokensKey(userID), deviceID).Result()
 	if err != nil {
-		s.logger.Debug("Refresh token not found in Redis", zap.Error(err), zap.String("user_id", userID))
+		s.logger.Debug("Refresh token not found in Redis", zap.Error(err), zap.String("user_id", userID), zap.String("device_id", deviceID))
 		return "", err
 	}
 
-	s.logger.Debug("Refresh token retrieved from Redis", zap.String("user_id", userID))
+	s.logger.Debug("Refresh token retrieved from Redis", zap.String("user_id", userID), zap.String("device_id", deviceID))
 	return token, nil
 }
 
-// DeleteRefreshToken удаляет refresh токен из Redis
-func (s *Service) DeleteRefreshToken(ctx context.Context, userID string) error {
-	key := fmt.Sprintf("%s%s", app.RefreshTokenPrefix, userID)
-
-	err := s.redis.Del(ctx, key).Err()
+// DeleteRefreshToken удаляет refresh токен устройства пользователя из Redis
+func (s *Service) DeleteRefreshToken(ctx context.Context, userID, deviceID string) error {
+	err := s.redis.HDel(ctx, refreshTokensKey(userID), deviceID).Err()
 	if err != nil {
-		s.logger.Error("Failed to delete refresh token from Redis", zap.Error(err), zap.String("user_id", userID))
+		s.logger.Error("Failed to delete refresh token from Redis", zap.Error(err), zap.String("user_id", userID), zap.String("device_id", deviceID))
 		return err
 	}
 
-	s.logger.Debug("Refresh token deleted from Redis", zap.String("user_id", userID))
+	s.logger.Debug("Refresh token deleted from Redis", zap.String("user_id", userID), zap.String("device_id", deviceID))
 	return nil
 }
 
-// DeleteAllUserRefreshTokens удаляет все refresh токены пользователя из Redis
+// DeleteAllUserRefreshTokens удаляет refresh токены всех устройств пользователя одним DEL
 func (s *Service) DeleteAllUserRefreshTokens(ctx context.Context, userID string) error {
-	pattern := fmt.Sprintf("%s%s*", app.RefreshTokenPrefix, userID)
+	err := s.redis.Del(ctx, refreshTokensKey(userID)).Err()
+	if err != nil {
+		s.logger.Error("Failed to delete refresh tokens from Redis", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
 
-	keys, err := s.redis.Keys(ctx, pattern).Result()
+	s.logger.Debug("All refresh tokens deleted from Redis", zap.String("user_id", userID))
+	return nil
+}
+
+// RevokeAccessToken добавляет jti access токена в denylist до истечения срока его действия
+func (s *Service) RevokeAccessToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		// Токен уже истек, блокировать нечего
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%s", app.AccessDenylistPrefix, tokenID)
+
+	err := s.redis.Set(ctx, key, 1, ttl).Err()
 	if err != nil {
-		s.logger.Error("Failed to get refresh token keys from Redis", zap.Error(err), zap.String("user_id", userID))
+		s.logger.Error("Failed to revoke access token in Redis", zap.Error(err))
 		return err
 	}
 
-	if len(keys) > 0 {
-		err = s.redis.Del(ctx, keys...).Err()
+	s.logger.Debug("Access token revoked", zap.Duration("ttl", ttl))
+	return nil
+}
+
+// RevokeSessionTokens отзывает access токены, выданные на указанные сессии, до истечения срока их действия
+func (s *Service) RevokeSessionTokens(ctx context.Context, sessionIDs []string, ttl time.Duration) error {
+	if len(sessionIDs) == 0 || ttl <= 0 {
+		return nil
+	}
+
+	pipe := s.redis.Pipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Set(ctx, app.RevokedSessionPrefix+sessionID, 1, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Error("Failed to revoke session tokens in Redis", zap.Error(err))
+		return err
+	}
+
+	s.logger.Debug("Session tokens revoked", zap.Int("sessions", len(sessionIDs)), zap.Duration("ttl", ttl))
+	return nil
+}
+
+// SetTokensValidAfter делает недействительными все access токены пользователя, выданные до validAfter.
+// Отметка хранится ttl (срок жизни access токена): после этого старые токены истекают сами
+func (s *Service) SetTokensValidAfter(ctx context.Context, userID string, validAfter time.Time, ttl time.Duration) error {
+	// Миллисекунды: отзываются токены, выданные не позже этой миллисекунды, выданные в следующую остаются действительными
+	err := s.redis.Set(ctx, app.TokensValidAfterPrefix+userID, validAfter.UnixMilli(), ttl).Err()
+	if err != nil {
+		s.logger.Error("Failed to set tokens watermark in Redis", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Debug("Tokens watermark set", zap.String("user_id", userID), zap.Time("valid_after", validAfter))
+	return nil
+}
+
+// GetAccessTokenStatus возвращает состояние отзыва access токена за один запрос к Redis.
+// Пустой sessionID (токены без claim sid) не проверяется по списку отозванных сессий
+func (s *Service) GetAccessTokenStatus(ctx context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error) {
+	keys := []string{
+		app.AccessDenylistPrefix + tokenID,
+		app.BannedUserPrefix + userID,
+		app.TokensValidAfterPrefix + userID,
+	}
+	if sessionID != "" {
+		keys = append(keys, app.RevokedSessionPrefix+sessionID)
+	}
+
+	values, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		s.logger.Error("Failed to get access token status", zap.Error(err), zap.String("user_id", userID))
+		return nil, err
+	}
+
+	status := &domain.AccessTokenStatus{
+		Revoked:    values[0] != nil || (len(values) > 3 && values[3] != nil),
+		UserBanned: values[1] != nil,
+	}
+
+	if raw, ok := values[2].(string); ok {
+		millis, err := strconv.ParseInt(raw, 10, 64)
 		if err != nil {
-			s.logger.Error("Failed to delete refresh tokens from Redis", zap.Error(err), zap.String("user_id", userID))
-			return err
+			s.logger.Error("Corrupted tokens watermark", zap.Error(err), zap.String("user_id", userID))
+			return nil, err
 		}
-		s.logger.Debug("All refresh tokens deleted from Redis", zap.String("user_id", userID), zap.Int("count", len(keys)))
+		status.ValidAfter = time.UnixMilli(millis)
+	}
+
+	return status, nil
+}
+
+// SetUserBan помечает пользователя заблокированным для проверки access токенов.
+// ttl <= 0 означает бессрочную блокировку
+func (s *Service) SetUserBan(ctx context.Context, userID string, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	if err := s.redis.Set(ctx, app.BannedUserPrefix+userID, 1, ttl).Err(); err != nil {
+		s.logger.Error("Failed to set user ban in Redis", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Debug("User ban set in Redis", zap.String("user_id", userID), zap.Duration("ttl", ttl))
+	return nil
+}
+
+// DeleteUserBan снимает отметку о блокировке пользователя
+func (s *Service) DeleteUserBan(ctx context.Context, userID string) error {
+	if err := s.redis.Del(ctx, app.BannedUserPrefix+userID).Err(); err != nil {
+		s.logger.Error("Failed to delete user ban from Redis", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Debug("User ban deleted from Redis", zap.String("user_id", userID))
+	return nil
+}
+
+// permissionsCacheKey возвращает ключ кеша прав роли или пользователя
+func permissionsCacheKey(scope domain.PermissionScope, id string) string {
+	return fmt.Sprintf("%s%s:%s", app.PermissionsCachePrefix, scope, id)
+}
+
+// GetCachedPermissions возвращает права из кеша; found=false, если кеш пуст
+func (s *Service) GetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) ([]domain.Permission, bool, error) {
+	data, err := s.redis.Get(ctx, permissionsCacheKey(scope, id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		s.logger.Error("Failed to get cached permissions", zap.Error(err), zap.String("scope", string(scope)), zap.String("id", id))
+		return nil, false, err
+	}
+
+	var permissions []domain.Permission
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		s.logger.Warn("Corrupted permissions cache entry", zap.Error(err), zap.String("scope", string(scope)), zap.String("id", id))
+		return nil, false, nil
+	}
+
+	return permissions, true, nil
+}
+
+// SetCachedPermissions сохраняет права в кеш (включая пустой список, чтобы не ходить в БД повторно)
+func (s *Service) SetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string, permissions []domain.Permission, ttl time.Duration) error {
+	if permissions == nil {
+		permissions = []domain.Permission{}
+	}
+
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+
+	if err := s.redis.Set(ctx, permissionsCacheKey(scope, id), data, ttl).Err(); err != nil {
+		s.logger.Error("Failed to cache permissions", zap.Error(err), zap.String("scope", string(scope)), zap.String("id", id))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCachedPermissions сбрасывает кеш прав роли или пользователя
+func (s *Service) DeleteCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) error {
+	if err := s.redis.Del(ctx, permissionsCacheKey(scope, id)).Err(); err != nil {
+		s.logger.Error("Failed to invalidate permissions cache", zap.Error(err), zap.String("scope", string(scope)), zap.String("id", id))
+		return err
 	}
 
+	s.logger.Debug("Permissions cache invalidated", zap.String("scope", string(scope)), zap.String("id", id))
 	return nil
 }
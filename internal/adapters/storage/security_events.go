@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// CreateSecurityEvent записывает событие безопасности в журнал
+func (s *Service) CreateSecurityEvent(ctx context.Context, event *domain.SecurityEvent) error {
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		s.logger.Error("Failed to marshal security event details", zap.Error(err))
+		return err
+	}
+	if event.Details == nil {
+		details = []byte("{}")
+	}
+
+	query, args, err := squirrel.Insert("security_events").
+		Columns("id", "user_id", "event_type", "ip_address", "user_agent", "details", "created_at").
+		Values(event.ID, event.UserID, string(event.Type), event.IPAddress, event.UserAgent, string(details), event.CreatedAt.Format("2006-01-02 15:04:05")).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build create security event query", zap.Error(err))
+		return err
+	}
+
+	_, err = s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to create security event", zap.Error(err), zap.String("user_id", event.UserID))
+		return err
+	}
+
+	s.logger.Info("Security event recorded",
+		zap.String("event_id", event.ID),
+		zap.String("user_id", event.UserID),
+		zap.String("type", string(event.Type)),
+	)
+	return nil
+}
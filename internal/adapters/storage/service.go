@@ -9,7 +9,7 @@ import (
 
 // Service представляет storage сервис
 type Service struct {
-	db     *pgxpool.Pool
+	db     database
 	redis  *redis.Client
 	config *config.Config
 	logger *zap.Logger
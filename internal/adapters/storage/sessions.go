@@ -10,11 +10,33 @@ import (
 	"go.uber.org/zap"
 )
 
+// sessionColumns колонки таблицы user_sessions в порядке сканирования scanSession
+var sessionColumns = []string{
+	"id", "user_id", "token_hash", "device_id", "app_type", "app_version",
+	"ip_address", "user_agent", "last_seen_at", "family_id", "rotated_at",
+	"replaced_by", "expires_at", "created_at",
+}
+
 // CreateSession создает новую сессию пользователя
 func (s *Service) CreateSession(ctx context.Context, session *domain.UserSession) error {
 	query, args, err := squirrel.Insert("user_sessions").
-		Columns("id", "user_id", "token_hash", "expires_at", "created_at").
-		Values(session.ID, session.UserID, session.TokenHash, session.ExpiresAt.Format("2006-01-02 15:04:05"), session.CreatedAt.Format("2006-01-02 15:04:05")).
+		Columns(sessionColumns...).
+		Values(
+			session.ID,
+			session.UserID,
+			session.TokenHash,
+			session.DeviceID,
+			session.AppType,
+			session.AppVersion,
+			session.IPAddress,
+			session.UserAgent,
+			session.LastSeenAt.Format("2006-01-02 15:04:05"),
+			session.FamilyID,
+			session.RotatedAt,
+			session.ReplacedBy,
+			session.ExpiresAt.Format("2006-01-02 15:04:05"),
+			session.CreatedAt.Format("2006-01-02 15:04:05"),
+		).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
@@ -23,7 +45,7 @@ func (s *Service) CreateSession(ctx context.Context, session *domain.UserSession
 		return err
 	}
 
-	_, err = s.db.Exec(ctx, query, args...)
+	_, err = s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to create session", zap.Error(err), zap.String("user_id", session.UserID))
 		return err
@@ -35,7 +57,7 @@ func (s *Service) CreateSession(ctx context.Context, session *domain.UserSession
 
 // GetSessionByID получает сессию по ID
 func (s *Service) GetSessionByID(ctx context.Context, id string) (*domain.UserSession, error) {
-	query, args, err := squirrel.Select("id", "user_id", "token_hash", "expires_at", "created_at").
+	query, args, err := squirrel.Select(sessionColumns...).
 		From("user_sessions").
 		Where(squirrel.Eq{"id": id}).
 		PlaceholderFormat(squirrel.Dollar).
@@ -46,15 +68,7 @@ func (s *Service) GetSessionByID(ctx context.Context, id string) (*domain.UserSe
 		return nil, err
 	}
 
-	var session domain.UserSession
-	err = s.db.QueryRow(ctx, query, args...).Scan(
-		&session.ID,
-		&session.UserID,
-		&session.TokenHash,
-		&session.ExpiresAt,
-		&session.CreatedAt,
-	)
-
+	session, err := scanSession(s.conn(ctx).QueryRow(ctx, query, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			s.logger.Debug("Session not found", zap.String("session_id", id))
@@ -64,12 +78,12 @@ func (s *Service) GetSessionByID(ctx context.Context, id string) (*domain.UserSe
 		return nil, err
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 // GetSessionsByUserID получает все сессии пользователя
 func (s *Service) GetSessionsByUserID(ctx context.Context, userID string) ([]*domain.UserSession, error) {
-	query, args, err := squirrel.Select("id", "user_id", "token_hash", "expires_at", "created_at").
+	query, args, err := squirrel.Select(sessionColumns...).
 		From("user_sessions").
 		Where(squirrel.Eq{"user_id": userID}).
 		OrderBy("created_at DESC").
@@ -81,7 +95,7 @@ func (s *Service) GetSessionsByUserID(ctx context.Context, userID string) ([]*do
 		return nil, err
 	}
 
-	rows, err := s.db.Query(ctx, query, args...)
+	rows, err := s.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to get sessions by user ID", zap.Error(err), zap.String("user_id", userID))
 		return nil, err
@@ -90,19 +104,12 @@ func (s *Service) GetSessionsByUserID(ctx context.Context, userID string) ([]*do
 
 	var sessions []*domain.UserSession
 	for rows.Next() {
-		var session domain.UserSession
-		err := rows.Scan(
-			&session.ID,
-			&session.UserID,
-			&session.TokenHash,
-			&session.ExpiresAt,
-			&session.CreatedAt,
-		)
+		session, err := scanSession(rows)
 		if err != nil {
 			s.logger.Error("Failed to scan session", zap.Error(err))
 			return nil, err
 		}
-		sessions = append(sessions, &session)
+		sessions = append(sessions, session)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -125,7 +132,7 @@ func (s *Service) DeleteSession(ctx context.Context, id string) error {
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to delete session", zap.Error(err), zap.String("session_id", id))
 		return err
@@ -142,6 +149,140 @@ func (s *Service) DeleteSession(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteSessionsByUserID удаляет все сессии пользователя
+func (s *Service) DeleteSessionsByUserID(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Delete("user_sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete user sessions query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to delete user sessions", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("User sessions deleted", zap.String("user_id", userID), zap.Int64("count", tag.RowsAffected()))
+	return nil
+}
+
+// MarkSessionRotated помечает сессию ротированной и связывает ее с новой сессией.
+// Возвращает domain.ErrSessionRotated, если сессия уже была ротирована (повторное использование токена),
+// и domain.ErrUserNotFound, если сессия удалена (выход, отзыв или повторный вход на устройстве)
+func (s *Service) MarkSessionRotated(ctx context.Context, id, replacedBy string) error {
+	query, args, err := squirrel.Update("user_sessions").
+		Set("rotated_at", time.Now().Format("2006-01-02 15:04:05")).
+		Set("replaced_by", replacedBy).
+		Where(squirrel.Eq{"id": id, "rotated_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build mark session rotated query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to mark session rotated", zap.Error(err), zap.String("session_id", id))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		// Ротированная сессия сохраняется до удаления семейства, поэтому отсутствие строки
+		// означает завершенную сессию, а не повторное использование токена
+		var exists bool
+		err := s.conn(ctx).QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM user_sessions WHERE id = $1)`, id).Scan(&exists)
+		if err != nil {
+			s.logger.Error("Failed to check rotated session", zap.Error(err), zap.String("session_id", id))
+			return err
+		}
+		if !exists {
+			s.logger.Debug("Session not found for rotation", zap.String("session_id", id))
+			return domain.ErrUserNotFound
+		}
+
+		s.logger.Debug("Session already rotated", zap.String("session_id", id))
+		return domain.ErrSessionRotated
+	}
+
+	return nil
+}
+
+// DeleteSessionsByFamily удаляет все сессии семейства ротации
+func (s *Service) DeleteSessionsByFamily(ctx context.Context, familyID string) error {
+	query, args, err := squirrel.Delete("user_sessions").
+		Where(squirrel.Eq{"family_id": familyID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete session family query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to delete session family", zap.Error(err), zap.String("family_id", familyID))
+		return err
+	}
+
+	s.logger.Info("Session family deleted", zap.String("family_id", familyID), zap.Int64("count", tag.RowsAffected()))
+	return nil
+}
+
+// DeleteSessionsByDevice удаляет сессии пользователя на указанном устройстве
+func (s *Service) DeleteSessionsByDevice(ctx context.Context, userID, deviceID string) error {
+	query, args, err := squirrel.Delete("user_sessions").
+		Where(squirrel.Eq{"user_id": userID, "device_id": deviceID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete device sessions query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to delete device sessions", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		s.logger.Info("Device sessions replaced", zap.String("user_id", userID), zap.String("device_id", deviceID))
+	}
+	return nil
+}
+
+// CountActiveSessions возвращает количество неистекших сессий, не замененных ротацией
+func (s *Service) CountActiveSessions(ctx context.Context) (int, error) {
+	query, args, err := squirrel.Select("COUNT(*)").
+		From("user_sessions").
+		Where(squirrel.Gt{"expires_at": time.Now().Format("2006-01-02 15:04:05")}).
+		Where(squirrel.Eq{"rotated_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build count sessions query", zap.Error(err))
+		return 0, err
+	}
+
+	var count int
+	if err := s.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		s.logger.Error("Failed to count active sessions", zap.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // DeleteExpiredSessions удаляет истекшие сессии
 func (s *Service) DeleteExpiredSessions(ctx context.Context) error {
 	query, args, err := squirrel.Delete("user_sessions").
@@ -154,7 +295,7 @@ func (s *Service) DeleteExpiredSessions(ctx context.Context) error {
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to delete expired sessions", zap.Error(err))
 		return err
@@ -168,3 +309,28 @@ func (s *Service) DeleteExpiredSessions(ctx context.Context) error {
 
 	return nil
 }
+
+// scanSession сканирует строку с колонками sessionColumns
+func scanSession(row pgx.Row) (*domain.UserSession, error) {
+	var session domain.UserSession
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.DeviceID,
+		&session.AppType,
+		&session.AppVersion,
+		&session.IPAddress,
+		&session.UserAgent,
+		&session.LastSeenAt,
+		&session.FamilyID,
+		&session.RotatedAt,
+		&session.ReplacedBy,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
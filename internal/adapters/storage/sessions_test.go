@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_MarkSessionRotated(t *testing.T) {
+	const query = `UPDATE user_sessions SET rotated_at = \$1, replaced_by = \$2 WHERE id = \$3 AND rotated_at IS NULL`
+	const existsQuery = `SELECT EXISTS\(SELECT 1 FROM user_sessions WHERE id = \$1\)`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "rotated",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(pgxmock.AnyArg(), "s2", "s1").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+		},
+		{
+			name: "already rotated by concurrent request",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(pgxmock.AnyArg(), "s2", "s1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				db.ExpectQuery(existsQuery).WithArgs("s1").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			wantErr: domain.ErrSessionRotated,
+		},
+		{
+			name: "deleted by concurrent logout",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(pgxmock.AnyArg(), "s2", "s1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				db.ExpectQuery(existsQuery).WithArgs("s1").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "existence check error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(pgxmock.AnyArg(), "s2", "s1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				db.ExpectQuery(existsQuery).WithArgs("s1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs(pgxmock.AnyArg(), "s2", "s1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.MarkSessionRotated(context.Background(), "s1", "s2")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_DeleteSessionsByFamily(t *testing.T) {
+	const query = `DELETE FROM user_sessions WHERE family_id = \$1`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "family deleted",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("f1").WillReturnResult(pgxmock.NewResult("DELETE", 3))
+			},
+		},
+		{
+			name: "family already gone",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("f1").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+			},
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("f1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.DeleteSessionsByFamily(context.Background(), "f1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
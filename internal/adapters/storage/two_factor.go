@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// SetTOTPSecret сохраняет зашифрованный секрет TOTP; двухфакторная аутентификация включается после подтверждения кодом
+func (s *Service) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	return s.updateTOTP(ctx, userID, "set TOTP secret", map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	})
+}
+
+// EnableTOTP включает двухфакторную аутентификацию, step - шаг кода, которым она подтверждена
+func (s *Service) EnableTOTP(ctx context.Context, userID string, step int64) error {
+	return s.updateTOTP(ctx, userID, "enable TOTP", map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+	})
+}
+
+// DisableTOTP отключает двухфакторную аутентификацию и удаляет секрет
+func (s *Service) DisableTOTP(ctx context.Context, userID string) error {
+	return s.updateTOTP(ctx, userID, "disable TOTP", map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	})
+}
+
+// updateTOTP обновляет колонки TOTP пользователя
+func (s *Service) updateTOTP(ctx context.Context, userID, action string, values map[string]interface{}) error {
+	values["updated_at"] = time.Now().Format("2006-01-02 15:04:05")
+
+	query, args, err := squirrel.Update("users").
+		SetMap(values).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build TOTP update query", zap.Error(err), zap.String("action", action))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to "+action, zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for TOTP update", zap.String("user_id", userID), zap.String("action", action))
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UseTOTPStep запоминает шаг принятого кода TOTP. Возвращает false, если код этого или более позднего шага уже использован
+func (s *Service) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query, args, err := squirrel.Update("users").
+		Set("totp_last_step", step).
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Lt{"totp_last_step": step}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build use TOTP step query", zap.Error(err))
+		return false, err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to use TOTP step", zap.Error(err), zap.String("user_id", userID))
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReplaceRecoveryCodes заменяет коды восстановления пользователя новыми (передаются хеши кодов)
+func (s *Service) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	if err := s.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	insert := squirrel.Insert("user_recovery_codes").
+		Columns("id", "user_id", "code_hash", "created_at")
+	for _, codeHash := range codeHashes {
+		insert = insert.Values(app.GenerateUUID(), userID, codeHash, now)
+	}
+
+	query, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build insert recovery codes query", zap.Error(err))
+		return err
+	}
+
+	if _, err := s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to insert recovery codes", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Recovery codes replaced", zap.String("user_id", userID), zap.Int("count", len(codeHashes)))
+	return nil
+}
+
+// UseRecoveryCode помечает код восстановления использованным. Возвращает false, если кода нет или он уже использован
+func (s *Service) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query, args, err := squirrel.Update("user_recovery_codes").
+		Set("used_at", time.Now().Format("2006-01-02 15:04:05")).
+		Where(squirrel.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build use recovery code query", zap.Error(err))
+		return false, err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to use recovery code", zap.Error(err), zap.String("user_id", userID))
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountRecoveryCodes возвращает количество неиспользованных кодов восстановления
+func (s *Service) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query, args, err := squirrel.Select("COUNT(*)").
+		From("user_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID, "used_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build count recovery codes query", zap.Error(err))
+		return 0, err
+	}
+
+	var count int
+	if err := s.conn(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		s.logger.Error("Failed to count recovery codes", zap.Error(err), zap.String("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteRecoveryCodes удаляет все коды восстановления пользователя
+func (s *Service) DeleteRecoveryCodes(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Delete("user_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build delete recovery codes query", zap.Error(err))
+		return err
+	}
+
+	if _, err := s.conn(ctx).Exec(ctx, query, args...); err != nil {
+		s.logger.Error("Failed to delete recovery codes", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	return nil
+}
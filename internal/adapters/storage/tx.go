@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// txKey ключ контекста для активной транзакции
+type txKey struct{}
+
+// querier общий интерфейс пула соединений и транзакции
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// database интерфейс пула соединений, которым пользуется storage
+type database interface {
+	querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// RunInTx выполняет fn в транзакции. Все методы storage, вызванные с переданным
+// в fn контекстом, работают в этой транзакции. Вложенный вызов переиспользует внешнюю транзакцию.
+func (s *Service) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RunWithAdvisoryLock выполняет fn в транзакции, удерживающей advisory-блокировку name.
+// Если блокировку держит другой процесс, fn не вызывается и возвращается false
+func (s *Service) RunWithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	var acquired bool
+
+	err := s.RunInTx(ctx, func(ctx context.Context) error {
+		// Блокировка снимается автоматически при завершении транзакции, в том числе при падении процесса
+		if err := s.conn(ctx).QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, name).Scan(&acquired); err != nil {
+			s.logger.Error("Failed to acquire advisory lock", zap.Error(err), zap.String("lock", name))
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		return fn(ctx)
+	})
+
+	return acquired, err
+}
+
+// conn возвращает активную транзакцию из контекста или пул соединений
+func (s *Service) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return s.db
+}
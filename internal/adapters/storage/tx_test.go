@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newMockService создает storage с моком пула PostgreSQL; ожидания проверяются в конце теста
+func newMockService(t *testing.T) (*Service, pgxmock.PgxPoolIface) {
+	t.Helper()
+	db, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.ExpectationsWereMet())
+		db.Close()
+	})
+	return &Service{db: db, logger: zap.NewNop()}, db
+}
+
+func TestService_RunInTx(t *testing.T) {
+	errFn := errors.New("fn failed")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		fn      func(s *Service) func(ctx context.Context) error
+		wantErr error
+	}{
+		{
+			name: "queries run in transaction and commit",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectBegin()
+				db.ExpectExec(`DELETE FROM user_sessions WHERE user_id = \$1`).WithArgs("u1").WillReturnResult(pgxmock.NewResult("DELETE", 2))
+				db.ExpectCommit()
+			},
+			fn: func(s *Service) func(ctx context.Context) error {
+				return func(ctx context.Context) error { return s.DeleteSessionsByUserID(ctx, "u1") }
+			},
+		},
+		{
+			name: "error rolls back",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectBegin()
+				db.ExpectRollback()
+			},
+			fn: func(s *Service) func(ctx context.Context) error {
+				return func(ctx context.Context) error { return errFn }
+			},
+			wantErr: errFn,
+		},
+		{
+			name: "nested call reuses transaction",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectBegin()
+				db.ExpectCommit()
+			},
+			fn: func(s *Service) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					return s.RunInTx(ctx, func(ctx context.Context) error { return nil })
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.RunInTx(context.Background(), tt.fn(s))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_RunWithAdvisoryLock(t *testing.T) {
+	tests := []struct {
+		name         string
+		acquired     bool
+		wantAcquired bool
+		wantCalled   bool
+	}{
+		{name: "lock acquired", acquired: true, wantAcquired: true, wantCalled: true},
+		{name: "lock held by another instance", acquired: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			db.ExpectBegin()
+			db.ExpectQuery(`SELECT pg_try_advisory_xact_lock\(hashtext\(\$1\)\)`).WithArgs("maintenance:job").
+				WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(tt.acquired))
+			db.ExpectCommit()
+
+			called := false
+			acquired, err := s.RunWithAdvisoryLock(context.Background(), "maintenance:job", func(ctx context.Context) error {
+				called = true
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAcquired, acquired)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
@@ -2,22 +2,55 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/TeDenis/bukhindor-backend/internal/domain"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
+// pgUniqueViolation код ошибки PostgreSQL при нарушении уникальности
+const pgUniqueViolation = "23505"
+
+// userSortColumns допустимые колонки для сортировки списка пользователей
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"email":      "email",
+	"name":       "name",
+}
+
+// userColumns колонки таблицы users в порядке сканирования scanUser
+var userColumns = []string{
+	"id", "email", "name", "password_hash", "role", "status", "ban_reason",
+	"banned_until", "banned_by", "banned_at", "failed_login_attempts", "last_failed_login_at",
+	"locked_until", "totp_secret", "totp_enabled", "totp_last_step", "email_verified_at",
+	"created_at", "updated_at",
+}
+
 // CreateUser создает нового пользователя
 func (s *Service) CreateUser(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (id, email, name, password_hash, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if user.Role == "" {
+		user.Role = domain.UserRoleUser
+	}
+	if user.Status == "" {
+		user.Status = domain.UserStatusActive
+	}
+
+	query := `INSERT INTO users (id, email, name, password_hash, role, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	s.logger.Debug("Generated SQL query", zap.String("query", query), zap.Any("args", []interface{}{user.ID, user.Email, user.Name, user.PasswordHash, user.IsActive, user.CreatedAt, user.UpdatedAt}))
+	s.logger.Debug("Generated SQL query", zap.String("query", query), zap.Any("args", []interface{}{user.ID, user.Email, user.Name, user.Role, user.Status, user.CreatedAt, user.UpdatedAt}))
 
-	_, err := s.db.Exec(ctx, query, user.ID, user.Email, user.Name, user.PasswordHash, user.IsActive, user.CreatedAt, user.UpdatedAt)
+	_, err := s.conn(ctx).Exec(ctx, query, user.ID, user.Email, user.Name, user.PasswordHash, string(user.Role), string(user.Status), user.CreatedAt, user.UpdatedAt)
 	if err != nil {
+		if isUniqueViolation(err) {
+			s.logger.Debug("User with email already exists", zap.String("email", user.Email))
+			return domain.ErrUserExists
+		}
 		s.logger.Error("Failed to create user", zap.Error(err), zap.String("email", user.Email))
 		return err
 	}
@@ -28,7 +61,7 @@ func (s *Service) CreateUser(ctx context.Context, user *domain.User) error {
 
 // GetUserByID получает пользователя по ID
 func (s *Service) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
-	query, args, err := squirrel.Select("id", "email", "name", "password_hash", "is_active", "created_at", "updated_at").
+	query, args, err := squirrel.Select(userColumns...).
 		From("users").
 		Where(squirrel.Eq{"id": id}).
 		PlaceholderFormat(squirrel.Dollar).
@@ -39,17 +72,7 @@ func (s *Service) GetUserByID(ctx context.Context, id string) (*domain.User, err
 		return nil, err
 	}
 
-	var user domain.User
-	err = s.db.QueryRow(ctx, query, args...).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(s.conn(ctx).QueryRow(ctx, query, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			s.logger.Debug("User not found", zap.String("user_id", id))
@@ -59,26 +82,16 @@ func (s *Service) GetUserByID(ctx context.Context, id string) (*domain.User, err
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetUserByEmail получает пользователя по email
 func (s *Service) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, email, name, password_hash, is_active, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT ` + strings.Join(userColumns, ", ") + ` FROM users WHERE email = $1`
 
 	s.logger.Debug("Generated SQL query for GetUserByEmail", zap.String("query", query), zap.String("email", email))
 
-	var user domain.User
-	err := s.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.PasswordHash,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(s.conn(ctx).QueryRow(ctx, query, email))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			s.logger.Debug("User not found", zap.String("email", email))
@@ -88,41 +101,192 @@ func (s *Service) GetUserByEmail(ctx context.Context, email string) (*domain.Use
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// ListUsers возвращает страницу пользователей и общее количество записей по фильтру
+func (s *Service) ListUsers(ctx context.Context, filter domain.UserListFilter) ([]*domain.User, int, error) {
+	where := squirrel.And{}
+	if filter.Status != "" {
+		where = append(where, statusCondition(filter.Status))
+	}
+	if filter.EmailPrefix != "" {
+		where = append(where, squirrel.ILike{"email": escapeLike(filter.EmailPrefix) + "%"})
+	}
+
+	countQuery, countArgs, err := squirrel.Select("COUNT(*)").
+		From("users").
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build count users query", zap.Error(err))
+		return nil, 0, err
+	}
+
+	var total int
+	if err = s.conn(ctx).QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		s.logger.Error("Failed to count users", zap.Error(err))
+		return nil, 0, err
+	}
+
+	sortColumn, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	query, args, err := squirrel.Select(userColumns...).
+		From("users").
+		Where(where).
+		OrderBy(sortColumn+" "+direction, "id "+direction).
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build list users query", zap.Error(err))
+		return nil, 0, err
+	}
+
+	rows, err := s.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to list users", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0, filter.Limit)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			s.logger.Error("Failed to scan user", zap.Error(err))
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.logger.Error("Failed to iterate users", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return users, total, nil
 }
 
-// UpdateUser обновляет пользователя
-func (s *Service) UpdateUser(ctx context.Context, user *domain.User) error {
+// UpdateUser обновляет только переданные поля пользователя, не затрагивая роль и данные блокировки,
+// которые могли измениться после чтения. Статус меняется, только если он по-прежнему равен
+// update.FromStatus и действующей блокировки нет, иначе возвращается domain.ErrUserStatusChanged
+func (s *Service) UpdateUser(ctx context.Context, userID string, update domain.UserUpdate) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	builder := squirrel.Update("users").
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	if update.Name != nil {
+		builder = builder.Set("name", *update.Name)
+	}
+	if update.Email != nil {
+		builder = builder.
+			Set("email", *update.Email).
+			Set("email_verified_at", nil)
+	}
+	if update.Status != nil {
+		builder = builder.
+			Set("status", string(*update.Status)).
+			Set("ban_reason", "").
+			Set("banned_until", nil).
+			Set("banned_by", "").
+			Set("banned_at", nil).
+			Where(squirrel.Eq{"status": string(update.FromStatus)}).
+			Where("(status <> ? OR banned_until <= ?)", string(domain.UserStatusBanned), now)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build update user query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			s.logger.Debug("Email already taken by another user", zap.String("user_id", userID))
+			return domain.ErrUserExists
+		}
+		s.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		if update.Status == nil {
+			s.logger.Debug("User not found for update", zap.String("user_id", userID))
+			return domain.ErrUserNotFound
+		}
+		return s.userUpdateConflict(ctx, userID)
+	}
+
+	s.logger.Info("User updated successfully", zap.String("user_id", userID))
+	return nil
+}
+
+// userUpdateConflict определяет, почему условное обновление статуса не затронуло ни одной строки
+func (s *Service) userUpdateConflict(ctx context.Context, userID string) error {
+	var exists bool
+	err := s.conn(ctx).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		s.logger.Error("Failed to check user existence", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if !exists {
+		s.logger.Debug("User not found for update", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	s.logger.Debug("User status changed before update", zap.String("user_id", userID))
+	return domain.ErrUserStatusChanged
+}
+
+// UpdateUserStatus сохраняет статус пользователя и данные блокировки
+func (s *Service) UpdateUserStatus(ctx context.Context, user *domain.User) error {
 	user.UpdatedAt = time.Now()
 
 	query, args, err := squirrel.Update("users").
-		Set("email", user.Email).
-		Set("name", user.Name).
-		Set("is_active", user.IsActive).
+		Set("status", string(user.Status)).
+		Set("ban_reason", user.BanReason).
+		Set("banned_until", formatNullableTime(user.BannedUntil)).
+		Set("banned_by", user.BannedBy).
+		Set("banned_at", formatNullableTime(user.BannedAt)).
 		Set("updated_at", user.UpdatedAt.Format("2006-01-02 15:04:05")).
 		Where(squirrel.Eq{"id": user.ID}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
 	if err != nil {
-		s.logger.Error("Failed to build update user query", zap.Error(err))
+		s.logger.Error("Failed to build update user status query", zap.Error(err))
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
-		s.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", user.ID))
+		s.logger.Error("Failed to update user status", zap.Error(err), zap.String("user_id", user.ID))
 		return err
 	}
 
-	rowsAffected := int64(tag.RowsAffected())
-
-	if rowsAffected == 0 {
-		s.logger.Debug("User not found for update", zap.String("user_id", user.ID))
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for status update", zap.String("user_id", user.ID))
 		return domain.ErrUserNotFound
 	}
 
-	s.logger.Info("User updated successfully", zap.String("user_id", user.ID))
+	s.logger.Info("User status updated", zap.String("user_id", user.ID), zap.String("status", string(user.Status)))
 	return nil
 }
 
@@ -138,7 +302,7 @@ func (s *Service) DeleteUser(ctx context.Context, id string) error {
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", id))
 		return err
@@ -169,7 +333,7 @@ func (s *Service) UpdatePassword(ctx context.Context, userID, passwordHash strin
 		return err
 	}
 
-	tag, err := s.db.Exec(ctx, query, args...)
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		s.logger.Error("Failed to update password", zap.Error(err), zap.String("user_id", userID))
 		return err
@@ -185,3 +349,73 @@ func (s *Service) UpdatePassword(ctx context.Context, userID, passwordHash strin
 	s.logger.Info("Password updated successfully", zap.String("user_id", userID))
 	return nil
 }
+
+// scanUser сканирует строку с колонками userColumns
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Status,
+		&user.BanReason,
+		&user.BannedUntil,
+		&user.BannedBy,
+		&user.BannedAt,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.TOTPLastStep,
+		&user.EmailVerifiedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// formatNullableTime форматирует необязательное время для записи в колонку TIMESTAMP NULL
+func formatNullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// isUniqueViolation проверяет, является ли ошибка нарушением уникального ограничения
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// statusCondition строит условие фильтра по статусу с учетом срока блокировки: истекшая блокировка считается снятой
+func statusCondition(status domain.UserStatus) squirrel.Sqlizer {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	banExpired := squirrel.And{
+		squirrel.Eq{"status": string(domain.UserStatusBanned)},
+		squirrel.LtOrEq{"banned_until": now},
+	}
+
+	switch status {
+	case domain.UserStatusActive:
+		return squirrel.Or{squirrel.Eq{"status": string(domain.UserStatusActive)}, banExpired}
+	case domain.UserStatusBanned:
+		return squirrel.And{
+			squirrel.Eq{"status": string(domain.UserStatusBanned)},
+			squirrel.Or{squirrel.Eq{"banned_until": nil}, squirrel.Gt{"banned_until": now}},
+		}
+	default:
+		return squirrel.Eq{"status": string(status)}
+	}
+}
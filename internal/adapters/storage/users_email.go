@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// MarkEmailVerified отмечает email пользователя подтвержденным
+func (s *Service) MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error {
+	query, args, err := squirrel.Update("users").
+		Set("email_verified_at", verifiedAt.Format("2006-01-02 15:04:05")).
+		Set("updated_at", time.Now().Format("2006-01-02 15:04:05")).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build mark email verified query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to mark email verified", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for email verification", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	s.logger.Info("User email verified", zap.String("user_id", userID))
+	return nil
+}
+
+// UpdateUserEmail меняет email пользователя на подтвержденный новый адрес
+func (s *Service) UpdateUserEmail(ctx context.Context, userID, email string) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	query, args, err := squirrel.Update("users").
+		Set("email", email).
+		Set("email_verified_at", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build update user email query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			s.logger.Debug("Email already taken by another user", zap.String("user_id", userID))
+			return domain.ErrUserExists
+		}
+		s.logger.Error("Failed to update user email", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for email update", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	s.logger.Info("User email changed", zap.String("user_id", userID))
+	return nil
+}
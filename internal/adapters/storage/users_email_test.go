@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_UpdateUserEmail(t *testing.T) {
+	// Новый адрес сразу считается подтвержденным: владение им проверено ссылкой из письма
+	const query = `UPDATE users SET email = \$1, email_verified_at = \$2, updated_at = \$3 WHERE id = \$4`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "email changed",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("new@example.com", pgxmock.AnyArg(), pgxmock.AnyArg(), "u1").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+		},
+		{
+			name: "email taken by another user",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("new@example.com", pgxmock.AnyArg(), pgxmock.AnyArg(), "u1").
+					WillReturnError(&pgconn.PgError{Code: pgUniqueViolation})
+			},
+			wantErr: domain.ErrUserExists,
+		},
+		{
+			name: "user not found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("new@example.com", pgxmock.AnyArg(), pgxmock.AnyArg(), "u1").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("new@example.com", pgxmock.AnyArg(), pgxmock.AnyArg(), "u1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.UpdateUserEmail(context.Background(), "u1", "new@example.com")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// RecordFailedLogin увеличивает счетчик неудачных попыток входа и возвращает его новое значение.
+// Если предыдущая неудача была раньше resetAfter назад, отсчет начинается заново
+func (s *Service) RecordFailedLogin(ctx context.Context, userID string, resetAfter time.Duration) (int, error) {
+	now := time.Now()
+
+	query := `UPDATE users SET
+		failed_login_attempts = CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < $2 THEN 1 ELSE failed_login_attempts + 1 END,
+		last_failed_login_at = $3
+		WHERE id = $1
+		RETURNING failed_login_attempts`
+
+	var attempts int
+	err := s.conn(ctx).QueryRow(ctx, query, userID, now.Add(-resetAfter).Format("2006-01-02 15:04:05"), now.Format("2006-01-02 15:04:05")).Scan(&attempts)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logger.Debug("User not found for failed login", zap.String("user_id", userID))
+			return 0, domain.ErrUserNotFound
+		}
+		s.logger.Error("Failed to record failed login", zap.Error(err), zap.String("user_id", userID))
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// LockUser запрещает вход пользователя до указанного времени
+func (s *Service) LockUser(ctx context.Context, userID string, until time.Time) error {
+	query, args, err := squirrel.Update("users").
+		Set("locked_until", until.Format("2006-01-02 15:04:05")).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build lock user query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to lock user", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for lock", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	s.logger.Info("User login locked", zap.String("user_id", userID), zap.Time("locked_until", until))
+	return nil
+}
+
+// ResetFailedLogins сбрасывает счетчик неудачных попыток входа и снимает блокировку входа
+func (s *Service) ResetFailedLogins(ctx context.Context, userID string) error {
+	query, args, err := squirrel.Update("users").
+		Set("failed_login_attempts", 0).
+		Set("last_failed_login_at", nil).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build reset failed logins query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to reset failed logins", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for failed logins reset", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RecordFailedLogin(t *testing.T) {
+	// Счетчик начинается заново, если прошлая неудача старше окна ($2), иначе увеличивается
+	const query = `UPDATE users SET\s+` +
+		`failed_login_attempts = CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < \$2 THEN 1 ELSE failed_login_attempts \+ 1 END,\s+` +
+		`last_failed_login_at = \$3\s+` +
+		`WHERE id = \$1\s+` +
+		`RETURNING failed_login_attempts`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		want    int
+		wantErr error
+	}{
+		{
+			name: "attempt counted",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1", pgxmock.AnyArg(), pgxmock.AnyArg()).
+					WillReturnRows(pgxmock.NewRows([]string{"failed_login_attempts"}).AddRow(3))
+			},
+			want: 3,
+		},
+		{
+			name: "user not found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1", pgxmock.AnyArg(), pgxmock.AnyArg()).WillReturnError(pgx.ErrNoRows)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectQuery(query).WithArgs("u1", pgxmock.AnyArg(), pgxmock.AnyArg()).WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			got, err := s.RecordFailedLogin(context.Background(), "u1", time.Hour)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_LockUser(t *testing.T) {
+	const query = `UPDATE users SET locked_until = \$1 WHERE id = \$2`
+	until := time.Date(2025, time.January, 15, 10, 20, 30, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantErr      error
+	}{
+		{name: "locked", rowsAffected: 1},
+		{name: "user not found", rowsAffected: 0, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			db.ExpectExec(query).WithArgs("2025-01-15 10:20:30", "u1").WillReturnResult(pgxmock.NewResult("UPDATE", tt.rowsAffected))
+
+			err := s.LockUser(context.Background(), "u1", until)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_ResetFailedLogins(t *testing.T) {
+	const query = `UPDATE users SET failed_login_attempts = \$1, last_failed_login_at = \$2, locked_until = \$3 WHERE id = \$4`
+
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantErr      error
+	}{
+		{name: "reset", rowsAffected: 1},
+		{name: "user not found", rowsAffected: 0, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			db.ExpectExec(query).WithArgs(0, nil, nil, "u1").WillReturnResult(pgxmock.NewResult("UPDATE", tt.rowsAffected))
+
+			err := s.ResetFailedLogins(context.Background(), "u1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
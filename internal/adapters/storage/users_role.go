@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// UpdateUserRole меняет роль пользователя, не затрагивая остальные поля
+func (s *Service) UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error {
+	query, args, err := squirrel.Update("users").
+		Set("role", string(role)).
+		Set("updated_at", time.Now().Format("2006-01-02 15:04:05")).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		s.logger.Error("Failed to build update user role query", zap.Error(err))
+		return err
+	}
+
+	tag, err := s.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("Failed to update user role", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		s.logger.Debug("User not found for role update", zap.String("user_id", userID))
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_UpdateUserRole(t *testing.T) {
+	const query = `UPDATE users SET role = \$1, updated_at = \$2 WHERE id = \$3`
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name: "role changed",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("admin", pgxmock.AnyArg(), "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+		},
+		{
+			name: "user not found",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("admin", pgxmock.AnyArg(), "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(query).WithArgs("admin", pgxmock.AnyArg(), "u1").WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.UpdateUserRole(context.Background(), "u1", domain.UserRoleAdmin)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
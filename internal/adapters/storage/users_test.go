@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_UpdateUser(t *testing.T) {
+	const (
+		nameQuery   = `UPDATE users SET updated_at = \$1, name = \$2 WHERE id = \$3$`
+		emailQuery  = `UPDATE users SET updated_at = \$1, email = \$2, email_verified_at = \$3 WHERE id = \$4$`
+		statusQuery = `UPDATE users SET updated_at = \$1, status = \$2, ban_reason = \$3, banned_until = \$4, banned_by = \$5, banned_at = \$6 ` +
+			`WHERE id = \$7 AND status = \$8 AND \(status <> \$9 OR banned_until <= \$10\)`
+		existsQuery = `SELECT EXISTS \(SELECT 1 FROM users WHERE id = \$1\)`
+	)
+	name := "Maria"
+	email := "maria@example.com"
+	inactive := domain.UserStatusInactive
+	deactivate := domain.UserUpdate{Status: &inactive, FromStatus: domain.UserStatusActive}
+	statusArgs := []interface{}{pgxmock.AnyArg(), "inactive", "", nil, "", nil, "u1", "active", "banned", pgxmock.AnyArg()}
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		update  domain.UserUpdate
+		setup   func(db pgxmock.PgxPoolIface)
+		wantErr error
+	}{
+		{
+			name:   "only changed columns written",
+			update: domain.UserUpdate{Name: &name},
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(nameQuery).WithArgs(pgxmock.AnyArg(), "Maria", "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+		},
+		{
+			name:   "email taken",
+			update: domain.UserUpdate{Email: &email},
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(emailQuery).WithArgs(pgxmock.AnyArg(), email, nil, "u1").WillReturnError(&pgconn.PgError{Code: pgUniqueViolation})
+			},
+			wantErr: domain.ErrUserExists,
+		},
+		{
+			name:   "user not found",
+			update: domain.UserUpdate{Name: &name},
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(nameQuery).WithArgs(pgxmock.AnyArg(), "Maria", "u1").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:   "status changed conditionally",
+			update: deactivate,
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(statusQuery).WithArgs(statusArgs...).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+		},
+		{
+			name:   "status changed concurrently",
+			update: deactivate,
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(statusQuery).WithArgs(statusArgs...).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				db.ExpectQuery(existsQuery).WithArgs("u1").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			wantErr: domain.ErrUserStatusChanged,
+		},
+		{
+			name:   "deleted before status change",
+			update: deactivate,
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(statusQuery).WithArgs(statusArgs...).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				db.ExpectQuery(existsQuery).WithArgs("u1").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:   "database error",
+			update: deactivate,
+			setup: func(db pgxmock.PgxPoolIface) {
+				db.ExpectExec(statusQuery).WithArgs(statusArgs...).WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newMockService(t)
+			tt.setup(db)
+
+			err := s.UpdateUser(context.Background(), "u1", tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
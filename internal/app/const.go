@@ -16,13 +16,22 @@ const (
 
 // Константы для JWT
 const (
-	JWTCookieName      = "access_token"
-	RefreshTokenPrefix = "refresh_token:"
+	JWTCookieName          = "access_token"
+	RefreshTokensPrefix    = "refresh_tokens:"
+	AccessDenylistPrefix   = "access_denylist:"
+	RevokedSessionPrefix   = "revoked_session:"
+	BannedUserPrefix       = "banned_user:"
+	TokensValidAfterPrefix = "tokens_valid_after:"
+	RateLimitPrefix        = "rate_limit:"
+)
+
+// Константы для прав доступа
+const (
+	PermissionsCachePrefix = "permissions:"
 )
 
 // Константы для валидации
 const (
-	MinPasswordLength = 6
 	MaxPasswordLength = 128
 	MaxNameLength     = 100
 	MaxEmailLength    = 255
@@ -30,6 +39,8 @@ const (
 
 // Константы для токенов
 const (
-	PasswordResetTokenLength = 32
-	PasswordResetExpiration  = 24 // часы
+	PasswordResetTokenLength     = 32
+	PasswordResetExpiration      = 24 // часы
+	EmailVerificationTokenLength = 32
+	EmailChangeTokenLength       = 32
 )
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptString(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       []byte
+		plaintext string
+		wantErr   bool
+	}{
+		{name: "aes-128 key", key: bytes.Repeat([]byte{1}, 16), plaintext: "JBSWY3DPEHPK3PXP"},
+		{name: "aes-192 key", key: bytes.Repeat([]byte{2}, 24), plaintext: "JBSWY3DPEHPK3PXP"},
+		{name: "aes-256 key", key: bytes.Repeat([]byte{3}, 32), plaintext: "JBSWY3DPEHPK3PXP"},
+		{name: "empty plaintext", key: bytes.Repeat([]byte{4}, 32), plaintext: ""},
+		{name: "unicode plaintext", key: bytes.Repeat([]byte{5}, 32), plaintext: "секрет ✓"},
+		{name: "invalid key length", key: []byte("short"), plaintext: "secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := EncryptString(tt.key, tt.plaintext)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.plaintext != "" {
+				assert.NotContains(t, ciphertext, tt.plaintext)
+			}
+
+			// Случайный nonce делает шифротексты одного текста разными
+			again, err := EncryptString(tt.key, tt.plaintext)
+			require.NoError(t, err)
+			assert.NotEqual(t, ciphertext, again)
+
+			decrypted, err := DecryptString(tt.key, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, decrypted)
+		})
+	}
+}
+
+func TestDecryptString(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	ciphertext, err := EncryptString(key, "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	tests := []struct {
+		name       string
+		key        []byte
+		ciphertext string
+		want       string
+		wantErr    bool
+	}{
+		{name: "valid ciphertext", key: key, ciphertext: ciphertext, want: "JBSWY3DPEHPK3PXP"},
+		{name: "tampered ciphertext", key: key, ciphertext: base64.StdEncoding.EncodeToString(tampered), wantErr: true},
+		{name: "truncated nonce", key: key, ciphertext: base64.StdEncoding.EncodeToString(sealed[:8]), wantErr: true},
+		{name: "wrong key", key: bytes.Repeat([]byte{8}, 32), ciphertext: ciphertext, wantErr: true},
+		{name: "invalid key length", key: []byte("short"), ciphertext: ciphertext, wantErr: true},
+		{name: "not base64", key: key, ciphertext: "%%%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptString(tt.key, tt.ciphertext)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -15,6 +15,25 @@ var (
 	ErrTokenExpired         = errors.New("token expired")
 	ErrPasswordResetExpired = errors.New("password reset token expired")
 	ErrPasswordResetUsed    = errors.New("password reset token already used")
+	ErrVerificationExpired  = errors.New("email verification token expired")
+	ErrVerificationUsed     = errors.New("email verification token already used")
+	ErrEmailNotVerified     = errors.New("email is not verified")
+	ErrEmailChangeExpired   = errors.New("email change token expired")
+	ErrEmailChangeUsed      = errors.New("email change token already used")
+	ErrEmailInUse           = errors.New("email is already in use")
+	ErrPasswordReused       = errors.New("password was used recently")
+	ErrWeakPassword         = errors.New("password does not meet policy requirements")
 	ErrMissingHeaders       = errors.New("missing required headers")
 	ErrInvalidAppType       = errors.New("invalid app type")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrPermissionNotFound   = errors.New("permission not found")
+	ErrUserBanned           = errors.New("user is banned")
+	ErrUserInactive         = errors.New("user is inactive")
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrAccountLocked        = errors.New("account is temporarily locked")
+	ErrInvalidMFACode       = errors.New("invalid two-factor code")
+	ErrMFAAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrJobNotFound          = errors.New("maintenance job not found")
+	ErrJobLocked            = errors.New("maintenance job is running on another instance")
 )
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -51,11 +52,6 @@ func ValidateEmail(email string) bool {
 	return strings.Contains(email, "@") && strings.Contains(email, ".")
 }
 
-// ValidatePassword проверяет корректность пароля
-func ValidatePassword(password string) bool {
-	return len(password) >= MinPasswordLength && len(password) <= MaxPasswordLength
-}
-
 // ValidateName проверяет корректность имени
 func ValidateName(name string) bool {
 	name = strings.TrimSpace(name)
@@ -72,6 +68,40 @@ func ValidateAppType(appType string) bool {
 	}
 }
 
+// IsValidRole проверяет, что роль входит в список известных ролей
+func IsValidRole(role domain.UserRole) bool {
+	switch role {
+	case domain.UserRoleAdmin, domain.UserRoleUser, domain.UserRoleGuest:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidStatus проверяет, что статус входит в список известных статусов
+func IsValidStatus(status domain.UserStatus) bool {
+	switch status {
+	case domain.UserStatusActive, domain.UserStatusInactive, domain.UserStatusBanned:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanManageUser проверяет, что пользователь с ролью actorRole может изменять, удалять и блокировать target.
+// Аккаунтами администраторов управляет только администратор, даже если права users:* выданы другой роли
+func CanManageUser(actorRole domain.UserRole, target *domain.User) bool {
+	return target.Role != domain.UserRoleAdmin || actorRole == domain.UserRoleAdmin
+}
+
+// CurrentUserStatus возвращает статус с учетом срока блокировки: истекшая блокировка считается снятой
+func CurrentUserStatus(user *domain.User, now time.Time) domain.UserStatus {
+	if user.Status == domain.UserStatusBanned && user.BannedUntil != nil && !now.Before(*user.BannedUntil) {
+		return domain.UserStatusActive
+	}
+	return user.Status
+}
+
 // IsExpired проверяет, истекло ли время
 func IsExpired(expiresAt time.Time) bool {
 	return time.Now().After(expiresAt)
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Правила политики паролей, по которым клиент показывает ошибки у поля ввода
+const (
+	PasswordRuleMinLength    = "min_length"
+	PasswordRuleMaxLength    = "max_length"
+	PasswordRuleUppercase    = "uppercase"
+	PasswordRuleLowercase    = "lowercase"
+	PasswordRuleDigit        = "digit"
+	PasswordRuleSymbol       = "symbol"
+	PasswordRuleMaxRepeated  = "max_repeated"
+	PasswordRulePersonalInfo = "personal_info"
+	PasswordRuleCommon       = "common"
+)
+
+// Минимальная длина части email или имени, которую запрещено включать в пароль
+const minPersonalPartLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+// commonPasswords распространенные пароли в нижнем регистре
+var commonPasswords = parseCommonPasswords(commonPasswordsFile)
+
+// PasswordPolicy описывает требования к новым паролям
+type PasswordPolicy struct {
+	MinLength      int  // Минимум символов
+	RequireUpper   bool // Хотя бы одна заглавная буква
+	RequireLower   bool // Хотя бы одна строчная буква
+	RequireDigit   bool // Хотя бы одна цифра
+	RequireSymbol  bool // Хотя бы один символ, кроме букв и цифр
+	MaxRepeated    int  // Максимум одинаковых символов подряд, 0 - без ограничения
+	ForbidPersonal bool // Запрет включать в пароль email и имя пользователя
+	ForbidCommon   bool // Запрет распространенных паролей из встроенного списка
+}
+
+// PasswordViolation описывает нарушенное правило политики паролей
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Limit   int    `json:"limit,omitempty"` // Значение ограничения для правил длины и повторов
+}
+
+// PasswordPolicyError перечисляет все нарушенные правила политики паролей
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error возвращает текст ErrWeakPassword
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error()
+}
+
+// Is позволяет сравнивать ошибку с ErrWeakPassword через errors.Is
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// Validate проверяет пароль по всем правилам и возвращает *PasswordPolicyError со списком нарушений.
+// personal - email и имя пользователя, которые нельзя включать в пароль
+func (p *PasswordPolicy) Validate(password string, personal ...string) error {
+	var violations []PasswordViolation
+	violate := func(rule, message string, limit int) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message, Limit: limit})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violate(PasswordRuleMinLength, fmt.Sprintf("password must be at least %d characters long", p.MinLength), p.MinLength)
+	}
+	// bcrypt учитывает ограниченное число байт, поэтому максимум задается в байтах
+	if len(password) > MaxPasswordLength {
+		violate(PasswordRuleMaxLength, fmt.Sprintf("password must be at most %d bytes long", MaxPasswordLength), MaxPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violate(PasswordRuleUppercase, "password must contain an uppercase letter", 0)
+	}
+	if p.RequireLower && !hasLower {
+		violate(PasswordRuleLowercase, "password must contain a lowercase letter", 0)
+	}
+	if p.RequireDigit && !hasDigit {
+		violate(PasswordRuleDigit, "password must contain a digit", 0)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violate(PasswordRuleSymbol, "password must contain a special character", 0)
+	}
+
+	if p.MaxRepeated > 0 && maxRepeatedRun(password) > p.MaxRepeated {
+		violate(PasswordRuleMaxRepeated, fmt.Sprintf("password must not repeat a character more than %d times in a row", p.MaxRepeated), p.MaxRepeated)
+	}
+
+	if p.ForbidPersonal && containsPersonalInfo(password, personal) {
+		violate(PasswordRulePersonalInfo, "password must not contain your email or name", 0)
+	}
+
+	if p.ForbidCommon && IsCommonPassword(password) {
+		violate(PasswordRuleCommon, "password is too common", 0)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// IsCommonPassword проверяет пароль по встроенному списку распространенных паролей
+func IsCommonPassword(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
+
+// maxRepeatedRun возвращает длину самой длинной серии одинаковых символов подряд
+func maxRepeatedRun(password string) int {
+	var longest, run int
+	var prev rune
+	for i, r := range []rune(password) {
+		if i > 0 && r == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// containsPersonalInfo проверяет, содержит ли пароль локальную часть email или слово из имени
+func containsPersonalInfo(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+		// Имя проверяем по словам: "Иван Петров" запрещает и "иван", и "петров"
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(part) >= minPersonalPartLength && strings.Contains(lower, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseCommonPasswords разбирает встроенный список: по паролю на строку, строки с # - комментарии
+func parseCommonPasswords(content string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
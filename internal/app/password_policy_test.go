@@ -0,0 +1,224 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	strict := PasswordPolicy{
+		MinLength:      8,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RequireSymbol:  true,
+		MaxRepeated:    3,
+		ForbidPersonal: true,
+		ForbidCommon:   true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		personal []string
+		want     []PasswordViolation
+	}{
+		{
+			name:     "valid password",
+			policy:   strict,
+			password: "Tr0ub4dor&3",
+			personal: []string{"ivan@example.com", "Ivan Petrov"},
+		},
+		{
+			name:     "too short",
+			policy:   PasswordPolicy{MinLength: 8},
+			password: "Ab1!",
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMinLength, Message: "password must be at least 8 characters long", Limit: 8},
+			},
+		},
+		{
+			name:     "too long in bytes",
+			policy:   PasswordPolicy{},
+			password: strings.Repeat("a", MaxPasswordLength+1),
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMaxLength, Message: "password must be at most 128 bytes long", Limit: MaxPasswordLength},
+			},
+		},
+		{
+			name:     "missing uppercase",
+			policy:   PasswordPolicy{RequireUpper: true},
+			password: "lower1!",
+			want:     []PasswordViolation{{Rule: PasswordRuleUppercase, Message: "password must contain an uppercase letter"}},
+		},
+		{
+			name:     "missing lowercase",
+			policy:   PasswordPolicy{RequireLower: true},
+			password: "UPPER1!",
+			want:     []PasswordViolation{{Rule: PasswordRuleLowercase, Message: "password must contain a lowercase letter"}},
+		},
+		{
+			name:     "missing digit",
+			policy:   PasswordPolicy{RequireDigit: true},
+			password: "NoDigits!",
+			want:     []PasswordViolation{{Rule: PasswordRuleDigit, Message: "password must contain a digit"}},
+		},
+		{
+			name:     "missing symbol",
+			policy:   PasswordPolicy{RequireSymbol: true},
+			password: "NoSymbol1 with spaces",
+			want:     []PasswordViolation{{Rule: PasswordRuleSymbol, Message: "password must contain a special character"}},
+		},
+		{
+			name:     "too many repeated characters",
+			policy:   PasswordPolicy{MaxRepeated: 3},
+			password: "abbbbc",
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMaxRepeated, Message: "password must not repeat a character more than 3 times in a row", Limit: 3},
+			},
+		},
+		{
+			name:     "repeated characters at the limit",
+			policy:   PasswordPolicy{MaxRepeated: 3},
+			password: "abbbc",
+		},
+		{
+			name:     "contains email local part",
+			policy:   PasswordPolicy{ForbidPersonal: true},
+			password: "my-IVAN.P-pass",
+			personal: []string{"ivan.p@example.com"},
+			want:     []PasswordViolation{{Rule: PasswordRulePersonalInfo, Message: "password must not contain your email or name"}},
+		},
+		{
+			name:     "common password in any case",
+			policy:   PasswordPolicy{ForbidCommon: true},
+			password: "PassWord",
+			want:     []PasswordViolation{{Rule: PasswordRuleCommon, Message: "password is too common"}},
+		},
+		{
+			name:     "several violations in rule order",
+			policy:   strict,
+			password: "qwerty",
+			personal: []string{"qwerty@example.com"},
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMinLength, Message: "password must be at least 8 characters long", Limit: 8},
+				{Rule: PasswordRuleUppercase, Message: "password must contain an uppercase letter"},
+				{Rule: PasswordRuleDigit, Message: "password must contain a digit"},
+				{Rule: PasswordRuleSymbol, Message: "password must contain a special character"},
+				{Rule: PasswordRulePersonalInfo, Message: "password must not contain your email or name"},
+				{Rule: PasswordRuleCommon, Message: "password is too common"},
+			},
+		},
+		{
+			// 8 рун, но 16 байт: длина считается в символах
+			name:     "multibyte password meets length in runes",
+			policy:   PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true},
+			password: "Пароль№1",
+		},
+		{
+			// 4 руны, но 8 байт: байтовая длина не должна засчитываться
+			name:     "multibyte password too short in runes",
+			policy:   PasswordPolicy{MinLength: 5},
+			password: "ёжик",
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMinLength, Message: "password must be at least 5 characters long", Limit: 5},
+			},
+		},
+		{
+			name:     "multibyte repeated characters",
+			policy:   PasswordPolicy{MaxRepeated: 2},
+			password: "жжжук",
+			want: []PasswordViolation{
+				{Rule: PasswordRuleMaxRepeated, Message: "password must not repeat a character more than 2 times in a row", Limit: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, tt.personal...)
+			if len(tt.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			assert.True(t, errors.Is(err, ErrWeakPassword))
+			assert.Equal(t, tt.want, policyErr.Violations)
+		})
+	}
+}
+
+func TestIsCommonPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "listed password", password: "123456", want: true},
+		{name: "listed password in other case", password: "QWERTY", want: true},
+		{name: "unlisted password", password: "Tr0ub4dor&3", want: false},
+		{name: "comment line is not a password", password: "#", want: false},
+		{name: "empty", password: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsCommonPassword(tt.password))
+		})
+	}
+}
+
+func TestMaxRepeatedRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{name: "empty", password: "", want: 0},
+		{name: "single character", password: "a", want: 1},
+		{name: "no repeats", password: "abc", want: 1},
+		{name: "run in the middle", password: "abbbc", want: 3},
+		{name: "run at the end", password: "abcccc", want: 4},
+		{name: "longest of several runs", password: "aabbbaa", want: 3},
+		{name: "case sensitive", password: "aAaA", want: 1},
+		{name: "multibyte runes", password: "ёёёж", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, maxRepeatedRun(tt.password))
+		})
+	}
+}
+
+func TestContainsPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		want     bool
+	}{
+		{name: "no personal data", password: "anything", personal: nil, want: false},
+		{name: "email local part", password: "xxjohnxx", personal: []string{"john@example.com"}, want: true},
+		{name: "email domain is allowed", password: "example123", personal: []string{"john@example.com"}, want: false},
+		{name: "email local part split into words", password: "smith2024", personal: []string{"john.smith@example.com"}, want: true},
+		{name: "name word in any case", password: "PETROV-1990", personal: []string{"Ivan Petrov"}, want: true},
+		{name: "cyrillic name", password: "мойиван123", personal: []string{"Иван Петров"}, want: true},
+		{name: "short parts are ignored", password: "xyjo42", personal: []string{"Jo Li", "jo@example.com"}, want: false},
+		{name: "three-rune part counts", password: "мояяна", personal: []string{"Яна"}, want: true},
+		{name: "blank values are ignored", password: "password", personal: []string{"", "   "}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, containsPersonalInfo(tt.password, tt.personal))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Параметры TOTP (RFC 6238), поддерживаемые Google Authenticator и аналогами
+const (
+	TOTPDigits     = 6
+	TOTPPeriod     = 30 * time.Second
+	TOTPSecretSize = 20 // байт, рекомендуемый размер для HMAC-SHA1
+	TOTPSkew       = 1  // Допустимое расхождение часов в шагах в каждую сторону
+
+	RecoveryCodeSize = 10 // байт случайности в коде восстановления
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret генерирует случайный секрет TOTP в base32 без выравнивания
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, TOTPSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPStep возвращает номер временного шага TOTP для момента t
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode вычисляет код TOTP для секрета и временного шага
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Динамическое усечение (RFC 4226, раздел 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulo), nil
+}
+
+// ValidateTOTP проверяет код с учетом расхождения часов и возвращает шаг, которому он соответствует
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(now)
+	for step := current - TOTPSkew; step <= current+TOTPSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPURI формирует otpauth:// URI для добавления аккаунта в приложение-аутентификатор
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// GenerateRecoveryCode генерирует одноразовый код восстановления вида xxxx-xxxx-xxxx-xxxx.
+// 80 бит случайности делают перебор хешей из утекшей базы неосуществимым
+func GenerateRecoveryCode() (string, error) {
+	bytes := make([]byte, RecoveryCodeSize)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(bytes))
+
+	groups := make([]string, 0, len(code)/4)
+	for i := 0; i < len(code); i += 4 {
+		groups = append(groups, code[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// NormalizeRecoveryCode приводит введенный код восстановления к виду, в котором хранится его хеш
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret ASCII-ключ "12345678901234567890" из приложения B RFC 6238 в base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		unix    int64
+		want    string
+		wantErr bool
+	}{
+		// Векторы SHA1 из RFC 6238, приложение B, усеченные до 6 цифр
+		{name: "rfc 6238 t=59", secret: rfc6238Secret, unix: 59, want: "287082"},
+		{name: "rfc 6238 t=1111111109", secret: rfc6238Secret, unix: 1111111109, want: "081804"},
+		{name: "rfc 6238 t=1111111111", secret: rfc6238Secret, unix: 1111111111, want: "050471"},
+		{name: "rfc 6238 t=1234567890", secret: rfc6238Secret, unix: 1234567890, want: "005924"},
+		{name: "rfc 6238 t=2000000000", secret: rfc6238Secret, unix: 2000000000, want: "279037"},
+		{name: "rfc 6238 t=20000000000", secret: rfc6238Secret, unix: 20000000000, want: "353130"},
+		{name: "lowercase secret", secret: strings.ToLower(rfc6238Secret), unix: 59, want: "287082"},
+		{name: "invalid base32 secret", secret: "not-base32!", unix: 59, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TOTPCode(tt.secret, TOTPStep(time.Unix(tt.unix, 0)))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	codeAt := func(t *testing.T, step int64) string {
+		t.Helper()
+		code, err := TOTPCode(rfc6238Secret, step)
+		require.NoError(t, err)
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     func(t *testing.T) string
+		wantStep int64
+		wantOK   bool
+	}{
+		{
+			name:     "current step",
+			secret:   rfc6238Secret,
+			code:     func(t *testing.T) string { return codeAt(t, current) },
+			wantStep: current,
+			wantOK:   true,
+		},
+		{
+			name:     "previous step within skew",
+			secret:   rfc6238Secret,
+			code:     func(t *testing.T) string { return codeAt(t, current-TOTPSkew) },
+			wantStep: current - TOTPSkew,
+			wantOK:   true,
+		},
+		{
+			name:     "next step within skew",
+			secret:   rfc6238Secret,
+			code:     func(t *testing.T) string { return codeAt(t, current+TOTPSkew) },
+			wantStep: current + TOTPSkew,
+			wantOK:   true,
+		},
+		{
+			name:   "step before skew window",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return codeAt(t, current-TOTPSkew-1) },
+		},
+		{
+			name:   "step after skew window",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return codeAt(t, current+TOTPSkew+1) },
+		},
+		{
+			name:     "surrounding whitespace is ignored",
+			secret:   rfc6238Secret,
+			code:     func(t *testing.T) string { return " " + codeAt(t, current) + "\n" },
+			wantStep: current,
+			wantOK:   true,
+		},
+		{
+			name:   "too short",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return codeAt(t, current)[:TOTPDigits-1] },
+		},
+		{
+			name:   "too long",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return codeAt(t, current) + "0" },
+		},
+		{
+			name:   "empty",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return "" },
+		},
+		{
+			name:   "non-digit input",
+			secret: rfc6238Secret,
+			code:   func(t *testing.T) string { return "05O471" },
+		},
+		{
+			name:   "invalid secret",
+			secret: "not-base32!",
+			code:   func(t *testing.T) string { return codeAt(t, current) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code(t), now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantStep, step)
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`)
+
+	seen := make(map[string]struct{})
+	for i := 0; i < 100; i++ {
+		code, err := GenerateRecoveryCode()
+		require.NoError(t, err)
+		assert.Regexp(t, format, code)
+
+		normalized := NormalizeRecoveryCode(code)
+		assert.Len(t, normalized, 16, "80 bits of base32 without separators")
+
+		_, duplicate := seen[normalized]
+		assert.False(t, duplicate, "duplicate recovery code %q", code)
+		seen[normalized] = struct{}{}
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "already normalized", code: "abcdefghijklmnop", want: "abcdefghijklmnop"},
+		{name: "dashes removed", code: "abcd-efgh-ijkl-mnop", want: "abcdefghijklmnop"},
+		{name: "uppercase and spaces", code: "  ABCD EFGH-ijkl mnop ", want: "abcdefghijklmnop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeRecoveryCode(tt.code))
+		})
+	}
+}
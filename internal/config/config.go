@@ -2,29 +2,37 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/TeDenis/bukhindor-backend/internal/app"
 	"go.uber.org/zap"
 )
 
 // Config содержит все настройки приложения
 type Config struct {
+	// Окружение
+	AppEnv string `env:"APP_ENV" envDefault:"development"` // development, staging, production
+
 	// Сервер
 	ServerPort string `env:"SERVER_PORT" envDefault:"8080"`
 	ServerHost string `env:"SERVER_HOST" envDefault:"localhost"`
 
+	// Обратный прокси: IP клиента для ограничения частоты запросов берется из PROXY_HEADER,
+	// только если запрос пришел с адреса из TRUSTED_PROXIES
+	ProxyHeader    string   `env:"PROXY_HEADER" envDefault:""`                     // X-Real-IP, X-Forwarded-For и т.п.; пусто - IP соединения
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envDefault:"" envSeparator:","` // IP и подсети (CIDR) прокси
+
 	// База данных PostgreSQL
 	PostgresHost     string `env:"POSTGRES_HOST" envDefault:"localhost"`
 	PostgresPort     string `env:"POSTGRES_PORT" envDefault:"5432"`
 	PostgresUser     string `env:"POSTGRES_USER" envDefault:"bukhindor"`
-	PostgresPassword string `env:"POSTGRES_PASSWORD" envDefault:"password"`
+	PostgresPassword string `env:"POSTGRES_PASSWORD" envDefault:"" secret:"true"`
 	PostgresDB       string `env:"POSTGRES_DB" envDefault:"bukhindor"`
 	PostgresSSLMode  string `env:"POSTGRES_SSLMODE" envDefault:"disable"`
 
 	// Redis
 	RedisURL      string `env:"REDIS_URL" envDefault:"redis://localhost:6379"`
-	RedisPassword string `env:"REDIS_PASSWORD" envDefault:""`
+	RedisPassword string `env:"REDIS_PASSWORD" envDefault:"" secret:"true"`
 	RedisDB       int    `env:"REDIS_DB" envDefault:"0"`
 
 	// Логирование
@@ -32,45 +40,95 @@ type Config struct {
 	LogFormat string `env:"LOG_FORMAT" envDefault:"console"` // console, json
 
 	// JWT
-	JWTSecret              string        `env:"JWT_SECRET" envDefault:"your-secret-key"`
+	JWTSecret              string        `env:"JWT_SECRET" envDefault:"your-secret-key" secret:"true"`
 	JWTExpiration          time.Duration `env:"JWT_EXPIRATION" envDefault:"15m"`
 	RefreshTokenExpiration time.Duration `env:"REFRESH_TOKEN_EXPIRATION" envDefault:"7d"`
+	JWTRevocationCacheTTL  time.Duration `env:"JWT_REVOCATION_CACHE_TTL" envDefault:"5s"` // Кеш проверки отзыва в памяти процесса, 0 - отключен
+	JWTKeysDir             string        `env:"JWT_KEYS_DIR" envDefault:""`               // PEM ключи RS256/ES256/EdDSA; пусто - HS256 с JWT_SECRET
+	JWTSigningKeyID        string        `env:"JWT_SIGNING_KEY_ID" envDefault:""`         // kid ключа подписи; пусто - самый новый ключ (в production обязателен при JWT_KEYS_DIR)
 
 	// CORS
-	CORSAllowedOrigins string `env:"CORS_ALLOWED_ORIGINS" envDefault:"*"`
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" envDefault:"*" envSeparator:","`
+
+	// Ограничение частоты запросов к auth ручкам
+	RateLimitLogin          RateLimit `env:"RATE_LIMIT_LOGIN" envDefault:"10/1m"`
+	RateLimitRegister       RateLimit `env:"RATE_LIMIT_REGISTER" envDefault:"5/1h"`
+	RateLimitResetPassword  RateLimit `env:"RATE_LIMIT_RESET_PASSWORD" envDefault:"5/1h"`
+	RateLimitRefresh        RateLimit `env:"RATE_LIMIT_REFRESH" envDefault:"60/1m"`
+	RateLimitLogin2FA       RateLimit `env:"RATE_LIMIT_LOGIN_2FA" envDefault:"10/1m"`
+	RateLimitVerifyEmail    RateLimit `env:"RATE_LIMIT_VERIFY_EMAIL" envDefault:"3/1h"`    // Повторная отправка письма подтверждения
+	RateLimitChangeEmail    RateLimit `env:"RATE_LIMIT_CHANGE_EMAIL" envDefault:"5/1h"`    // Запрос смены email: проверка пароля и отправка писем
+	RateLimitChangePassword RateLimit `env:"RATE_LIMIT_CHANGE_PASSWORD" envDefault:"5/1h"` // Смена пароля: защищает текущий пароль от перебора по украденному токену
+	RateLimitTwoFactor      RateLimit `env:"RATE_LIMIT_2FA" envDefault:"5/1h"`             // Подтверждение, перевыпуск кодов восстановления и отключение 2FA: защищает пароль и коды от перебора
+	RateLimitConfirmToken   RateLimit `env:"RATE_LIMIT_CONFIRM_TOKEN" envDefault:"20/1h"`  // Ручки, принимающие одноразовые токены из писем
+	RateLimitFailOpen       bool      `env:"RATE_LIMIT_FAIL_OPEN" envDefault:"true"`       // Пропускать запросы при недоступности Redis
+
+	// Блокировка входа после неудачных попыток
+	LoginBackoffAfter     int           `env:"LOGIN_BACKOFF_AFTER" envDefault:"3"`      // С какой неудачи подряд начинается задержка, 0 - без задержек
+	LoginBackoffBase      time.Duration `env:"LOGIN_BACKOFF_BASE" envDefault:"1s"`      // Первая задержка, далее удваивается
+	LoginLockoutThreshold int           `env:"LOGIN_LOCKOUT_THRESHOLD" envDefault:"10"` // С какой неудачи подряд вход блокируется, 0 - без блокировки
+	LoginLockoutDuration  time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"` // Срок блокировки и предел задержки
+	LoginFailureWindow    time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"1h"`    // Счетчик начинается заново после паузы в неудачах
+
+	// Подтверждение email
+	EmailVerificationRequired   bool          `env:"EMAIL_VERIFICATION_REQUIRED" envDefault:"false"` // true - вход без подтвержденного email запрещен, false - только флаг в /auth/me
+	EmailVerificationExpiration time.Duration `env:"EMAIL_VERIFICATION_EXPIRATION" envDefault:"24h"`
+	EmailChangeExpiration       time.Duration `env:"EMAIL_CHANGE_EXPIRATION" envDefault:"24h"` // Срок действия ссылок подтверждения и отмены смены email
+
+	// Двухфакторная аутентификация (TOTP)
+	MFAIssuer          string        `env:"MFA_ISSUER" envDefault:"Bukhindor"`              // Название сервиса в приложении-аутентификаторе
+	MFAEncryptionKey   EncryptionKey `env:"MFA_ENCRYPTION_KEY" envDefault:"" secret:"true"` // Ключ шифрования секретов TOTP; пусто - производный от JWT_SECRET (только вне production)
+	MFAPendingTokenTTL time.Duration `env:"MFA_PENDING_TOKEN_TTL" envDefault:"5m"`          // Время на ввод кода после проверки пароля
+	MFARecoveryCodes   int           `env:"MFA_RECOVERY_CODES" envDefault:"10"`             // Количество выдаваемых кодов восстановления
 
 	// Пароли
-	MinPasswordLength int `env:"MIN_PASSWORD_LENGTH" envDefault:"6"`
+	MinPasswordLength      int  `env:"MIN_PASSWORD_LENGTH" envDefault:"6"`
+	PasswordRequireUpper   bool `env:"PASSWORD_REQUIRE_UPPER" envDefault:"false"`
+	PasswordRequireLower   bool `env:"PASSWORD_REQUIRE_LOWER" envDefault:"false"`
+	PasswordRequireDigit   bool `env:"PASSWORD_REQUIRE_DIGIT" envDefault:"false"`
+	PasswordRequireSymbol  bool `env:"PASSWORD_REQUIRE_SYMBOL" envDefault:"false"`
+	PasswordMaxRepeated    int  `env:"PASSWORD_MAX_REPEATED" envDefault:"3"`       // Максимум одинаковых символов подряд, 0 - без ограничения
+	PasswordForbidPersonal bool `env:"PASSWORD_FORBID_PERSONAL" envDefault:"true"` // Запрет включать в пароль email и имя
+	PasswordForbidCommon   bool `env:"PASSWORD_FORBID_COMMON" envDefault:"true"`   // Запрет паролей из встроенного списка распространенных
+	PasswordHistorySize    int  `env:"PASSWORD_HISTORY_SIZE" envDefault:"5"`       // Сколько последних паролей, включая текущий, нельзя использовать снова, 0 - без проверки
 
 	// Метрики
-	MetricsPort string `env:"METRICS_PORT" envDefault:"9090"`
+	MetricsPort            string        `env:"METRICS_PORT" envDefault:"9090"`
+	MetricsCollectInterval time.Duration `env:"METRICS_COLLECT_INTERVAL" envDefault:"15s"` // Опрос пулов соединений и сессий, 0 - отключен
+
+	// Права доступа
+	PermissionsCacheTTL time.Duration `env:"PERMISSIONS_CACHE_TTL" envDefault:"5m"`
+
+	// Обслуживание БД: интервал (1h), @every, @daily и т.п. или cron-выражение в UTC (0 - задача отключена)
+	MaintenanceSessionsSchedule       Schedule      `env:"MAINTENANCE_SESSIONS_SCHEDULE" envDefault:"1h"`
+	MaintenancePasswordResetsSchedule Schedule      `env:"MAINTENANCE_PASSWORD_RESETS_SCHEDULE" envDefault:"1h"`
+	MaintenanceVerificationsSchedule  Schedule      `env:"MAINTENANCE_EMAIL_VERIFICATIONS_SCHEDULE" envDefault:"1h"`
+	MaintenanceEmailChangesSchedule   Schedule      `env:"MAINTENANCE_EMAIL_CHANGES_SCHEDULE" envDefault:"1h"`
+	MaintenanceJitter                 time.Duration `env:"MAINTENANCE_JITTER" envDefault:"1m"` // Случайная задержка, разводящая запуски реплик
+
+	// Почта
+	AppBaseURL        string        `env:"APP_BASE_URL" envDefault:"http://localhost:8080"` // База для ссылок в письмах
+	MailDriver        string        `env:"MAIL_DRIVER" envDefault:"file"`                   // smtp, file
+	MailFrom          string        `env:"MAIL_FROM" envDefault:"Bukhindor <no-reply@bukhindor.com>"`
+	MailDefaultLocale string        `env:"MAIL_DEFAULT_LOCALE" envDefault:"ru"` // ru, en
+	MailSpoolDir      string        `env:"MAIL_SPOOL_DIR" envDefault:"./var/mail"`
+	MailQueueSize     int           `env:"MAIL_QUEUE_SIZE" envDefault:"100"`
+	MailMaxRetries    int           `env:"MAIL_MAX_RETRIES" envDefault:"5"`
+	SMTPHost          string        `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort          string        `env:"SMTP_PORT" envDefault:"587"` // 465 - неявный TLS, иначе STARTTLS при поддержке сервером
+	SMTPUsername      string        `env:"SMTP_USERNAME" envDefault:""`
+	SMTPPassword      string        `env:"SMTP_PASSWORD" envDefault:"" secret:"true"`
+	SMTPTimeout       time.Duration `env:"SMTP_TIMEOUT" envDefault:"10s"`
 }
 
-// New создает новую конфигурацию из переменных окружения
-func New() *Config {
-	cfg := &Config{
-		ServerPort:             getEnv("SERVER_PORT", "7080"),
-		ServerHost:             getEnv("SERVER_HOST", "localhost"),
-		PostgresHost:           getEnv("POSTGRES_HOST", "localhost"),
-		PostgresPort:           getEnv("POSTGRES_PORT", "5433"),
-		PostgresUser:           getEnv("POSTGRES_USER", "bukhindor"),
-		PostgresPassword:       getEnv("POSTGRES_PASSWORD", ""),
-		PostgresDB:             getEnv("POSTGRES_DB", "bukhindor"),
-		PostgresSSLMode:        getEnv("POSTGRES_SSLMODE", "disable"),
-		RedisURL:               getEnv("REDIS_URL", "redis://localhost:6380"),
-		RedisPassword:          getEnv("REDIS_PASSWORD", ""),
-		RedisDB:                getEnvAsInt("REDIS_DB", 0),
-		LogLevel:               getEnv("LOG_LEVEL", "info"),
-		LogFormat:              getEnv("LOG_FORMAT", "console"),
-		JWTSecret:              getEnv("JWT_SECRET", "your-secret-key"),
-		JWTExpiration:          getEnvAsDuration("JWT_EXPIRATION", 15*time.Minute),
-		RefreshTokenExpiration: getEnvAsDuration("REFRESH_TOKEN_EXPIRATION", 7*24*time.Hour),
-		CORSAllowedOrigins:     getEnv("CORS_ALLOWED_ORIGINS", "*"),
-		MinPasswordLength:      getEnvAsInt("MIN_PASSWORD_LENGTH", 6),
-		MetricsPort:            getEnv("METRICS_PORT", "9090"),
+// New создает конфигурацию из переменных окружения по тегам env и envDefault.
+// Некорректные значения возвращаются ошибкой, а не заменяются значениями по умолчанию
+func New() (*Config, error) {
+	cfg := &Config{}
+	if err := load(cfg); err != nil {
+		return nil, err
 	}
-
-	return cfg
+	return cfg, nil
 }
 
 // GetPostgresDSN возвращает строку подключения к PostgreSQL
@@ -79,6 +137,20 @@ func (c *Config) GetPostgresDSN() string {
 		c.PostgresHost, c.PostgresPort, c.PostgresUser, c.PostgresPassword, c.PostgresDB, c.PostgresSSLMode)
 }
 
+// PasswordPolicy собирает политику паролей из настроек MIN_PASSWORD_LENGTH и PASSWORD_*
+func (c *Config) PasswordPolicy() *app.PasswordPolicy {
+	return &app.PasswordPolicy{
+		MinLength:      c.MinPasswordLength,
+		RequireUpper:   c.PasswordRequireUpper,
+		RequireLower:   c.PasswordRequireLower,
+		RequireDigit:   c.PasswordRequireDigit,
+		RequireSymbol:  c.PasswordRequireSymbol,
+		MaxRepeated:    c.PasswordMaxRepeated,
+		ForbidPersonal: c.PasswordForbidPersonal,
+		ForbidCommon:   c.PasswordForbidCommon,
+	}
+}
+
 // NewLogger создает новый логгер на основе конфигурации
 func NewLogger(cfg *Config) (*zap.Logger, error) {
 	var zapConfig zap.Config
@@ -100,31 +172,3 @@ func NewLogger(cfg *Config) (*zap.Logger, error) {
 
 	return zapConfig.Build()
 }
-
-// getEnv получает значение переменной окружения или возвращает значение по умолчанию
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// getEnvAsInt получает значение переменной окружения как int или возвращает значение по умолчанию
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := fmt.Sscanf(value, "%d", &defaultValue); err == nil && intValue == 1 {
-			return defaultValue
-		}
-	}
-	return defaultValue
-}
-
-// getEnvAsDuration получает значение переменной окружения как Duration или возвращает значение по умолчанию
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
-		}
-	}
-	return defaultValue
-}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_PasswordPolicy(t *testing.T) {
+	cfg := &Config{
+		MinPasswordLength:      10,
+		PasswordRequireUpper:   true,
+		PasswordRequireLower:   true,
+		PasswordRequireDigit:   true,
+		PasswordRequireSymbol:  true,
+		PasswordMaxRepeated:    3,
+		PasswordForbidPersonal: true,
+		PasswordForbidCommon:   true,
+	}
+
+	assert.Equal(t, &app.PasswordPolicy{
+		MinLength:      10,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RequireSymbol:  true,
+		MaxRepeated:    3,
+		ForbidPersonal: true,
+		ForbidCommon:   true,
+	}, cfg.PasswordPolicy())
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Размер ключа шифрования AES-256
+const encryptionKeySize = 32
+
+// EncryptionKey представляет ключ шифрования данных в БД. Формат переменной: 64 hex символа или 32 байта в base64
+type EncryptionKey struct {
+	key []byte
+}
+
+// IsSet сообщает, задан ли ключ
+func (k EncryptionKey) IsSet() bool {
+	return len(k.key) > 0
+}
+
+// Bytes возвращает ключ
+func (k EncryptionKey) Bytes() []byte {
+	return k.key
+}
+
+// UnmarshalText разбирает ключ из переменной окружения
+func (k *EncryptionKey) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+
+	if key, err := hex.DecodeString(value); err == nil && len(key) == encryptionKeySize {
+		k.key = key
+		return nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(value); err == nil && len(key) == encryptionKeySize {
+		k.key = key
+		return nil
+	}
+
+	return fmt.Errorf("expected %d bytes as hex or base64", encryptionKeySize)
+}
+
+// String возвращает ключ в hex
+func (k EncryptionKey) String() string {
+	return hex.EncodeToString(k.key)
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Суффикс переменной с путем к файлу, из которого читается значение (Docker/Kubernetes secrets)
+const fileEnvSuffix = "_FILE"
+
+// Значение, которым заменяются секреты при выводе конфигурации
+const redactedValue = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Setting представляет действующее значение настройки
+type Setting struct {
+	Env   string
+	Value string
+}
+
+// load заполняет поля структуры по тегам env, envDefault и envSeparator.
+// Пустая переменная считается незаданной. Возвращает ошибки всех некорректных значений сразу
+func load(target interface{}) error {
+	value := reflect.ValueOf(target).Elem()
+	fields := value.Type()
+
+	var errs []error
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		raw, err := lookupEnv(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if raw == "" {
+			raw = field.Tag.Get("envDefault")
+		}
+
+		if err := setField(value.Field(i), raw, field.Tag.Get("envSeparator")); err != nil {
+			// Значение секрета не попадает в текст ошибки
+			if field.Tag.Get("secret") == "true" {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", key, raw, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lookupEnv возвращает значение переменной key или содержимое файла из key_FILE
+func lookupEnv(key string) (string, error) {
+	value := os.Getenv(key)
+
+	path := os.Getenv(key + fileEnvSuffix)
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("%s and %s%s are both set", key, key, fileEnvSuffix)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s%s: %w", key, fileEnvSuffix, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// setField разбирает raw в значение типа поля
+func setField(field reflect.Value, raw, separator string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if raw == "" {
+			return nil
+		}
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	if field.Type() == durationType {
+		if raw == "" {
+			return nil
+		}
+		duration, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		if raw == "" {
+			return nil
+		}
+		number, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		field.SetInt(number)
+	case reflect.Bool:
+		if raw == "" {
+			return nil
+		}
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("expected a boolean")
+		}
+		field.SetBool(flag)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		if separator == "" {
+			separator = ","
+		}
+		items := []string{}
+		for _, item := range strings.Split(raw, separator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+// parseDuration разбирает длительность в формате time.ParseDuration с поддержкой дней: 7d, 1d12h, 1.5d
+func parseDuration(value string) (time.Duration, error) {
+	days, rest, found := strings.Cut(value, "d")
+	if !found {
+		return time.ParseDuration(value)
+	}
+
+	count, err := strconv.ParseFloat(days, 64)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid number of days %q", days)
+	}
+	duration := time.Duration(count * float64(24*time.Hour))
+
+	if rest != "" {
+		extra, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		if extra < 0 {
+			return 0, errors.New("negative duration after days")
+		}
+		duration += extra
+	}
+
+	return duration, nil
+}
+
+// Settings возвращает действующие значения всех настроек в порядке объявления; секреты скрыты
+func (c *Config) Settings() []Setting {
+	value := reflect.ValueOf(c).Elem()
+	fields := value.Type()
+
+	settings := make([]Setting, 0, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		text := formatValue(value.Field(i))
+		if field.Tag.Get("secret") == "true" && text != "" {
+			text = redactedValue
+		}
+		// Пароль может быть указан прямо в URL, например redis://:password@host:6379
+		if strings.Contains(text, "://") {
+			if parsed, err := url.Parse(text); err == nil && parsed.User != nil {
+				text = parsed.Redacted()
+			}
+		}
+
+		settings = append(settings, Setting{Env: key, Value: text})
+	}
+
+	return settings
+}
+
+// formatValue форматирует значение поля так же, как оно задается в окружении
+func formatValue(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+	if field.Kind() == reflect.Slice {
+		items := make([]string, field.Len())
+		for i := range items {
+			items[i] = field.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
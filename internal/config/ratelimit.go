@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit представляет лимит запросов в скользящем окне. Формат переменной: <запросов>/<окно>, например 10/1m; 0 - без лимита
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Enabled сообщает, задан ли лимит
+func (r RateLimit) Enabled() bool {
+	return r.Requests > 0 && r.Window > 0
+}
+
+// UnmarshalText разбирает лимит из переменной окружения
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "0" {
+		*r = RateLimit{}
+		return nil
+	}
+
+	requests, window, found := strings.Cut(value, "/")
+	if !found {
+		return fmt.Errorf("expected <requests>/<window>, e.g. 10/1m")
+	}
+
+	count, err := strconv.Atoi(requests)
+	if err != nil || count < 0 {
+		return fmt.Errorf("invalid number of requests %q", requests)
+	}
+	duration, err := parseDuration(window)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+
+	*r = RateLimit{Requests: count, Window: duration}
+	return nil
+}
+
+// String возвращает лимит в формате переменной окружения
+func (r RateLimit) String() string {
+	if !r.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", r.Requests, r.Window)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        RateLimit
+		wantEnabled bool
+		wantString  string
+		wantErr     bool
+	}{
+		{name: "requests per minute", value: "10/1m", want: RateLimit{Requests: 10, Window: time.Minute}, wantEnabled: true, wantString: "10/1m0s"},
+		{name: "window in days", value: "5/1d", want: RateLimit{Requests: 5, Window: 24 * time.Hour}, wantEnabled: true, wantString: "5/24h0m0s"},
+		{name: "surrounding whitespace", value: " 3/30s ", want: RateLimit{Requests: 3, Window: 30 * time.Second}, wantEnabled: true, wantString: "3/30s"},
+		{name: "zero disables limit", value: "0", want: RateLimit{}, wantString: "0"},
+		{name: "zero requests disables limit", value: "0/1m", want: RateLimit{Window: time.Minute}, wantString: "0"},
+		{name: "non-numeric requests", value: "x/1m", wantErr: true},
+		{name: "negative requests", value: "-1/1m", wantErr: true},
+		{name: "zero window", value: "5/0s", wantErr: true},
+		{name: "negative window", value: "5/-1m", wantErr: true},
+		{name: "invalid window", value: "5/soon", wantErr: true},
+		{name: "missing window", value: "10", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ошибка разбора не должна менять прежнее значение
+			got := RateLimit{Requests: 99, Window: time.Hour}
+			err := got.UnmarshalText([]byte(tt.value))
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, RateLimit{Requests: 99, Window: time.Hour}, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantEnabled, got.Enabled())
+			assert.Equal(t, tt.wantString, got.String())
+		})
+	}
+}
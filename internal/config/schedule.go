@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule представляет расписание периодической задачи. Формат переменной: длительность (1h, 1d) или
+// @every <длительность> - запуск с интервалом; @hourly, @daily, @weekly, @monthly, @yearly или
+// cron-выражение из пяти полей "минута час день месяц день_недели" в UTC; 0 - задача отключена
+type Schedule struct {
+	Every time.Duration // Интервал между запусками; 0 - расписание задано cron-полями
+
+	spec                          string
+	minute, hour, dom, month, dow uint64 // Допустимые значения полей cron битовыми масками
+	domAny, dowAny                bool   // Поле задано как "*"
+}
+
+// Сокращения cron-выражений
+var scheduleAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// scheduleHorizon предел поиска следующего запуска: выражение, не срабатывающее за это время (например, 30 февраля), не срабатывает никогда
+const scheduleHorizon = 5 * 366 * 24 * time.Hour
+
+// Enabled сообщает, задано ли расписание
+func (s Schedule) Enabled() bool {
+	return s.spec != ""
+}
+
+// Next возвращает время следующего запуска строго после t; нулевое время - запусков больше не будет
+func (s Schedule) Next(t time.Time) time.Time {
+	if !s.Enabled() {
+		return time.Time{}
+	}
+	if s.Every > 0 {
+		return t.Add(s.Every)
+	}
+
+	// Ищем с начала следующей минуты, пропуская целиком неподходящие месяцы, дни и часы
+	next := t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(scheduleHorizon)
+	for next.Before(limit) {
+		switch {
+		case !has(s.month, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !has(s.hour, next.Hour()):
+			next = next.Truncate(time.Hour).Add(time.Hour)
+		case !has(s.minute, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next.In(t.Location())
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches проверяет день месяца и день недели; как в cron, если заданы оба поля, достаточно совпадения одного
+func (s Schedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// UnmarshalText разбирает расписание из переменной окружения
+func (s *Schedule) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "" || value == "0" {
+		*s = Schedule{}
+		return nil
+	}
+
+	if every, found := strings.CutPrefix(value, "@every "); found {
+		return s.setEvery(value, strings.TrimSpace(every))
+	}
+	if !strings.HasPrefix(value, "@") && !strings.Contains(value, " ") {
+		return s.setEvery(value, value)
+	}
+
+	spec := value
+	if alias, ok := scheduleAliases[value]; ok {
+		spec = alias
+	} else if strings.HasPrefix(value, "@") {
+		return fmt.Errorf("unknown schedule %q", value)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return fmt.Errorf("expected 5 cron fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	parsed := Schedule{spec: value}
+	var err error
+	if parsed.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return fmt.Errorf("minute: %w", err)
+	}
+	if parsed.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return fmt.Errorf("hour: %w", err)
+	}
+	if parsed.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return fmt.Errorf("day of month: %w", err)
+	}
+	if parsed.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return fmt.Errorf("month: %w", err)
+	}
+	if parsed.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return fmt.Errorf("day of week: %w", err)
+	}
+	// 7 и 0 означают воскресенье
+	if has(parsed.dow, 7) {
+		parsed.dow |= 1
+	}
+	parsed.domAny = fields[2] == "*"
+	parsed.dowAny = fields[4] == "*"
+
+	*s = parsed
+	return nil
+}
+
+// String возвращает расписание в формате переменной окружения
+func (s Schedule) String() string {
+	if !s.Enabled() {
+		return "0"
+	}
+	return s.spec
+}
+
+// setEvery задает запуск с интервалом
+func (s *Schedule) setEvery(spec, value string) error {
+	every, err := parseDuration(value)
+	if err != nil {
+		return err
+	}
+	if every < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	if every == 0 {
+		*s = Schedule{}
+		return nil
+	}
+
+	*s = Schedule{Every: every, spec: spec}
+	return nil
+}
+
+// parseCronField разбирает поле cron: *, число, диапазон a-b, шаг */n или a-b/n и их списки через запятую
+func parseCronField(field string, minValue, maxValue int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = minValue, maxValue
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseCronValue(lo, minValue, maxValue); err != nil {
+				return 0, err
+			}
+			if to, err = parseCronValue(hi, minValue, maxValue); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := parseCronValue(rangePart, minValue, maxValue)
+			if err != nil {
+				return 0, err
+			}
+			// Шаг от одного значения продолжается до конца диапазона поля
+			from, to = value, value
+			if hasStep {
+				to = maxValue
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// parseCronValue разбирает число поля cron и проверяет его диапазон
+func parseCronValue(value string, minValue, maxValue int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if n < minValue || n > maxValue {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, minValue, maxValue)
+	}
+	return n, nil
+}
+
+// has проверяет, входит ли значение в битовую маску поля cron
+func has(mask uint64, value int) bool {
+	return mask&(1<<uint(value)) != 0
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantEvery   time.Duration
+		wantEnabled bool
+		wantString  string
+		wantErr     bool
+	}{
+		{name: "duration", value: "1h", wantEvery: time.Hour, wantEnabled: true, wantString: "1h"},
+		{name: "duration in days", value: "1d", wantEvery: 24 * time.Hour, wantEnabled: true, wantString: "1d"},
+		{name: "every", value: "@every 15m", wantEvery: 15 * time.Minute, wantEnabled: true, wantString: "@every 15m"},
+		{name: "alias", value: "@daily", wantEnabled: true, wantString: "@daily"},
+		{name: "cron expression", value: "30 3 * * 1-5", wantEnabled: true, wantString: "30 3 * * 1-5"},
+		{name: "surrounding whitespace", value: " 0 * * * * ", wantEnabled: true, wantString: "0 * * * *"},
+		{name: "zero disables job", value: "0", wantString: "0"},
+		{name: "zero duration disables job", value: "0s", wantString: "0"},
+		{name: "empty disables job", value: "", wantString: "0"},
+		{name: "negative duration", value: "-1h", wantErr: true},
+		{name: "invalid duration", value: "soon", wantErr: true},
+		{name: "invalid every", value: "@every soon", wantErr: true},
+		{name: "unknown alias", value: "@sometimes", wantErr: true},
+		{name: "too few fields", value: "0 * * *", wantErr: true},
+		{name: "too many fields", value: "0 0 * * * *", wantErr: true},
+		{name: "minute out of range", value: "60 * * * *", wantErr: true},
+		{name: "hour out of range", value: "0 24 * * *", wantErr: true},
+		{name: "day of month out of range", value: "0 0 0 * *", wantErr: true},
+		{name: "month out of range", value: "0 0 * 13 *", wantErr: true},
+		{name: "day of week out of range", value: "0 0 * * 8", wantErr: true},
+		{name: "reversed range", value: "0 5-1 * * *", wantErr: true},
+		{name: "zero step", value: "*/0 * * * *", wantErr: true},
+		{name: "names are not supported", value: "0 0 * * mon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ошибка разбора не должна менять прежнее значение
+			got := Schedule{Every: time.Minute, spec: "1m"}
+			err := got.UnmarshalText([]byte(tt.value))
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, Schedule{Every: time.Minute, spec: "1m"}, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEvery, got.Every)
+			assert.Equal(t, tt.wantEnabled, got.Enabled())
+			assert.Equal(t, tt.wantString, got.String())
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Среда, 15 января 2025
+	from := time.Date(2025, time.January, 15, 10, 20, 30, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		from  time.Time
+		want  time.Time
+	}{
+		{name: "interval", value: "90m", from: from, want: from.Add(90 * time.Minute)},
+		{name: "every minute", value: "* * * * *", from: from, want: time.Date(2025, 1, 15, 10, 21, 0, 0, time.UTC)},
+		{name: "exact minute is not repeated", value: "* * * * *", from: time.Date(2025, 1, 15, 10, 21, 0, 0, time.UTC), want: time.Date(2025, 1, 15, 10, 22, 0, 0, time.UTC)},
+		{name: "hourly", value: "@hourly", from: from, want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{name: "daily", value: "@daily", from: from, want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "weekly on sunday", value: "@weekly", from: from, want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "monthly", value: "@monthly", from: from, want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "yearly", value: "@yearly", from: from, want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "later today", value: "30 3,14 * * *", from: from, want: time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)},
+		{name: "step", value: "*/25 * * * *", from: from, want: time.Date(2025, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{name: "step from value", value: "5/20 * * * *", from: from, want: time.Date(2025, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{name: "range with step", value: "0 8-18/4 * * *", from: from, want: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{name: "weekdays skip weekend", value: "0 9 * * 1-5", from: time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC), want: time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)},
+		{name: "seven is sunday", value: "0 0 * * 7", from: from, want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month or day of week", value: "0 0 20 * 5", from: from, want: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month with any weekday", value: "0 0 20 * *", from: from, want: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{name: "skips short months", value: "0 0 31 * *", from: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), want: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", value: "0 0 29 2 *", from: from, want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "never fires", value: "0 0 30 2 *", from: from, want: time.Time{}},
+		{name: "disabled", value: "0", from: from, want: time.Time{}},
+		{
+			name:  "evaluated in utc and returned in caller location",
+			value: "0 12 * * *",
+			from:  time.Date(2025, 1, 15, 14, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)),
+			want:  time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schedule
+			require.NoError(t, s.UnmarshalText([]byte(tt.value)))
+
+			got := s.Next(tt.from)
+			if tt.want.IsZero() {
+				assert.True(t, got.IsZero(), got)
+				return
+			}
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+			assert.Equal(t, tt.from.Location(), got.Location())
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+)
+
+// Окружения приложения
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Значение JWT_SECRET по умолчанию, с которым нельзя запускаться в production
+const defaultJWTSecret = "your-secret-key"
+
+// Минимальная длина JWT_SECRET в production
+const minJWTSecretLength = 32
+
+// Problem описывает одну проблему конфигурации
+type Problem struct {
+	Env     string // Переменная окружения
+	Message string
+}
+
+// ValidationError содержит все найденные проблемы конфигурации
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error перечисляет все проблемы конфигурации
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		messages = append(messages, fmt.Sprintf("%s: %s", problem.Env, problem.Message))
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// IsProduction сообщает, запущено ли приложение в production
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == EnvProduction
+}
+
+// Validate проверяет конфигурацию и возвращает *ValidationError со всеми найденными проблемами.
+// В production запрещены значения по умолчанию, небезопасные для реального развертывания
+func (c *Config) Validate() error {
+	var problems []Problem
+	add := func(env, format string, args ...interface{}) {
+		problems = append(problems, Problem{Env: env, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch c.AppEnv {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		add("APP_ENV", "unknown environment %q, expected %s, %s or %s", c.AppEnv, EnvDevelopment, EnvStaging, EnvProduction)
+	}
+
+	// Без списка доверенных прокси заголовок с IP мог бы подставить любой клиент
+	if c.ProxyHeader != "" && len(c.TrustedProxies) == 0 {
+		add("TRUSTED_PROXIES", "must be set when PROXY_HEADER is set")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				add("TRUSTED_PROXIES", "%q is neither an IP address nor a CIDR subnet", proxy)
+			}
+		}
+	}
+
+	// Токены с нулевым сроком жизни истекли бы сразу после выдачи
+	if c.JWTExpiration <= 0 {
+		add("JWT_EXPIRATION", "must be positive")
+	}
+	if c.RefreshTokenExpiration <= 0 {
+		add("REFRESH_TOKEN_EXPIRATION", "must be positive")
+	}
+	// Иначе refresh токен истекал бы раньше access токена и не мог бы его обновить
+	if c.JWTExpiration > 0 && c.RefreshTokenExpiration > 0 && c.RefreshTokenExpiration <= c.JWTExpiration {
+		add("REFRESH_TOKEN_EXPIRATION", "must be longer than JWT_EXPIRATION")
+	}
+
+	if c.LoginBackoffAfter < 0 {
+		add("LOGIN_BACKOFF_AFTER", "must not be negative")
+	}
+	if c.LoginLockoutThreshold < 0 {
+		add("LOGIN_LOCKOUT_THRESHOLD", "must not be negative")
+	}
+	if (c.LoginBackoffAfter > 0 || c.LoginLockoutThreshold > 0) && c.LoginLockoutDuration <= 0 {
+		add("LOGIN_LOCKOUT_DURATION", "must be positive when login back-off or lockout is enabled")
+	}
+
+	if c.EmailVerificationExpiration <= 0 {
+		add("EMAIL_VERIFICATION_EXPIRATION", "must be positive")
+	}
+	if c.MinPasswordLength < 1 {
+		add("MIN_PASSWORD_LENGTH", "must be positive")
+	}
+	// Пароли длиннее MaxPasswordLength отклоняются всегда, иначе ни один пароль не прошел бы политику
+	if c.MinPasswordLength > app.MaxPasswordLength {
+		add("MIN_PASSWORD_LENGTH", "must not exceed %d", app.MaxPasswordLength)
+	}
+	if c.PasswordMaxRepeated < 0 {
+		add("PASSWORD_MAX_REPEATED", "must not be negative")
+	}
+	if c.PasswordHistorySize < 0 {
+		add("PASSWORD_HISTORY_SIZE", "must not be negative")
+	}
+
+	if c.EmailChangeExpiration <= 0 {
+		add("EMAIL_CHANGE_EXPIRATION", "must be positive")
+	}
+
+	if c.MFAPendingTokenTTL <= 0 {
+		add("MFA_PENDING_TOKEN_TTL", "must be positive")
+	}
+	if c.MFARecoveryCodes <= 0 {
+		add("MFA_RECOVERY_CODES", "must be positive")
+	}
+
+	if c.IsProduction() {
+		// Секрет используется только для HS256; при JWT_KEYS_DIR токены подписываются ключами из каталога
+		if c.JWTKeysDir == "" {
+			switch {
+			case c.JWTSecret == defaultJWTSecret:
+				add("JWT_SECRET", "default secret is not allowed in production")
+			case len(c.JWTSecret) < minJWTSecretLength:
+				add("JWT_SECRET", "must be at least %d characters in production", minJWTSecretLength)
+			}
+		}
+		// Без закрепленного ключа реплика после перезапуска начала бы подписывать только что созданным ключом,
+		// которого еще нет у остальных реплик, и они отклоняли бы ее токены
+		if c.JWTKeysDir != "" && c.JWTSigningKeyID == "" {
+			add("JWT_SIGNING_KEY_ID", "must be set in production when JWT_KEYS_DIR is set")
+		}
+
+		// Ключ, производный от JWT_SECRET, сменился бы вместе с ним и сделал секреты TOTP нечитаемыми
+		if !c.MFAEncryptionKey.IsSet() {
+			add("MFA_ENCRYPTION_KEY", "must be set in production")
+		}
+
+		if c.PostgresPassword == "" {
+			add("POSTGRES_PASSWORD", "empty password is not allowed in production")
+		}
+
+		switch c.PostgresSSLMode {
+		case "require", "verify-ca", "verify-full":
+		default:
+			add("POSTGRES_SSLMODE", "%q is insecure in production, use require, verify-ca or verify-full", c.PostgresSSLMode)
+		}
+
+		for _, origin := range c.CORSAllowedOrigins {
+			if origin == "*" {
+				add("CORS_ALLOWED_ORIGINS", "wildcard origin is not allowed in production")
+				break
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
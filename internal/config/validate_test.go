@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "defaults", change: func(cfg *Config) {}},
+		{
+			name: "trusted proxies",
+			change: func(cfg *Config) {
+				cfg.ProxyHeader = "X-Real-IP"
+				cfg.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12", "::1"}
+			},
+		},
+		{
+			name:    "proxy header without trusted proxies",
+			change:  func(cfg *Config) { cfg.ProxyHeader = "X-Forwarded-For" },
+			wantErr: "TRUSTED_PROXIES: must be set when PROXY_HEADER is set",
+		},
+		{
+			name: "invalid trusted proxy",
+			change: func(cfg *Config) {
+				cfg.ProxyHeader = "X-Real-IP"
+				cfg.TrustedProxies = []string{"proxy.local"}
+			},
+			wantErr: `TRUSTED_PROXIES: "proxy.local" is neither an IP address nor a CIDR subnet`,
+		},
+		{
+			name:    "zero access token expiration",
+			change:  func(cfg *Config) { cfg.JWTExpiration = 0 },
+			wantErr: "JWT_EXPIRATION: must be positive",
+		},
+		{
+			name:    "negative refresh token expiration",
+			change:  func(cfg *Config) { cfg.RefreshTokenExpiration = -time.Hour },
+			wantErr: "REFRESH_TOKEN_EXPIRATION: must be positive",
+		},
+		{
+			name: "refresh token not longer than access token",
+			change: func(cfg *Config) {
+				cfg.JWTExpiration = time.Hour
+				cfg.RefreshTokenExpiration = time.Hour
+			},
+			wantErr: "REFRESH_TOKEN_EXPIRATION: must be longer than JWT_EXPIRATION",
+		},
+		{
+			name: "unpinned signing key in production",
+			change: func(cfg *Config) {
+				cfg.AppEnv = EnvProduction
+				cfg.JWTKeysDir = "/etc/jwt-keys"
+			},
+			wantErr: "JWT_SIGNING_KEY_ID: must be set in production when JWT_KEYS_DIR is set",
+		},
+		{
+			name:   "longest allowed minimum password length",
+			change: func(cfg *Config) { cfg.MinPasswordLength = 128 },
+		},
+		{
+			name:    "minimum password length above maximum",
+			change:  func(cfg *Config) { cfg.MinPasswordLength = 129 },
+			wantErr: "MIN_PASSWORD_LENGTH: must not exceed 128",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := New()
+			require.NoError(t, err)
+			tt.change(cfg)
+
+			err = cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
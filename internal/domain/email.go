@@ -0,0 +1,22 @@
+package domain
+
+// EmailTemplate представляет шаблон письма
+type EmailTemplate string
+
+const (
+	EmailTemplatePasswordReset EmailTemplate = "password_reset"
+	EmailTemplateWelcome       EmailTemplate = "welcome"
+	EmailTemplateSecurityAlert EmailTemplate = "security_alert"
+	EmailTemplateVerifyEmail   EmailTemplate = "verify_email"
+	EmailTemplateEmailChange   EmailTemplate = "email_change"
+	EmailTemplateEmailNotice   EmailTemplate = "email_change_notice"
+)
+
+// Email представляет письмо для отправки по шаблону
+type Email struct {
+	To       string         `json:"to"`
+	ToName   string         `json:"to_name"`
+	Template EmailTemplate  `json:"template"`
+	Locale   string         `json:"locale"` // ru, en; пустое значение - локаль по умолчанию
+	Data     map[string]any `json:"data"`
+}
@@ -5,4 +5,14 @@ import "errors"
 // Ошибки домена
 var (
 	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+
+	ErrUserStatusChanged = errors.New("user status changed concurrently")
+
+	ErrPasswordResetUsed     = errors.New("password reset already used")
+	ErrEmailVerificationUsed = errors.New("email verification already used")
+	ErrEmailChangeUsed       = errors.New("email change already used")
+	ErrSessionRotated        = errors.New("session already rotated")
+
+	ErrPermissionNotFound = errors.New("permission not found")
 )
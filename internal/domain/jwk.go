@@ -0,0 +1,19 @@
+package domain
+
+// JSONWebKey представляет открытый ключ проверки подписи JWT (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // EC и OKP
+	N         string `json:"n,omitempty"`   // Модуль RSA
+	E         string `json:"e,omitempty"`   // Экспонента RSA
+	X         string `json:"x,omitempty"`   // EC и OKP
+	Y         string `json:"y,omitempty"`   // EC
+}
+
+// JSONWebKeySet представляет набор открытых ключей для /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+// Permission представляет право на действие в формате "ресурс:действие"
+type Permission string
+
+const (
+	PermissionUsersRead      Permission = "users:read"
+	PermissionUsersWrite     Permission = "users:write"
+	PermissionUsersBan       Permission = "users:ban"
+	PermissionSessionsRevoke Permission = "sessions:revoke"
+)
+
+// PermissionScope представляет субъект, которому выдаются права
+type PermissionScope string
+
+const (
+	PermissionScopeRole PermissionScope = "role"
+	PermissionScopeUser PermissionScope = "user"
+)
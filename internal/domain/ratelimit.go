@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// RateLimitResult представляет результат проверки лимита запросов
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // Через сколько освободится место в окне
+}
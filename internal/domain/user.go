@@ -4,40 +4,158 @@ import "time"
 
 // User представляет пользователя в системе
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	Name         string    `json:"name"`
-	PasswordHash string    `json:"-"` // Не отправляем в JSON
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	PasswordHash string     `json:"-"` // Не отправляем в JSON
+	Role         UserRole   `json:"role"`
+	Status       UserStatus `json:"status"`
+	BanReason    string     `json:"ban_reason"`
+	BannedUntil  *time.Time `json:"banned_until"` // nil - бессрочная блокировка
+	BannedBy     string     `json:"banned_by"`    // ID администратора, заблокировавшего пользователя
+	BannedAt     *time.Time `json:"banned_at"`
+
+	FailedLoginAttempts int        `json:"failed_login_attempts"` // Неудачные попытки входа подряд
+	LastFailedLoginAt   *time.Time `json:"last_failed_login_at"`
+	LockedUntil         *time.Time `json:"locked_until"` // Вход запрещен до указанного времени
+
+	TOTPSecret   string `json:"-"` // Зашифрованный секрет TOTP, задается при подключении
+	TOTPEnabled  bool   `json:"totp_enabled"`
+	TOTPLastStep int64  `json:"-"` // Шаг последнего принятого кода, защищает от повторного использования
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // nil - владение email не подтверждено
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserListFilter представляет параметры выборки списка пользователей
+type UserListFilter struct {
+	Status      UserStatus // Фильтр по статусу (пустой - без фильтра)
+	EmailPrefix string     // Фильтр по началу email
+	SortBy      string     // Поле сортировки: created_at, updated_at, email, name
+	SortDesc    bool       // Сортировка по убыванию
+	Limit       int
+	Offset      int
+}
+
+// UserUpdate представляет изменяемые поля пользователя (nil - поле не меняется)
+type UserUpdate struct {
+	Name       *string
+	Email      *string     // Новый email сбрасывает подтверждение
+	Status     *UserStatus // Новый статус снимает данные истекшей блокировки
+	FromStatus UserStatus  // Статус, прочитанный перед изменением; статус меняется, только если он не изменился
 }
 
-// UserSession представляет сессию пользователя
+// UserSession представляет сессию пользователя на конкретном устройстве
 type UserSession struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	TokenHash  string     `json:"-"` // Хеш refresh токена
+	DeviceID   string     `json:"device_id"`
+	AppType    string     `json:"app_type"`
+	AppVersion string     `json:"app_version"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	FamilyID   string     `json:"family_id"`   // Семейство ротации: ID первой сессии цепочки
+	RotatedAt  *time.Time `json:"rotated_at"`  // Время ротации, nil для актуальной сессии
+	ReplacedBy string     `json:"replaced_by"` // ID сессии, выданной при ротации
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SecurityEventType представляет тип события безопасности
+type SecurityEventType string
+
+const (
+	SecurityEventRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+)
+
+// SecurityEvent представляет запись журнала событий безопасности
+type SecurityEvent struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Type      SecurityEventType `json:"type"`
+	IPAddress string            `json:"ip_address"`
+	UserAgent string            `json:"user_agent"`
+	Details   map[string]any    `json:"details"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// DeviceInfo представляет данные устройства, с которого выполняется запрос
+type DeviceInfo struct {
+	DeviceID   string `json:"device_id"`
+	AppType    string `json:"app_type"`
+	AppVersion string `json:"app_version"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+}
+
+// AccessTokenStatus представляет состояние отзыва access токена и его владельца
+type AccessTokenStatus struct {
+	Revoked    bool      // Токен добавлен в denylist (logout) или его сессия отозвана
+	UserBanned bool      // Владелец токена заблокирован
+	ValidAfter time.Time // Токены, выданные раньше, недействительны; нулевое значение - без ограничения
+}
+
+// PasswordReset представляет запрос на сброс пароля
+type PasswordReset struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
-	TokenHash string    `json:"-"` // Хеш refresh токена
+	TokenHash string    `json:"-"` // Хеш токена из письма
 	ExpiresAt time.Time `json:"expires_at"`
+	Used      bool      `json:"used"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// PasswordReset представляет запрос на сброс пароля
-type PasswordReset struct {
+// EmailVerification представляет запрос на подтверждение email
+type EmailVerification struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
-	Token     string    `json:"token"`
+	Email     string    `json:"email"` // Адрес, на который отправлено письмо; после смены email токен недействителен
+	TokenHash string    `json:"-"`     // Хеш токена из письма
 	ExpiresAt time.Time `json:"expires_at"`
 	Used      bool      `json:"used"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// EmailChange представляет запрос на смену email
+type EmailChange struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	NewEmail        string    `json:"new_email"`
+	TokenHash       string    `json:"-"` // Хеш токена подтверждения из письма на новый адрес
+	CancelTokenHash string    `json:"-"` // Хеш токена отмены из письма на старый адрес
+	ExpiresAt       time.Time `json:"expires_at"`
+	Used            bool      `json:"used"` // Запрос подтвержден или отменен
+	CreatedAt       time.Time `json:"created_at"`
+}
+
 // AuthTokens представляет пару токенов аутентификации
 type AuthTokens struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
 
+// MFAChallenge выдается при входе по паролю вместо токенов, если у пользователя включена двухфакторная аутентификация
+type MFAChallenge struct {
+	Token     string    `json:"mfa_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TOTPEnrollment содержит данные для добавления аккаунта в приложение-аутентификатор
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatus представляет состояние двухфакторной аутентификации пользователя
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
 // UserStatus представляет статус пользователя
 type UserStatus string
 
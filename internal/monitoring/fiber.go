@@ -0,0 +1,78 @@
+package monitoring
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Значение метки endpoint для запросов, не совпавших ни с одним маршрутом
+const unmatchedEndpoint = "unmatched"
+
+// FiberMiddleware возвращает middleware для сбора HTTP метрик.
+// Запросы группируются по шаблону маршрута (/api/v1/users/:id), а не по фактическому пути
+func (m *Metrics) FiberMiddleware() fiber.Handler {
+	// Маршруты обработчиков (без middleware) собираются при первом запросе, когда все роуты уже зарегистрированы
+	var routesOnce sync.Once
+	var handlerRoutes map[string]struct{}
+
+	return func(c *fiber.Ctx) error {
+		routesOnce.Do(func() {
+			handlerRoutes = make(map[string]struct{})
+			for _, route := range c.App().GetRoutes(true) {
+				handlerRoutes[route.Method+" "+route.Path] = struct{}{}
+			}
+		})
+
+		start := time.Now()
+		method := c.Method()
+
+		// Увеличиваем счетчик активных запросов
+		m.httpRequestsInFlight.WithLabelValues(method).Inc()
+		defer m.httpRequestsInFlight.WithLabelValues(method).Dec()
+
+		err := c.Next()
+
+		// Ошибку обработчика в ответ превратит ErrorHandler позже, поэтому статус берем из нее
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		endpoint := routeTemplate(c, handlerRoutes, status)
+		duration := time.Since(start).Seconds()
+
+		m.httpRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(status)).Inc()
+		m.httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration)
+
+		m.logger.Debug("HTTP request metrics recorded",
+			zap.String("method", method),
+			zap.String("endpoint", endpoint),
+			zap.Int("status", status),
+			zap.Float64("duration", duration),
+		)
+
+		return err
+	}
+}
+
+// routeTemplate возвращает шаблон совпавшего маршрута.
+// Если запрос остановила middleware группы, это префикс группы; без совпадений - unmatched
+func routeTemplate(c *fiber.Ctx, handlerRoutes map[string]struct{}, status int) string {
+	route := c.Route()
+	if _, ok := handlerRoutes[route.Method+" "+route.Path]; ok {
+		return route.Path
+	}
+	if status == fiber.StatusNotFound || status == fiber.StatusMethodNotAllowed {
+		return unmatchedEndpoint
+	}
+	return route.Path
+}
@@ -27,6 +27,11 @@ type Metrics struct {
 	databaseConnections *prometheus.GaugeVec
 	redisConnections    *prometheus.GaugeVec
 
+	// Метрики задач обслуживания
+	maintenanceJobRuns        *prometheus.CounterVec
+	maintenanceJobDuration    *prometheus.HistogramVec
+	maintenanceJobLastSuccess *prometheus.GaugeVec
+
 	logger *zap.Logger
 }
 
@@ -59,7 +64,7 @@ func NewMetrics(logger *zap.Logger) *Metrics {
 			Name: "http_requests_in_flight",
 			Help: "Current number of HTTP requests being processed",
 		},
-		[]string{"method", "endpoint"},
+		[]string{"method"},
 	)
 
 	// Бизнес метрики
@@ -120,6 +125,32 @@ func NewMetrics(logger *zap.Logger) *Metrics {
 		[]string{"status"},
 	)
 
+	// Метрики задач обслуживания
+	m.maintenanceJobRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "maintenance_job_runs_total",
+			Help: "Total number of maintenance job runs",
+		},
+		[]string{"job", "status"},
+	)
+
+	m.maintenanceJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "maintenance_job_duration_seconds",
+			Help:    "Maintenance job duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"job"},
+	)
+
+	m.maintenanceJobLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maintenance_job_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful maintenance job run",
+		},
+		[]string{"job"},
+	)
+
 	// Регистрируем метрики
 	prometheus.MustRegister(
 		m.httpRequestsTotal,
@@ -132,42 +163,14 @@ func NewMetrics(logger *zap.Logger) *Metrics {
 		m.activeSessions,
 		m.databaseConnections,
 		m.redisConnections,
+		m.maintenanceJobRuns,
+		m.maintenanceJobDuration,
+		m.maintenanceJobLastSuccess,
 	)
 
 	return m
 }
 
-// HTTPMiddleware возвращает middleware для сбора HTTP метрик
-func (m *Metrics) HTTPMiddleware() func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
-			// Увеличиваем счетчик активных запросов
-			m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Inc()
-			defer m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Dec()
-
-			// Создаем ResponseWriter для перехвата статуса
-			rw := &responseWriter{ResponseWriter: w, statusCode: 200}
-			next.ServeHTTP(rw, r)
-
-			// Записываем метрики
-			duration := time.Since(start).Seconds()
-			status := http.StatusText(rw.statusCode)
-
-			m.httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, status).Inc()
-			m.httpRequestDuration.WithLabelValues(r.Method, r.URL.Path).Observe(duration)
-
-			m.logger.Debug("HTTP request metrics recorded",
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.Int("status", rw.statusCode),
-				zap.Float64("duration", duration),
-			)
-		})
-	}
-}
-
 // RecordUserRegistration записывает метрику регистрации пользователя
 func (m *Metrics) RecordUserRegistration(success bool) {
 	status := "success"
@@ -212,22 +215,19 @@ func (m *Metrics) SetRedisConnections(active int) {
 	m.redisConnections.WithLabelValues("active").Set(float64(active))
 }
 
+// RecordMaintenanceJob записывает метрики запуска задачи обслуживания (status: success, failed, skipped)
+func (m *Metrics) RecordMaintenanceJob(job, status string, duration time.Duration) {
+	m.maintenanceJobRuns.WithLabelValues(job, status).Inc()
+	if status == "skipped" {
+		return
+	}
+	m.maintenanceJobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	if status == "success" {
+		m.maintenanceJobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
+
 // Handler возвращает HTTP handler для метрик
 func (m *Metrics) Handler() http.Handler {
 	return promhttp.Handler()
 }
-
-// responseWriter перехватывает статус код ответа
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
-func (rw *responseWriter) Write(b []byte) (int, error) {
-	return rw.ResponseWriter.Write(b)
-}
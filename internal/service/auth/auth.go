@@ -2,7 +2,7 @@ package auth
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/TeDenis/bukhindor-backend/internal/app"
@@ -13,8 +13,9 @@ import (
 
 // LoginInput представляет входные данные для входа
 type LoginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string            `json:"email"`
+	Password string            `json:"password"`
+	Device   domain.DeviceInfo `json:"device"` // Устройство, на котором открывается сессия
 }
 
 // RegisterInput представляет входные данные для регистрации
@@ -22,23 +23,28 @@ type RegisterInput struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"` // Язык писем
 }
 
-// ResetPasswordInput представляет входные данные для сброса пароля
-type ResetPasswordInput struct {
-	Email string `json:"email"`
+// LoginResult результат входа по паролю: пара токенов или, при включенной двухфакторной аутентификации, токен ожидания кода
+type LoginResult struct {
+	Tokens *domain.AuthTokens
+	MFA    *domain.MFAChallenge
 }
 
 // Login выполняет аутентификацию пользователя
-func (s *Service) Login(ctx context.Context, input LoginInput) (*domain.AuthTokens, error) {
+func (s *Service) Login(ctx context.Context, input LoginInput) (*LoginResult, error) {
 	// Валидация входных данных
 	if !app.ValidateEmail(input.Email) {
 		s.logger.Warn("Invalid email format", zap.String("email", input.Email))
+		s.metrics.RecordUserLogin(false, "invalid_input")
 		return nil, app.ErrInvalidInput
 	}
 
-	if !app.ValidatePassword(input.Password) {
+	// Политика паролей не применяется ко входу: пароль мог быть задан до ее ужесточения
+	if input.Password == "" || len(input.Password) > app.MaxPasswordLength {
 		s.logger.Warn("Invalid password format")
+		s.metrics.RecordUserLogin(false, "invalid_input")
 		return nil, app.ErrInvalidInput
 	}
 
@@ -46,58 +52,85 @@ func (s *Service) Login(ctx context.Context, input LoginInput) (*domain.AuthToke
 	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
 	if err != nil {
 		s.logger.Warn("User not found during login", zap.String("email", input.Email))
+		s.metrics.RecordUserLogin(false, "user_not_found")
 		return nil, app.ErrInvalidCredentials
 	}
 
 	// Проверяем активность пользователя
-	if !user.IsActive {
+	now := time.Now()
+	status := app.CurrentUserStatus(user, now)
+	if status == domain.UserStatusInactive {
 		s.logger.Warn("Inactive user attempted login", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "inactive")
 		return nil, app.ErrInvalidCredentials
 	}
 
-	// Проверяем пароль
+	// Проверяем пароль; попытки во время блокировки входа ее не продлевают
+	locked := isLocked(user, now)
 	if !app.CheckPasswordHash(input.Password, user.PasswordHash) {
 		s.logger.Warn("Invalid password for user", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "invalid_password")
+		if !locked {
+			s.registerFailedLogin(ctx, user)
+		}
 		return nil, app.ErrInvalidCredentials
 	}
 
-	// Генерируем токены
-	tokens, err := s.generateTokens(user.ID)
-	if err != nil {
-		s.logger.Error("Failed to generate tokens", zap.Error(err), zap.String("user_id", user.ID))
-		return nil, app.ErrInternalServer
+	// Во время блокировки не пускает даже верный пароль; ответ не отличается от неверного пароля,
+	// чтобы по нему нельзя было узнать о существовании аккаунта и его блокировке
+	if locked {
+		s.logger.Warn("Locked user attempted login", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "locked")
+		return nil, app.ErrInvalidCredentials
 	}
 
-	// Сохраняем refresh токен в Redis
-	err = s.redisRepo.SetRefreshToken(ctx, user.ID, tokens.RefreshToken, s.config.RefreshTokenExpiration)
-	if err != nil {
-		s.logger.Error("Failed to save refresh token", zap.Error(err), zap.String("user_id", user.ID))
-		return nil, app.ErrInternalServer
+	// О блокировке сообщаем только после проверки пароля, чтобы не раскрывать статус аккаунта
+	if status == domain.UserStatusBanned {
+		s.logger.Warn("Banned user attempted login", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "banned")
+		return nil, app.ErrUserBanned
+	}
+
+	if s.config.EmailVerificationRequired && user.EmailVerifiedAt == nil {
+		s.logger.Warn("Unverified user attempted login", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "email_not_verified")
+		return nil, app.ErrEmailNotVerified
 	}
 
-	// Создаем сессию в БД
-	session := &domain.UserSession{
-		ID:        app.GenerateUUID(),
-		UserID:    user.ID,
-		TokenHash: app.HashToken(tokens.RefreshToken), // Хешируем refresh токен для БД
-		ExpiresAt: time.Now().Add(s.config.RefreshTokenExpiration),
-		CreatedAt: time.Now(),
+	// Вход завершается кодом второго фактора; счетчик неудач сбрасывается только после него
+	if user.TOTPEnabled {
+		challenge, err := s.issueMFAChallenge(user, input.Device)
+		if err != nil {
+			s.metrics.RecordUserLogin(false, "internal")
+			return nil, err
+		}
+		s.logger.Info("Password verified, waiting for second factor", zap.String("user_id", user.ID))
+		return &LoginResult{MFA: challenge}, nil
 	}
 
-	err = s.sessionRepo.CreateSession(ctx, session)
+	s.resetFailedLogins(ctx, user)
+
+	// Открываем сессию на устройстве (предыдущая сессия этого устройства заменяется)
+	tokens, err := s.issueSession(ctx, user, input.Device, time.Time{})
 	if err != nil {
-		s.logger.Error("Failed to create session", zap.Error(err), zap.String("user_id", user.ID))
-		// Удаляем refresh токен из Redis если не удалось создать сессию
-		_ = s.redisRepo.DeleteRefreshToken(ctx, user.ID)
-		return nil, app.ErrInternalServer
+		s.metrics.RecordUserLogin(false, "internal")
+		return nil, err
 	}
 
+	s.metrics.RecordUserLogin(true, "")
 	s.logger.Info("User logged in successfully", zap.String("user_id", user.ID), zap.String("email", user.Email))
-	return tokens, nil
+	return &LoginResult{Tokens: tokens}, nil
 }
 
 // Register регистрирует нового пользователя
 func (s *Service) Register(ctx context.Context, input RegisterInput) (*domain.User, error) {
+	user, err := s.register(ctx, input)
+	s.metrics.RecordUserRegistration(err == nil)
+	return user, err
+}
+
+// register выполняет регистрацию, Register учитывает ее результат в метриках
+func (s *Service) register(ctx context.Context, input RegisterInput) (*domain.User, error) {
 	// Валидация входных данных
 	if !app.ValidateName(input.Name) {
 		s.logger.Warn("Invalid name format", zap.String("name", input.Name))
@@ -109,9 +142,9 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (*domain.Us
 		return nil, app.ErrInvalidInput
 	}
 
-	if !app.ValidatePassword(input.Password) {
-		s.logger.Warn("Invalid password format")
-		return nil, app.ErrInvalidInput
+	if err := s.passwordPolicy.Validate(input.Password, input.Email, input.Name); err != nil {
+		s.logger.Warn("Password does not meet policy")
+		return nil, err
 	}
 
 	// Проверяем, существует ли пользователь с таким email
@@ -134,97 +167,61 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (*domain.Us
 		Email:        input.Email,
 		Name:         input.Name,
 		PasswordHash: passwordHash,
-		IsActive:     true,
+		Role:         domain.UserRoleUser,
+		Status:       domain.UserStatusActive,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
 	err = s.userRepo.CreateUser(ctx, user)
 	if err != nil {
+		// Параллельная регистрация с тем же email отсекается уникальным индексом
+		if errors.Is(err, domain.ErrUserExists) {
+			s.logger.Warn("User already exists", zap.String("email", input.Email))
+			return nil, app.ErrUserExists
+		}
 		s.logger.Error("Failed to create user", zap.Error(err), zap.String("email", input.Email))
 		return nil, app.ErrInternalServer
 	}
 
+	// Приветствие отправляется после подтверждения email; ошибка отправки не отменяет регистрацию
+	_ = s.sendVerificationEmail(ctx, user, input.Locale)
+
 	s.logger.Info("User registered successfully", zap.String("user_id", user.ID), zap.String("email", user.Email))
 	return user, nil
 }
 
-// RequestPasswordReset создает запрос на сброс пароля
-func (s *Service) RequestPasswordReset(ctx context.Context, input ResetPasswordInput) error {
-	// Валидация email
-	if !app.ValidateEmail(input.Email) {
-		s.logger.Warn("Invalid email format", zap.String("email", input.Email))
-		return app.ErrInvalidInput
-	}
-
-	// Получаем пользователя по email
-	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
-	if err != nil {
-		// Не раскрываем информацию о существовании пользователя
-		s.logger.Debug("User not found for password reset", zap.String("email", input.Email))
-		return nil // Возвращаем успех даже если пользователь не найден
-	}
-
-	// Проверяем активность пользователя
-	if !user.IsActive {
-		s.logger.Debug("Inactive user requested password reset", zap.String("user_id", user.ID))
-		return nil // Возвращаем успех даже для неактивных пользователей
-	}
-
-	// Генерируем токен для сброса пароля
-	token, err := app.GenerateRandomToken(app.PasswordResetTokenLength)
-	if err != nil {
-		s.logger.Error("Failed to generate password reset token", zap.Error(err), zap.String("user_id", user.ID))
-		return app.ErrInternalServer
-	}
-
-	// Создаем запрос на сброс пароля
-	reset := &domain.PasswordReset{
-		ID:        app.GenerateUUID(),
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(time.Duration(app.PasswordResetExpiration) * time.Hour),
-		Used:      false,
-		CreatedAt: time.Now(),
+// generateTokens генерирует пару токенов (access и refresh) для сессии sessionID, access токен несет роль пользователя.
+// Access токен выдается строго позже revokedAt: токен, выданный в ту же миллисекунду, что и отзыв, считается отозванным
+func (s *Service) generateTokens(userID, sessionID string, role domain.UserRole, revokedAt time.Time) (*domain.AuthTokens, error) {
+	issuedAt := time.Now()
+	if notBefore := revokedAt.Truncate(time.Millisecond).Add(time.Millisecond); issuedAt.Before(notBefore) {
+		issuedAt = notBefore
 	}
 
-	err = s.passwordResetRepo.CreatePasswordReset(ctx, reset)
-	if err != nil {
-		s.logger.Error("Failed to create password reset", zap.Error(err), zap.String("user_id", user.ID))
-		return app.ErrInternalServer
-	}
-
-	// TODO: Отправить email с токеном для сброса пароля
-	// В реальном приложении здесь должна быть отправка email
-
-	s.logger.Info("Password reset requested", zap.String("user_id", user.ID), zap.String("email", user.Email))
-	return nil
-}
-
-// generateTokens генерирует пару токенов (access и refresh)
-func (s *Service) generateTokens(userID string) (*domain.AuthTokens, error) {
 	// Генерируем access токен
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	accessTokenString, err := s.tokenKeys.Sign(jwt.MapClaims{
+		"jti":     app.GenerateUUID(), // Идентификатор для denylist при отзыве
 		"user_id": userID,
-		"exp":     time.Now().Add(s.config.JWTExpiration).Unix(),
-		"iat":     time.Now().Unix(),
+		"sid":     sessionID,
+		"role":    string(role),
+		"exp":     issuedAt.Add(s.config.JWTExpiration).Unix(),
+		"iat":     float64(issuedAt.UnixMilli()) / 1000, // С миллисекундами, чтобы сравнивать с отметкой отзыва точнее секунды
 		"type":    "access",
 	})
-
-	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWTSecret))
 	if err != nil {
 		return nil, err
 	}
 
 	// Генерируем refresh токен
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	refreshTokenString, err := s.tokenKeys.Sign(jwt.MapClaims{
+		"jti":     app.GenerateUUID(),
 		"user_id": userID,
+		"sid":     sessionID,
 		"exp":     time.Now().Add(s.config.RefreshTokenExpiration).Unix(),
 		"iat":     time.Now().Unix(),
 		"type":    "refresh",
 	})
-
-	refreshTokenString, err := refreshToken.SignedString([]byte(s.config.JWTSecret))
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +234,8 @@ func (s *Service) generateTokens(userID string) (*domain.AuthTokens, error) {
 
 // RefreshTokensInput входные данные для обновления токенов
 type RefreshTokensInput struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string            `json:"refresh_token"`
+	Device       domain.DeviceInfo `json:"device"` // Текущие данные устройства
 }
 
 // RefreshTokens обновляет access токен используя refresh токен
@@ -248,12 +246,7 @@ func (s *Service) RefreshTokens(ctx context.Context, input RefreshTokensInput) (
 	}
 
 	// Парсим JWT токен для получения user_id
-	token, err := jwt.Parse(input.RefreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWTSecret), nil
-	})
+	token, err := jwt.Parse(input.RefreshToken, s.tokenKeys.Keyfunc)
 
 	if err != nil {
 		s.logger.Error("Failed to parse refresh token", zap.Error(err))
@@ -285,8 +278,49 @@ func (s *Service) RefreshTokens(ctx context.Context, input RefreshTokensInput) (
 		return nil, app.ErrInvalidToken
 	}
 
-	// Получаем refresh токен из Redis для проверки
-	storedToken, err := s.redisRepo.GetRefreshToken(ctx, userID)
+	// Токены без sid выданы до привязки сессий к устройствам
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		s.logger.Warn("Missing sid in refresh token", zap.String("user_id", userID))
+		return nil, app.ErrInvalidToken
+	}
+
+	// Получаем сессию, к которой привязан токен
+	session, err := s.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		s.logger.Warn("Session for refresh token not found", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInvalidToken
+	}
+
+	if session.UserID != userID {
+		s.logger.Warn("Refresh token session belongs to another user", zap.String("user_id", userID))
+		return nil, app.ErrInvalidToken
+	}
+
+	// Токен должен быть именно тем, который выдан для этой сессии
+	if session.TokenHash != app.HashToken(input.RefreshToken) {
+		s.logger.Warn("Refresh token does not match session", zap.String("user_id", userID))
+		return nil, app.ErrInvalidToken
+	}
+
+	// Повторное предъявление уже ротированного токена означает его компрометацию
+	if session.RotatedAt != nil {
+		s.handleRefreshTokenReuse(ctx, session, input.Device)
+		return nil, app.ErrInvalidToken
+	}
+
+	// Токен одного устройства нельзя использовать на другом
+	if input.Device.DeviceID != "" && input.Device.DeviceID != session.DeviceID {
+		s.logger.Warn("Refresh token used from another device",
+			zap.String("user_id", userID),
+			zap.String("session_device_id", session.DeviceID),
+			zap.String("device_id", input.Device.DeviceID),
+		)
+		return nil, app.ErrInvalidToken
+	}
+
+	// Получаем refresh токен устройства из Redis для проверки
+	storedToken, err := s.redisRepo.GetRefreshToken(ctx, userID, session.DeviceID)
 	if err != nil {
 		s.logger.Error("Failed to get refresh token from Redis", zap.Error(err), zap.String("user_id", userID))
 		return nil, app.ErrInvalidToken
@@ -302,46 +336,25 @@ func (s *Service) RefreshTokens(ctx context.Context, input RefreshTokensInput) (
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		s.logger.Error("Failed to get user for refresh", zap.Error(err), zap.String("user_id", userID))
-		return nil, app.ErrUserNotFound
+		return nil, app.ErrInvalidToken
 	}
 
-	if !user.IsActive {
+	switch app.CurrentUserStatus(user, time.Now()) {
+	case domain.UserStatusBanned:
+		s.logger.Warn("Banned user tried to refresh token", zap.String("user_id", userID))
+		return nil, app.ErrUserBanned
+	case domain.UserStatusInactive:
 		s.logger.Warn("Inactive user tried to refresh token", zap.String("user_id", userID))
 		return nil, app.ErrForbidden
 	}
 
-	// Генерируем новые токены
-	newTokens, err := s.generateTokens(userID)
+	// Выдаем новую пару токенов в том же семействе ротации; роль берется актуальная из БД
+	newTokens, err := s.rotateSession(ctx, session, user, input.Device)
 	if err != nil {
-		s.logger.Error("Failed to generate new tokens", zap.Error(err), zap.String("user_id", userID))
-		return nil, app.ErrInternalServer
-	}
-
-	// Сохраняем новый refresh токен в Redis
-	err = s.redisRepo.SetRefreshToken(ctx, userID, newTokens.RefreshToken, s.config.RefreshTokenExpiration)
-	if err != nil {
-		s.logger.Error("Failed to save new refresh token", zap.Error(err), zap.String("user_id", userID))
-		return nil, app.ErrInternalServer
-	}
-
-	// Создаем новую сессию в БД
-	session := &domain.UserSession{
-		ID:        app.GenerateUUID(),
-		UserID:    userID,
-		TokenHash: app.HashToken(newTokens.RefreshToken),
-		ExpiresAt: time.Now().Add(s.config.RefreshTokenExpiration),
-		CreatedAt: time.Now(),
-	}
-
-	err = s.sessionRepo.CreateSession(ctx, session)
-	if err != nil {
-		s.logger.Error("Failed to create new session", zap.Error(err), zap.String("user_id", userID))
-		// Удаляем refresh токен из Redis в случае ошибки
-		_ = s.redisRepo.DeleteRefreshToken(ctx, userID)
-		return nil, app.ErrInternalServer
+		return nil, err
 	}
 
-	s.logger.Info("Tokens refreshed successfully", zap.String("user_id", userID))
+	s.logger.Info("Tokens refreshed successfully", zap.String("user_id", userID), zap.String("device_id", session.DeviceID))
 
 	return newTokens, nil
 }
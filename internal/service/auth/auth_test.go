@@ -0,0 +1,439 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Login(t *testing.T) {
+	const password = "Kx9!vQ2#mZ"
+	device := domain.DeviceInfo{DeviceID: "device-1", AppType: "web"}
+	lockedUntil := time.Now().Add(time.Minute)
+	bannedUntil := time.Now().Add(time.Hour)
+
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name                 string
+		password             string
+		verificationRequired bool
+		user                 func(user *domain.User)
+		setup                func(m testMocks)
+		wantErr              error
+	}{
+		{
+			name:     "success resets failed attempts",
+			password: password,
+			user:     func(user *domain.User) { user.FailedLoginAttempts = 2 },
+			setup: func(m testMocks) {
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.sessions.EXPECT().DeleteSessionsByDevice(gomock.Any(), "u1", "device-1").Return(nil)
+				m.sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+				m.redis.EXPECT().SetRefreshToken(gomock.Any(), "u1", "device-1", gomock.Any(), 24*time.Hour).Return(nil)
+				m.metrics.EXPECT().RecordUserLogin(true, "")
+			},
+		},
+		{
+			name:     "wrong password counts failure",
+			password: "wrong-password",
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_password")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:     "failure at threshold locks login",
+			password: "wrong-password",
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_password")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(5, nil)
+				m.users.EXPECT().LockUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:     "wrong password while locked does not extend lock",
+			password: "wrong-password",
+			user:     func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_password")
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:     "correct password while locked looks like wrong password",
+			password: password,
+			user:     func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "locked")
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:     "banned user with wrong password",
+			password: "wrong-password",
+			user:     func(user *domain.User) { user.Status = domain.UserStatusBanned; user.BannedUntil = &bannedUntil },
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_password")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:     "banned user with correct password",
+			password: password,
+			user:     func(user *domain.User) { user.Status = domain.UserStatusBanned; user.BannedUntil = &bannedUntil },
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "banned")
+			},
+			wantErr: app.ErrUserBanned,
+		},
+		{
+			name:     "inactive user",
+			password: password,
+			user:     func(user *domain.User) { user.Status = domain.UserStatusInactive },
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "inactive")
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:                 "unverified email when verification required",
+			password:             password,
+			verificationRequired: true,
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "email_not_verified")
+			},
+			wantErr: app.ErrEmailNotVerified,
+		},
+		{
+			name:                 "unverified email with wrong password",
+			password:             "wrong-password",
+			verificationRequired: true,
+			setup: func(m testMocks) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_password")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:                 "verified email when verification required",
+			password:             password,
+			verificationRequired: true,
+			user:                 func(user *domain.User) { user.EmailVerifiedAt = &verifiedAt },
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.sessions.EXPECT().DeleteSessionsByDevice(gomock.Any(), "u1", "device-1").Return(nil)
+				m.sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+				m.redis.EXPECT().SetRefreshToken(gomock.Any(), "u1", "device-1", gomock.Any(), 24*time.Hour).Return(nil)
+				m.metrics.EXPECT().RecordUserLogin(true, "")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			s.config.EmailVerificationRequired = tt.verificationRequired
+			user := testUser(t, password)
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil)
+			tt.setup(m)
+
+			got, err := s.Login(context.Background(), LoginInput{Email: user.Email, Password: tt.password, Device: device})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.NotErrorIs(t, err, app.ErrAccountLocked)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, got.Tokens)
+			assert.NotEmpty(t, got.Tokens.AccessToken)
+			assert.NotEmpty(t, got.Tokens.RefreshToken)
+		})
+	}
+
+	t.Run("unknown email", func(t *testing.T) {
+		s, m := newTestService(t)
+		m.users.EXPECT().GetUserByEmail(gomock.Any(), "nobody@example.com").Return(nil, domain.ErrUserNotFound)
+		m.metrics.EXPECT().RecordUserLogin(false, "user_not_found")
+
+		_, err := s.Login(context.Background(), LoginInput{Email: "nobody@example.com", Password: password, Device: device})
+		assert.ErrorIs(t, err, app.ErrInvalidCredentials)
+	})
+}
+
+func TestService_Register(t *testing.T) {
+	input := RegisterInput{Name: "Anna", Email: "anna@example.com", Password: "Kx9!vQ2#mZ", Locale: "en"}
+
+	tests := []struct {
+		name    string
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "registered and verification sent",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), input.Email).Return(nil, domain.ErrUserNotFound)
+				m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+				m.verifications.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+				m.metrics.EXPECT().RecordUserRegistration(true)
+			},
+		},
+		{
+			name: "email taken",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), input.Email).Return(&domain.User{ID: "u1"}, nil)
+				m.metrics.EXPECT().RecordUserRegistration(false)
+			},
+			wantErr: app.ErrUserExists,
+		},
+		{
+			name: "concurrent registration with same email",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), input.Email).Return(nil, domain.ErrUserNotFound)
+				m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(domain.ErrUserExists)
+				m.metrics.EXPECT().RecordUserRegistration(false)
+			},
+			wantErr: app.ErrUserExists,
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), input.Email).Return(nil, domain.ErrUserNotFound)
+				m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(errors.New("db unavailable"))
+				m.metrics.EXPECT().RecordUserRegistration(false)
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+
+			got, err := s.Register(context.Background(), input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, input.Email, got.Email)
+			assert.Equal(t, domain.UserStatusActive, got.Status)
+			assert.True(t, app.CheckPasswordHash(input.Password, got.PasswordHash))
+			assert.Nil(t, got.EmailVerifiedAt)
+		})
+	}
+}
+
+func TestService_RefreshTokens(t *testing.T) {
+	device := domain.DeviceInfo{DeviceID: "device-1"}
+	rotatedAt := time.Now().Add(-time.Minute)
+	bannedUntil := time.Now().Add(time.Hour)
+
+	refreshToken, err := signTestToken(jwt.MapClaims{
+		"user_id": "u1",
+		"sid":     "s1",
+		"type":    "refresh",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	accessToken, err := signTestToken(jwt.MapClaims{
+		"user_id": "u1",
+		"sid":     "s1",
+		"type":    "access",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	session := func() *domain.UserSession {
+		return &domain.UserSession{ID: "s1", UserID: "u1", FamilyID: "f1", DeviceID: "device-1", TokenHash: app.HashToken(refreshToken)}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		device  domain.DeviceInfo
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name:   "rotated within family",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleAdmin, Status: domain.UserStatusActive}, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				var newSessionID string
+				m.sessions.EXPECT().MarkSessionRotated(gomock.Any(), "s1", gomock.Any()).DoAndReturn(func(_ context.Context, _, replacedBy string) error {
+					newSessionID = replacedBy
+					return nil
+				})
+				m.sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, created *domain.UserSession) error {
+					assert.Equal(t, newSessionID, created.ID)
+					assert.Equal(t, "f1", created.FamilyID)
+					assert.Equal(t, "device-1", created.DeviceID)
+					return nil
+				})
+				m.redis.EXPECT().SetRefreshToken(gomock.Any(), "u1", "device-1", gomock.Any(), 24*time.Hour).Return(nil)
+			},
+		},
+		{
+			name:   "reuse of rotated token revokes family",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				rotated := session()
+				rotated.RotatedAt = &rotatedAt
+				rotated.ReplacedBy = "s2"
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(rotated, nil)
+				m.sessions.EXPECT().DeleteSessionsByFamily(gomock.Any(), "f1").Return(nil)
+				m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "device-1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), time.Hour).Return(nil)
+				m.securityEvents.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *domain.SecurityEvent) error {
+					assert.Equal(t, domain.SecurityEventRefreshTokenReuse, event.Type)
+					assert.Equal(t, "s2", event.Details["replaced_by"])
+					return nil
+				})
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Email: "anna@example.com"}, nil)
+				m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "concurrent rotation is reuse",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Status: domain.UserStatusActive}, nil).Times(2)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.sessions.EXPECT().MarkSessionRotated(gomock.Any(), "s1", gomock.Any()).Return(domain.ErrSessionRotated)
+				m.sessions.EXPECT().DeleteSessionsByFamily(gomock.Any(), "f1").Return(nil)
+				m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "device-1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), gomock.Any()).Return(nil)
+				m.securityEvents.EXPECT().CreateSecurityEvent(gomock.Any(), gomock.Any()).Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "session ended by concurrent logout is not reuse",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Status: domain.UserStatusActive}, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.sessions.EXPECT().MarkSessionRotated(gomock.Any(), "s1", gomock.Any()).Return(domain.ErrUserNotFound)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "token from another device",
+			token:  refreshToken,
+			device: domain.DeviceInfo{DeviceID: "device-2"},
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "token replaced in redis",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return("other", nil)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "user deleted",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:   "banned user",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Status: domain.UserStatusBanned, BannedUntil: &bannedUntil}, nil)
+			},
+			wantErr: app.ErrUserBanned,
+		},
+		{
+			name:   "inactive user",
+			token:  refreshToken,
+			device: device,
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s1").Return(session(), nil)
+				m.redis.EXPECT().GetRefreshToken(gomock.Any(), "u1", "device-1").Return(refreshToken, nil)
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Status: domain.UserStatusInactive}, nil)
+			},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:    "access token",
+			token:   accessToken,
+			device:  device,
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:    "malformed token",
+			token:   "not-a-jwt",
+			device:  device,
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+
+			got, err := s.RefreshTokens(context.Background(), RefreshTokensInput{RefreshToken: tt.token, Device: tt.device})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// Новый access токен несет актуальную роль из БД
+			claims := jwt.MapClaims{}
+			_, err = jwt.ParseWithClaims(got.AccessToken, claims, func(*jwt.Token) (interface{}, error) { return testSecret, nil })
+			require.NoError(t, err)
+			assert.Equal(t, "admin", claims["role"])
+			assert.NotEqual(t, "s1", claims["sid"])
+		})
+	}
+}
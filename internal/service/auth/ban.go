@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxBanReasonLength ограничение длины причины блокировки (соответствует колонке users.ban_reason)
+const maxBanReasonLength = 500
+
+// BanUserInput представляет входные данные для блокировки пользователя
+type BanUserInput struct {
+	UserID       string
+	BannedBy     string          // ID блокирующего
+	BannedByRole domain.UserRole // Роль блокирующего; администратора может заблокировать только admin
+	Reason       string
+	Until        *time.Time // nil - бессрочная блокировка
+}
+
+// BanUser блокирует пользователя и немедленно завершает все его сессии
+func (s *Service) BanUser(ctx context.Context, input BanUserInput) (*domain.User, error) {
+	now := time.Now()
+
+	if input.UserID == "" || len(input.Reason) > maxBanReasonLength {
+		return nil, app.ErrInvalidInput
+	}
+
+	if input.Until != nil && !input.Until.After(now) {
+		s.logger.Warn("Ban expiry is in the past", zap.String("user_id", input.UserID))
+		return nil, app.ErrInvalidInput
+	}
+
+	if input.UserID == input.BannedBy {
+		s.logger.Warn("Admin attempted to ban themselves", zap.String("user_id", input.UserID))
+		return nil, app.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get user for ban", zap.Error(err), zap.String("user_id", input.UserID))
+		return nil, app.ErrInternalServer
+	}
+
+	if !app.CanManageUser(input.BannedByRole, user) {
+		s.logger.Warn("Attempt to ban admin by non-admin", zap.String("user_id", user.ID), zap.String("banned_by", input.BannedBy))
+		return nil, app.ErrForbidden
+	}
+
+	// Снятие блокировки делает пользователя активным, поэтому блокировка не должна скрывать деактивацию
+	if user.Status == domain.UserStatusInactive {
+		s.logger.Warn("Attempt to ban inactive user", zap.String("user_id", user.ID))
+		return nil, app.ErrUserInactive
+	}
+
+	user.Status = domain.UserStatusBanned
+	user.BanReason = input.Reason
+	user.BannedUntil = input.Until
+	user.BannedBy = input.BannedBy
+	user.BannedAt = &now
+
+	// Меняем статус, удаляем сессии и отменяем выданные ссылки сброса пароля атомарно
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateUserStatus(ctx, user); err != nil {
+			return err
+		}
+		if err := s.sessionRepo.DeleteSessionsByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.passwordResetRepo.CancelPasswordResets(ctx, user.ID)
+	})
+	if err != nil {
+		s.logger.Error("Failed to ban user", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	// Отметка в Redis отсекает еще не истекшие access токены в JWTAuth
+	var ttl time.Duration
+	if input.Until != nil {
+		ttl = input.Until.Sub(now)
+	}
+	if err := s.redisRepo.SetUserBan(ctx, user.ID, ttl); err != nil {
+		s.logger.Error("Failed to mark user banned in Redis", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.DeleteAllUserRefreshTokens(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to delete refresh tokens of banned user", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	// Токены, выданные до блокировки, не должны заработать снова после разблокировки
+	if err := s.invalidateAccessTokens(ctx, user.ID, time.Now()); err != nil {
+		return nil, app.ErrInternalServer
+	}
+
+	s.logger.Info("User banned",
+		zap.String("user_id", user.ID),
+		zap.String("banned_by", input.BannedBy),
+		zap.Timep("banned_until", input.Until),
+	)
+	return user, nil
+}
+
+// UnbanUser снимает блокировку с пользователя; для незаблокированного пользователя ничего не меняет.
+// Блокировку администратора снимает только администратор
+func (s *Service) UnbanUser(ctx context.Context, userID string, unbannedByRole domain.UserRole) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get user for unban", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	if !app.CanManageUser(unbannedByRole, user) {
+		s.logger.Warn("Attempt to unban admin by non-admin", zap.String("user_id", userID))
+		return nil, app.ErrForbidden
+	}
+
+	if user.Status != domain.UserStatusBanned {
+		return user, nil
+	}
+
+	user.Status = domain.UserStatusActive
+	user.BanReason = ""
+	user.BannedUntil = nil
+	user.BannedBy = ""
+	user.BannedAt = nil
+
+	if err := s.userRepo.UpdateUserStatus(ctx, user); err != nil {
+		s.logger.Error("Failed to unban user", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.DeleteUserBan(ctx, userID); err != nil {
+		s.logger.Error("Failed to delete user ban from Redis", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	s.logger.Info("User unbanned", zap.String("user_id", userID))
+	return user, nil
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_BanUser(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+
+	// expectBan ожидает смену статуса, удаление сессий и отзыв токенов пользователя u1
+	expectBan := func(m testMocks) {
+		m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+		m.users.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Return(nil)
+		m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+		m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(nil)
+		m.redis.EXPECT().SetUserBan(gomock.Any(), "u1", gomock.Any()).Return(nil)
+		m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+		m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), gomock.Any()).Return(nil)
+	}
+
+	tests := []struct {
+		name    string
+		input   BanUserInput
+		target  domain.UserRole
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name:   "non-admin bans user",
+			input:  BanUserInput{UserID: "u1", BannedBy: "moderator", BannedByRole: domain.UserRoleUser, Reason: "spam", Until: &until},
+			target: domain.UserRoleUser,
+			setup:  expectBan,
+		},
+		{
+			name:   "admin bans admin",
+			input:  BanUserInput{UserID: "u1", BannedBy: "admin", BannedByRole: domain.UserRoleAdmin},
+			target: domain.UserRoleAdmin,
+			setup:  expectBan,
+		},
+		{
+			name:    "non-admin bans admin",
+			input:   BanUserInput{UserID: "u1", BannedBy: "moderator", BannedByRole: domain.UserRoleUser},
+			target:  domain.UserRoleAdmin,
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:    "self ban",
+			input:   BanUserInput{UserID: "u1", BannedBy: "u1", BannedByRole: domain.UserRoleAdmin},
+			wantErr: app.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			if tt.setup != nil {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: tt.target, Status: domain.UserStatusActive}, nil)
+				tt.setup(m)
+			}
+
+			got, err := s.BanUser(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, domain.UserStatusBanned, got.Status)
+			assert.Equal(t, tt.input.BannedBy, got.BannedBy)
+		})
+	}
+}
+
+func TestService_BanUser_InactiveUser(t *testing.T) {
+	s, m := newTestService(t)
+	user := &domain.User{ID: "u1", Role: domain.UserRoleUser, Status: domain.UserStatusInactive}
+	m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil).Times(2)
+
+	_, err := s.BanUser(context.Background(), BanUserInput{UserID: "u1", BannedBy: "admin", BannedByRole: domain.UserRoleAdmin})
+	assert.ErrorIs(t, err, app.ErrUserInactive)
+
+	// Блокировка не состоялась, поэтому снятие блокировки не активирует пользователя
+	got, err := s.UnbanUser(context.Background(), "u1", domain.UserRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusInactive, got.Status)
+}
+
+func TestService_UnbanUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		stored     domain.User
+		setup      func(m testMocks)
+		wantStatus domain.UserStatus
+		wantErr    error
+	}{
+		{
+			name:   "non-admin unbans user",
+			role:   domain.UserRoleUser,
+			stored: domain.User{ID: "u1", Role: domain.UserRoleUser, Status: domain.UserStatusBanned, BanReason: "spam"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Return(nil)
+				m.redis.EXPECT().DeleteUserBan(gomock.Any(), "u1").Return(nil)
+			},
+			wantStatus: domain.UserStatusActive,
+		},
+		{
+			name:   "admin unbans admin",
+			role:   domain.UserRoleAdmin,
+			stored: domain.User{ID: "u1", Role: domain.UserRoleAdmin, Status: domain.UserStatusBanned},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Return(nil)
+				m.redis.EXPECT().DeleteUserBan(gomock.Any(), "u1").Return(nil)
+			},
+			wantStatus: domain.UserStatusActive,
+		},
+		{
+			name:    "non-admin unbans admin",
+			role:    domain.UserRoleUser,
+			stored:  domain.User{ID: "u1", Role: domain.UserRoleAdmin, Status: domain.UserStatusBanned},
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:       "not banned",
+			role:       domain.UserRoleUser,
+			stored:     domain.User{ID: "u1", Role: domain.UserRoleUser, Status: domain.UserStatusActive},
+			setup:      func(m testMocks) {},
+			wantStatus: domain.UserStatusActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			stored := tt.stored
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&stored, nil)
+			tt.setup(m)
+
+			got, err := s.UnbanUser(context.Background(), "u1", tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Empty(t, got.BanReason)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ChangePasswordInput представляет входные данные для смены пароля авторизованным пользователем
+type ChangePasswordInput struct {
+	UserID              string
+	CurrentPassword     string
+	NewPassword         string
+	RevokeOtherSessions bool              // Завершить сессии на всех устройствах, кроме текущего
+	Device              domain.DeviceInfo // Текущее устройство, получает новую пару токенов при RevokeOtherSessions
+	Locale              string            // Язык уведомления
+}
+
+// ChangePassword меняет пароль после проверки текущего. При RevokeOtherSessions завершает все сессии
+// и возвращает новую пару токенов для текущего устройства, иначе возвращает nil
+func (s *Service) ChangePassword(ctx context.Context, input ChangePasswordInput) (*domain.AuthTokens, error) {
+	user, err := s.GetCurrentUser(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Текущий пароль защищен той же блокировкой, что и вход: украденный access токен не дает его перебирать
+	if isLocked(user, time.Now()) {
+		s.logger.Warn("Locked user attempted to change password", zap.String("user_id", user.ID))
+		return nil, &LockedError{Until: *user.LockedUntil}
+	}
+
+	if err := s.passwordPolicy.Validate(input.NewPassword, user.Email, user.Name); err != nil {
+		s.logger.Warn("Password does not meet policy", zap.String("user_id", user.ID))
+		return nil, err
+	}
+
+	if !app.CheckPasswordHash(input.CurrentPassword, user.PasswordHash) {
+		s.logger.Warn("Invalid current password for password change", zap.String("user_id", user.ID))
+		s.registerFailedLogin(ctx, user)
+		return nil, app.ErrInvalidCredentials
+	}
+
+	s.resetFailedLogins(ctx, user)
+
+	if err := s.checkPasswordReuse(ctx, user, input.NewPassword); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := app.HashPassword(input.NewPassword)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	// Сохраняем прежний пароль в историю, меняем пароль и при необходимости удаляем сессии атомарно
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.savePasswordHistory(ctx, user); err != nil {
+			return err
+		}
+		if err := s.userRepo.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+			return err
+		}
+		if !input.RevokeOtherSessions {
+			return nil
+		}
+		return s.sessionRepo.DeleteSessionsByUserID(ctx, user.ID)
+	})
+	if err != nil {
+		s.logger.Error("Failed to change password", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	s.sendSecurityAlert(ctx, user, securityEventPasswordChanged, input.Locale)
+
+	if !input.RevokeOtherSessions {
+		s.logger.Info("Password changed", zap.String("user_id", user.ID))
+		return nil, nil
+	}
+
+	// Отзываем токены всех устройств, затем выдаем текущему новую сессию. Отзываются токены, выданные
+	// не позже отметки с точностью до миллисекунды, поэтому новый access токен выдается строго после нее
+	if err := s.redisRepo.DeleteAllUserRefreshTokens(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to revoke refresh tokens after password change", zap.Error(err), zap.String("user_id", user.ID))
+	}
+	revokedAt := time.Now()
+	if err := s.invalidateAccessTokens(ctx, user.ID, revokedAt); err != nil {
+		s.logger.Error("Access tokens stay valid until expiry after password change", zap.String("user_id", user.ID))
+	}
+
+	tokens, err := s.issueSession(ctx, user, input.Device, revokedAt)
+	if err != nil {
+		s.logger.Error("Password changed but current device session was not reissued", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, err
+	}
+
+	s.logger.Info("Password changed, other sessions revoked", zap.String("user_id", user.ID))
+	return tokens, nil
+}
+
+// checkPasswordReuse запрещает новый пароль, совпадающий с текущим или одним из PASSWORD_HISTORY_SIZE последних
+func (s *Service) checkPasswordReuse(ctx context.Context, user *domain.User, password string) error {
+	size := s.config.PasswordHistorySize
+	if size <= 0 {
+		return nil
+	}
+
+	if app.CheckPasswordHash(password, user.PasswordHash) {
+		s.logger.Warn("New password matches current password", zap.String("user_id", user.ID))
+		return app.ErrPasswordReused
+	}
+
+	if size == 1 {
+		return nil
+	}
+
+	hashes, err := s.passwordHistory.GetPasswordHistory(ctx, user.ID, size-1)
+	if err != nil {
+		s.logger.Error("Failed to get password history", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	for _, hash := range hashes {
+		if app.CheckPasswordHash(password, hash) {
+			s.logger.Warn("New password matches a previous password", zap.String("user_id", user.ID))
+			return app.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// savePasswordHistory сохраняет текущий пароль пользователя в историю перед его заменой
+func (s *Service) savePasswordHistory(ctx context.Context, user *domain.User) error {
+	// Текущий пароль проверяется отдельно, в истории хранятся только предыдущие
+	keep := s.config.PasswordHistorySize - 1
+	if keep <= 0 {
+		return nil
+	}
+	return s.passwordHistory.AddPasswordHistory(ctx, user.ID, user.PasswordHash, keep)
+}
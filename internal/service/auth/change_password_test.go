@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// accessTokenIssuedAt возвращает iat access токена с точностью до миллисекунды
+func accessTokenIssuedAt(t *testing.T, token string) int64 {
+	t.Helper()
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) { return testSecret, nil })
+	require.NoError(t, err)
+	iat, ok := claims["iat"].(float64)
+	require.True(t, ok)
+	return int64(math.Round(iat * 1000))
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	const (
+		currentPassword = "Kx9!vQ2#mZ"
+		newPassword     = "Pq7@wL4$nR"
+	)
+	device := domain.DeviceInfo{DeviceID: "device-1", AppType: "web"}
+	valid := ChangePasswordInput{UserID: "u1", CurrentPassword: currentPassword, NewPassword: newPassword, Device: device, Locale: "en"}
+	with := func(change func(input *ChangePasswordInput)) ChangePasswordInput {
+		input := valid
+		change(&input)
+		return input
+	}
+	previousHash, err := app.HashPassword(newPassword)
+	require.NoError(t, err)
+
+	// expectPasswordSaved ожидает сохранение прежнего пароля в историю и запись нового
+	expectPasswordSaved := func(m testMocks, user *domain.User) {
+		m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return(nil, nil)
+		m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+		m.history.EXPECT().AddPasswordHistory(gomock.Any(), "u1", user.PasswordHash, 2).Return(nil)
+		m.users.EXPECT().UpdatePassword(gomock.Any(), "u1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, hash string) error {
+				assert.True(t, app.CheckPasswordHash(newPassword, hash))
+				return nil
+			})
+	}
+
+	tests := []struct {
+		name       string
+		input      ChangePasswordInput
+		setup      func(m testMocks, user *domain.User)
+		wantTokens bool
+		wantErr    error
+	}{
+		{
+			name:  "other sessions kept",
+			input: valid,
+			setup: func(m testMocks, user *domain.User) {
+				expectPasswordSaved(m, user)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateSecurityAlert, "anna@example.com")).Return(nil)
+			},
+		},
+		{
+			name:  "other sessions revoked and current device reissued",
+			input: with(func(input *ChangePasswordInput) { input.RevokeOtherSessions = true }),
+			setup: func(m testMocks, user *domain.User) {
+				expectPasswordSaved(m, user)
+				m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateSecurityAlert, "anna@example.com")).Return(nil)
+				m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), time.Hour).Return(nil)
+				expectIssueSession(m)
+			},
+			wantTokens: true,
+		},
+		{
+			name:  "failed logins reset after correct password",
+			input: valid,
+			setup: func(m testMocks, user *domain.User) {
+				user.FailedLoginAttempts = 2
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+				expectPasswordSaved(m, user)
+				m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "wrong current password counts failure",
+			input: with(func(input *ChangePasswordInput) { input.CurrentPassword = "wrong-password" }),
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:  "wrong current password locks account",
+			input: with(func(input *ChangePasswordInput) { input.CurrentPassword = "wrong-password" }),
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(5, nil)
+				m.users.EXPECT().LockUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:  "locked account refused even with correct password",
+			input: valid,
+			setup: func(m testMocks, user *domain.User) {
+				lockedUntil := time.Now().Add(time.Minute)
+				user.LockedUntil = &lockedUntil
+			},
+			wantErr: app.ErrAccountLocked,
+		},
+		{
+			name:    "weak new password",
+			input:   with(func(input *ChangePasswordInput) { input.NewPassword = "short" }),
+			wantErr: app.ErrWeakPassword,
+		},
+		{
+			name:  "previous password reused",
+			input: valid,
+			setup: func(m testMocks, user *domain.User) {
+				m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return([]string{previousHash}, nil)
+			},
+			wantErr: app.ErrPasswordReused,
+		},
+		{
+			name:  "storage error",
+			input: valid,
+			setup: func(m testMocks, user *domain.User) {
+				m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return(nil, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.history.EXPECT().AddPasswordHistory(gomock.Any(), "u1", user.PasswordHash, 2).Return(nil)
+				m.users.EXPECT().UpdatePassword(gomock.Any(), "u1", gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, currentPassword)
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m, user)
+			}
+
+			tokens, err := s.ChangePassword(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, tokens)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.wantTokens {
+				assert.Nil(t, tokens)
+				return
+			}
+			require.NotNil(t, tokens)
+			assert.NotEmpty(t, tokens.AccessToken)
+			assert.NotEmpty(t, tokens.RefreshToken)
+		})
+	}
+
+	t.Run("reissued token is not revoked by the watermark", func(t *testing.T) {
+		s, m := newTestService(t)
+		user := testUser(t, currentPassword)
+		m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+		expectPasswordSaved(m, user)
+		m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+		m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+		m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+		var validAfter time.Time
+		m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), time.Hour).
+			DoAndReturn(func(_ context.Context, _ string, at time.Time, _ time.Duration) error {
+				validAfter = at
+				return nil
+			})
+		expectIssueSession(m)
+
+		tokens, err := s.ChangePassword(context.Background(), with(func(input *ChangePasswordInput) { input.RevokeOtherSessions = true }))
+		require.NoError(t, err)
+		assert.Greater(t, accessTokenIssuedAt(t, tokens.AccessToken), validAfter.UnixMilli())
+	})
+}
+
+func TestService_generateTokens(t *testing.T) {
+	ahead := time.Now().Add(time.Second)
+
+	tests := []struct {
+		name      string
+		revokedAt time.Time
+		want      func(now time.Time) int64
+	}{
+		{
+			name: "no revocation",
+			want: func(now time.Time) int64 { return now.UnixMilli() },
+		},
+		{
+			name:      "revocation in the past",
+			revokedAt: time.Now().Add(-time.Second),
+			want:      func(now time.Time) int64 { return now.UnixMilli() },
+		},
+		{
+			// Отметка отзыва сравнивается включительно, поэтому токен выдается в следующую миллисекунду
+			name:      "revocation not earlier than now",
+			revokedAt: ahead,
+			want:      func(time.Time) int64 { return ahead.UnixMilli() + 1 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+
+			now := time.Now()
+			tokens, err := s.generateTokens("u1", "s1", domain.UserRoleUser, tt.revokedAt)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want(now), accessTokenIssuedAt(t, tokens.AccessToken), 50)
+		})
+	}
+}
+
+func TestService_checkPasswordReuse(t *testing.T) {
+	const (
+		currentPassword = "Kx9!vQ2#mZ"
+		newPassword     = "Pq7@wL4$nR"
+	)
+	previousHash, err := app.HashPassword(newPassword)
+	require.NoError(t, err)
+	otherHash, err := app.HashPassword("Zx3%tY8&bN")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		size     int
+		password string
+		setup    func(m testMocks)
+		wantErr  error
+	}{
+		{name: "check disabled", size: 0, password: currentPassword},
+		{name: "current password", size: 3, password: currentPassword, wantErr: app.ErrPasswordReused},
+		{name: "only current password checked", size: 1, password: newPassword},
+		{
+			name:     "previous password",
+			size:     3,
+			password: newPassword,
+			setup: func(m testMocks) {
+				m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return([]string{otherHash, previousHash}, nil)
+			},
+			wantErr: app.ErrPasswordReused,
+		},
+		{
+			name:     "new password",
+			size:     3,
+			password: newPassword,
+			setup: func(m testMocks) {
+				m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return([]string{otherHash}, nil)
+			},
+		},
+		{
+			name:     "history unavailable",
+			size:     3,
+			password: newPassword,
+			setup: func(m testMocks) {
+				m.history.EXPECT().GetPasswordHistory(gomock.Any(), "u1", 2).Return(nil, errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			s.config.PasswordHistorySize = tt.size
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.checkPasswordReuse(context.Background(), testUser(t, currentPassword), tt.password)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ChangeEmailInput представляет входные данные для запроса смены email
+type ChangeEmailInput struct {
+	UserID   string `json:"user_id"`
+	Password string `json:"password"`
+	NewEmail string `json:"new_email"`
+	Locale   string `json:"locale"` // Язык писем
+}
+
+// RequestEmailChange проверяет пароль и отправляет ссылку подтверждения на новый адрес, а уведомление со ссылкой отмены - на текущий.
+// Email меняется только после подтверждения с нового адреса
+func (s *Service) RequestEmailChange(ctx context.Context, input ChangeEmailInput) error {
+	newEmail := strings.TrimSpace(input.NewEmail)
+	if !app.ValidateEmail(newEmail) {
+		s.logger.Warn("Invalid email format", zap.String("email", newEmail))
+		return app.ErrInvalidInput
+	}
+
+	user, err := s.GetCurrentUser(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Пароль защищен той же блокировкой, что и вход: украденный access токен не дает его перебирать
+	if isLocked(user, time.Now()) {
+		s.logger.Warn("Locked user attempted to change email", zap.String("user_id", user.ID))
+		return &LockedError{Until: *user.LockedUntil}
+	}
+
+	if !app.CheckPasswordHash(input.Password, user.PasswordHash) {
+		s.logger.Warn("Invalid password for email change", zap.String("user_id", user.ID))
+		s.registerFailedLogin(ctx, user)
+		return app.ErrInvalidCredentials
+	}
+
+	s.resetFailedLogins(ctx, user)
+
+	if strings.EqualFold(newEmail, user.Email) {
+		s.logger.Warn("New email matches current email", zap.String("user_id", user.ID))
+		return app.ErrInvalidInput
+	}
+
+	// Окончательно уникальность проверяется при смене, здесь отсекаем заведомо занятые адреса
+	if _, err := s.userRepo.GetUserByEmail(ctx, newEmail); err == nil {
+		s.logger.Warn("Email change to existing email", zap.String("user_id", user.ID))
+		return app.ErrEmailInUse
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		s.logger.Error("Failed to check new email", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	token, err := app.GenerateRandomToken(app.EmailChangeTokenLength)
+	if err != nil {
+		s.logger.Error("Failed to generate email change token", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+	cancelToken, err := app.GenerateRandomToken(app.EmailChangeTokenLength)
+	if err != nil {
+		s.logger.Error("Failed to generate email change cancel token", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	now := time.Now()
+	change := &domain.EmailChange{
+		ID:              app.GenerateUUID(),
+		UserID:          user.ID,
+		NewEmail:        newEmail,
+		TokenHash:       app.HashToken(token),
+		CancelTokenHash: app.HashToken(cancelToken),
+		ExpiresAt:       now.Add(s.config.EmailChangeExpiration),
+		CreatedAt:       now,
+	}
+
+	// Действует только последний запрос: ссылки из предыдущих писем перестают работать
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.emailChangeRepo.CancelEmailChanges(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.emailChangeRepo.CreateEmailChange(ctx, change)
+	})
+	if err != nil {
+		s.logger.Error("Failed to create email change", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	baseURL := strings.TrimRight(s.config.AppBaseURL, "/")
+	expiresHours := max(int(s.config.EmailChangeExpiration.Hours()), 1)
+
+	recipient := *user
+	recipient.Email = newEmail
+	s.sendEmail(ctx, &recipient, domain.EmailTemplateEmailChange, input.Locale, map[string]any{
+		"ConfirmURL":   fmt.Sprintf("%s/confirm-email-change?token=%s", baseURL, url.QueryEscape(token)),
+		"ExpiresHours": expiresHours,
+	})
+	s.sendEmail(ctx, user, domain.EmailTemplateEmailNotice, input.Locale, map[string]any{
+		"NewEmail":  newEmail,
+		"CancelURL": fmt.Sprintf("%s/cancel-email-change?token=%s", baseURL, url.QueryEscape(cancelToken)),
+	})
+
+	s.logger.Info("Email change requested", zap.String("user_id", user.ID), zap.String("change_id", change.ID))
+	return nil
+}
+
+// ConfirmEmailChange меняет email пользователя по токену из письма на новый адрес
+func (s *Service) ConfirmEmailChange(ctx context.Context, token, locale string) error {
+	change, err := s.getActiveEmailChange(ctx, token, s.emailChangeRepo.GetEmailChangeByTokenHash)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, change.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get user for email change", zap.Error(err), zap.String("user_id", change.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Помечаем запрос использованным и меняем email атомарно.
+	// Адрес мог быть занят после создания запроса: нарушение уникальности откатывает транзакцию.
+	// Ссылки сброса пароля и подтверждения, отправленные на прежний адрес, перестают действовать
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.emailChangeRepo.MarkEmailChangeAsUsed(ctx, change.ID); err != nil {
+			return err
+		}
+		if err := s.userRepo.UpdateUserEmail(ctx, change.UserID, change.NewEmail); err != nil {
+			return err
+		}
+		if err := s.passwordResetRepo.CancelPasswordResets(ctx, change.UserID); err != nil {
+			return err
+		}
+		return s.verificationRepo.DeleteEmailVerifications(ctx, change.UserID)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmailChangeUsed):
+			s.logger.Warn("Email change token used concurrently", zap.String("change_id", change.ID))
+			return app.ErrEmailChangeUsed
+		case errors.Is(err, domain.ErrUserExists):
+			s.logger.Warn("New email was taken before confirmation", zap.String("change_id", change.ID))
+			return app.ErrEmailInUse
+		}
+		s.logger.Error("Failed to change email", zap.Error(err), zap.String("user_id", change.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Уведомление уходит на прежний адрес: user загружен до смены
+	s.sendSecurityAlert(ctx, user, securityEventEmailChanged, locale)
+
+	s.logger.Info("Email changed", zap.String("user_id", change.UserID), zap.String("change_id", change.ID))
+	return nil
+}
+
+// CancelEmailChange отменяет запрос на смену email по токену из уведомления на текущий адрес
+func (s *Service) CancelEmailChange(ctx context.Context, token string) error {
+	change, err := s.getActiveEmailChange(ctx, token, s.emailChangeRepo.GetEmailChangeByCancelTokenHash)
+	if err != nil {
+		return err
+	}
+
+	if err := s.emailChangeRepo.MarkEmailChangeAsUsed(ctx, change.ID); err != nil {
+		if errors.Is(err, domain.ErrEmailChangeUsed) {
+			return app.ErrEmailChangeUsed
+		}
+		s.logger.Error("Failed to cancel email change", zap.Error(err), zap.String("change_id", change.ID))
+		return app.ErrInternalServer
+	}
+
+	s.logger.Info("Email change cancelled", zap.String("user_id", change.UserID), zap.String("change_id", change.ID))
+	return nil
+}
+
+// getActiveEmailChange находит неиспользованный и неистекший запрос на смену email по токену
+func (s *Service) getActiveEmailChange(ctx context.Context, token string, get func(ctx context.Context, tokenHash string) (*domain.EmailChange, error)) (*domain.EmailChange, error) {
+	if token == "" {
+		return nil, app.ErrInvalidInput
+	}
+
+	change, err := get(ctx, app.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.Warn("Email change token not found")
+			return nil, app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get email change", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	if change.Used {
+		s.logger.Warn("Email change token already used", zap.String("change_id", change.ID))
+		return nil, app.ErrEmailChangeUsed
+	}
+
+	if app.IsExpired(change.ExpiresAt) {
+		s.logger.Warn("Email change token expired", zap.String("change_id", change.ID))
+		return nil, app.ErrEmailChangeExpired
+	}
+
+	return change, nil
+}
@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_RequestEmailChange(t *testing.T) {
+	const password = "Kx9!vQ2#mZ"
+	lockedUntil := time.Now().Add(time.Minute)
+	valid := ChangeEmailInput{UserID: "u1", Password: password, NewEmail: " maria@example.com ", Locale: "en"}
+	with := func(change func(input *ChangeEmailInput)) ChangeEmailInput {
+		input := valid
+		change(&input)
+		return input
+	}
+
+	tests := []struct {
+		name    string
+		input   ChangeEmailInput
+		user    func(user *domain.User)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name:  "cancels previous requests and sends both emails",
+			input: valid,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), "maria@example.com").Return(nil, domain.ErrUserNotFound)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				gomock.InOrder(
+					m.emailChanges.EXPECT().CancelEmailChanges(gomock.Any(), "u1").Return(nil),
+					m.emailChanges.EXPECT().CreateEmailChange(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, change *domain.EmailChange) error {
+							assert.Equal(t, "maria@example.com", change.NewEmail)
+							assert.NotEqual(t, change.TokenHash, change.CancelTokenHash)
+							return nil
+						}),
+				)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateEmailChange, "maria@example.com")).Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateEmailNotice, "anna@example.com")).Return(nil)
+			},
+		},
+		{
+			name:  "wrong password counts failure",
+			input: with(func(input *ChangeEmailInput) { input.Password = "wrong-password" }),
+			setup: func(m testMocks) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:  "wrong password locks account",
+			input: with(func(input *ChangeEmailInput) { input.Password = "wrong-password" }),
+			setup: func(m testMocks) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(5, nil)
+				m.users.EXPECT().LockUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:    "locked account refused even with correct password",
+			input:   valid,
+			user:    func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			wantErr: app.ErrAccountLocked,
+		},
+		{
+			name:  "failed logins reset after correct password",
+			input: with(func(input *ChangeEmailInput) { input.NewEmail = "Anna@Example.com" }),
+			user:  func(user *domain.User) { user.FailedLoginAttempts = 2 },
+			setup: func(m testMocks) {
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+			},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "same email",
+			input:   with(func(input *ChangeEmailInput) { input.NewEmail = "Anna@Example.com" }),
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:  "email taken",
+			input: valid,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), "maria@example.com").Return(&domain.User{ID: "u2"}, nil)
+			},
+			wantErr: app.ErrEmailInUse,
+		},
+		{
+			name:  "storage error",
+			input: valid,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByEmail(gomock.Any(), "maria@example.com").Return(nil, domain.ErrUserNotFound)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.emailChanges.EXPECT().CancelEmailChanges(gomock.Any(), "u1").Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, password)
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.RequestEmailChange(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("invalid email", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.RequestEmailChange(context.Background(), with(func(input *ChangeEmailInput) { input.NewEmail = "maria" }))
+		assert.ErrorIs(t, err, app.ErrInvalidInput)
+	})
+}
+
+func TestService_ConfirmEmailChange(t *testing.T) {
+	const token = "confirm-token"
+
+	tests := []struct {
+		name    string
+		change  func(change *domain.EmailChange)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "changes email and alerts previous address",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(testUser(t, "Kx9!vQ2#mZ"), nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(nil)
+				m.users.EXPECT().UpdateUserEmail(gomock.Any(), "u1", "maria@example.com").Return(nil)
+				m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(nil)
+				m.verifications.EXPECT().DeleteEmailVerifications(gomock.Any(), "u1").Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateSecurityAlert, "anna@example.com")).Return(nil)
+			},
+		},
+		{
+			name: "pending links cleanup fails",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(testUser(t, "Kx9!vQ2#mZ"), nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(nil)
+				m.users.EXPECT().UpdateUserEmail(gomock.Any(), "u1", "maria@example.com").Return(nil)
+				m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name: "email taken before confirmation",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(testUser(t, "Kx9!vQ2#mZ"), nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(nil)
+				m.users.EXPECT().UpdateUserEmail(gomock.Any(), "u1", "maria@example.com").Return(domain.ErrUserExists)
+			},
+			wantErr: app.ErrEmailInUse,
+		},
+		{
+			name: "token used concurrently",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(testUser(t, "Kx9!vQ2#mZ"), nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(domain.ErrEmailChangeUsed)
+			},
+			wantErr: app.ErrEmailChangeUsed,
+		},
+		{
+			name:    "token already used or cancelled",
+			change:  func(change *domain.EmailChange) { change.Used = true },
+			wantErr: app.ErrEmailChangeUsed,
+		},
+		{
+			name:    "token expired",
+			change:  func(change *domain.EmailChange) { change.ExpiresAt = time.Now().Add(-time.Minute) },
+			wantErr: app.ErrEmailChangeExpired,
+		},
+		{
+			name: "user deleted",
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			change := &domain.EmailChange{
+				ID:        "c1",
+				UserID:    "u1",
+				NewEmail:  "maria@example.com",
+				TokenHash: app.HashToken(token),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}
+			if tt.change != nil {
+				tt.change(change)
+			}
+			m.emailChanges.EXPECT().GetEmailChangeByTokenHash(gomock.Any(), app.HashToken(token)).Return(change, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.ConfirmEmailChange(context.Background(), token, "en")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		s, m := newTestService(t)
+		m.emailChanges.EXPECT().GetEmailChangeByTokenHash(gomock.Any(), app.HashToken(token)).Return(nil, domain.ErrUserNotFound)
+
+		err := s.ConfirmEmailChange(context.Background(), token, "en")
+		assert.ErrorIs(t, err, app.ErrInvalidToken)
+	})
+}
+
+func TestService_ConfirmEmailChange_RevokesOldAddressResets(t *testing.T) {
+	const token = "confirm-token"
+	s, m := newTestService(t)
+	change := &domain.EmailChange{ID: "c1", UserID: "u1", NewEmail: "maria@example.com", TokenHash: app.HashToken(token), ExpiresAt: time.Now().Add(time.Hour)}
+	// Ссылка на сброс пароля, отправленная на прежний адрес до смены
+	reset := &domain.PasswordReset{ID: "r1", UserID: "u1", TokenHash: app.HashToken("reset-token"), ExpiresAt: time.Now().Add(time.Hour)}
+
+	m.emailChanges.EXPECT().GetEmailChangeByTokenHash(gomock.Any(), app.HashToken(token)).Return(change, nil)
+	m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(testUser(t, "Kx9!vQ2#mZ"), nil)
+	m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+	m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(nil)
+	m.users.EXPECT().UpdateUserEmail(gomock.Any(), "u1", "maria@example.com").Return(nil)
+	m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").DoAndReturn(func(context.Context, string) error {
+		reset.Used = true
+		return nil
+	})
+	m.verifications.EXPECT().DeleteEmailVerifications(gomock.Any(), "u1").Return(nil)
+	m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+	require.NoError(t, s.ConfirmEmailChange(context.Background(), token, "en"))
+
+	m.resets.EXPECT().GetPasswordResetByTokenHash(gomock.Any(), app.HashToken("reset-token")).Return(reset, nil)
+	err := s.ConfirmPasswordReset(context.Background(), ConfirmPasswordResetInput{Token: "reset-token", NewPassword: "Pq7@wL4$nR"})
+	assert.ErrorIs(t, err, app.ErrPasswordResetUsed)
+}
+
+func TestService_CancelEmailChange(t *testing.T) {
+	const token = "cancel-token"
+
+	tests := []struct {
+		name    string
+		change  func(change *domain.EmailChange)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "cancelled",
+			setup: func(m testMocks) {
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(nil)
+			},
+		},
+		{
+			name: "confirmed concurrently",
+			setup: func(m testMocks) {
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(domain.ErrEmailChangeUsed)
+			},
+			wantErr: app.ErrEmailChangeUsed,
+		},
+		{
+			name:    "already confirmed",
+			change:  func(change *domain.EmailChange) { change.Used = true },
+			wantErr: app.ErrEmailChangeUsed,
+		},
+		{
+			name:    "token expired",
+			change:  func(change *domain.EmailChange) { change.ExpiresAt = time.Now().Add(-time.Minute) },
+			wantErr: app.ErrEmailChangeExpired,
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.emailChanges.EXPECT().MarkEmailChangeAsUsed(gomock.Any(), "c1").Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			change := &domain.EmailChange{
+				ID:              "c1",
+				UserID:          "u1",
+				NewEmail:        "maria@example.com",
+				CancelTokenHash: app.HashToken(token),
+				ExpiresAt:       time.Now().Add(time.Hour),
+			}
+			if tt.change != nil {
+				tt.change(change)
+			}
+			m.emailChanges.EXPECT().GetEmailChangeByCancelTokenHash(gomock.Any(), app.HashToken(token)).Return(change, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.CancelEmailChange(context.Background(), token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("empty token", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.CancelEmailChange(context.Background(), "")
+		assert.ErrorIs(t, err, app.ErrInvalidInput)
+	})
+}
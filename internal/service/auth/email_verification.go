@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ResendVerificationInput представляет входные данные для повторной отправки письма подтверждения
+type ResendVerificationInput struct {
+	Email  string `json:"email"`
+	Locale string `json:"locale"` // Язык письма
+}
+
+// VerifyEmail подтверждает email пользователя по токену из письма
+func (s *Service) VerifyEmail(ctx context.Context, token, locale string) error {
+	if token == "" {
+		return app.ErrInvalidInput
+	}
+
+	verification, err := s.verificationRepo.GetEmailVerificationByTokenHash(ctx, app.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.Warn("Email verification token not found")
+			return app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get email verification", zap.Error(err))
+		return app.ErrInternalServer
+	}
+
+	if verification.Used {
+		s.logger.Warn("Email verification token already used", zap.String("verification_id", verification.ID))
+		return app.ErrVerificationUsed
+	}
+
+	if app.IsExpired(verification.ExpiresAt) {
+		s.logger.Warn("Email verification token expired", zap.String("verification_id", verification.ID))
+		return app.ErrVerificationExpired
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, verification.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get user for email verification", zap.Error(err), zap.String("user_id", verification.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Письмо подтверждает только адрес, на который было отправлено: после смены email старый токен недействителен
+	if verification.Email != user.Email {
+		s.logger.Warn("Email verification token issued for previous email", zap.String("verification_id", verification.ID))
+		return app.ErrInvalidToken
+	}
+
+	// Помечаем токен использованным и подтверждаем email атомарно
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.verificationRepo.MarkEmailVerificationAsUsed(ctx, verification.ID); err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		return s.userRepo.MarkEmailVerified(ctx, user.ID, time.Now())
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailVerificationUsed) {
+			s.logger.Warn("Email verification token used concurrently", zap.String("verification_id", verification.ID))
+			return app.ErrVerificationUsed
+		}
+		s.logger.Error("Failed to verify email", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	// Приветствие отправляем один раз, после первого подтверждения
+	if user.EmailVerifiedAt == nil {
+		s.sendWelcomeEmail(ctx, user, locale)
+	}
+
+	s.logger.Info("Email verified", zap.String("user_id", user.ID))
+	return nil
+}
+
+// ResendVerificationEmail повторно отправляет письмо подтверждения.
+// Результат не зависит от существования аккаунта, чтобы не раскрывать зарегистрированные адреса
+func (s *Service) ResendVerificationEmail(ctx context.Context, input ResendVerificationInput) error {
+	if !app.ValidateEmail(input.Email) {
+		s.logger.Warn("Invalid email format", zap.String("email", input.Email))
+		return app.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		s.logger.Debug("User not found for email verification resend", zap.String("email", input.Email))
+		return nil
+	}
+
+	if user.EmailVerifiedAt != nil || app.CurrentUserStatus(user, time.Now()) != domain.UserStatusActive {
+		s.logger.Debug("Email verification resend skipped", zap.String("user_id", user.ID))
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user, input.Locale)
+}
+
+// sendVerificationEmail создает запрос на подтверждение email и отправляет письмо со ссылкой
+func (s *Service) sendVerificationEmail(ctx context.Context, user *domain.User, locale string) error {
+	token, err := app.GenerateRandomToken(app.EmailVerificationTokenLength)
+	if err != nil {
+		s.logger.Error("Failed to generate email verification token", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	now := time.Now()
+	verification := &domain.EmailVerification{
+		ID:        app.GenerateUUID(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: app.HashToken(token),
+		ExpiresAt: now.Add(s.config.EmailVerificationExpiration),
+		CreatedAt: now,
+	}
+
+	if err := s.verificationRepo.CreateEmailVerification(ctx, verification); err != nil {
+		s.logger.Error("Failed to create email verification", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	verifyURL := fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(s.config.AppBaseURL, "/"), url.QueryEscape(token))
+	s.sendEmail(ctx, user, domain.EmailTemplateVerifyEmail, locale, map[string]any{
+		"VerifyURL":    verifyURL,
+		"ExpiresHours": max(int(s.config.EmailVerificationExpiration.Hours()), 1),
+	})
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_VerifyEmail(t *testing.T) {
+	const token = "verification-token"
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		verification func(verification *domain.EmailVerification)
+		user         func(user *domain.User)
+		setup        func(m testMocks)
+		wantErr      error
+	}{
+		{
+			name: "verifies email and sends welcome",
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.verifications.EXPECT().MarkEmailVerificationAsUsed(gomock.Any(), "v1").Return(nil)
+				m.users.EXPECT().MarkEmailVerified(gomock.Any(), "u1", gomock.Any()).Return(nil)
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateWelcome, "anna@example.com")).Return(nil)
+			},
+		},
+		{
+			name: "already verified email skips welcome",
+			user: func(user *domain.User) { user.EmailVerifiedAt = &verifiedAt },
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.verifications.EXPECT().MarkEmailVerificationAsUsed(gomock.Any(), "v1").Return(nil)
+			},
+		},
+		{
+			name:    "token sent to previous email",
+			user:    func(user *domain.User) { user.Email = "maria@example.com" },
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:         "token already used",
+			verification: func(verification *domain.EmailVerification) { verification.Used = true },
+			wantErr:      app.ErrVerificationUsed,
+		},
+		{
+			name:         "token expired",
+			verification: func(verification *domain.EmailVerification) { verification.ExpiresAt = time.Now().Add(-time.Minute) },
+			wantErr:      app.ErrVerificationExpired,
+		},
+		{
+			name: "token used concurrently",
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.verifications.EXPECT().MarkEmailVerificationAsUsed(gomock.Any(), "v1").Return(domain.ErrEmailVerificationUsed)
+			},
+			wantErr: app.ErrVerificationUsed,
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.verifications.EXPECT().MarkEmailVerificationAsUsed(gomock.Any(), "v1").Return(nil)
+				m.users.EXPECT().MarkEmailVerified(gomock.Any(), "u1", gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			verification := &domain.EmailVerification{
+				ID:        "v1",
+				UserID:    "u1",
+				Email:     "anna@example.com",
+				TokenHash: app.HashToken(token),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}
+			if tt.verification != nil {
+				tt.verification(verification)
+			}
+			user := testUser(t, "Kx9!vQ2#mZ")
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.verifications.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), app.HashToken(token)).Return(verification, nil)
+			if !verification.Used && verification.ExpiresAt.After(time.Now()) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.VerifyEmail(context.Background(), token, "en")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		s, m := newTestService(t)
+		m.verifications.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), app.HashToken(token)).Return(nil, domain.ErrUserNotFound)
+
+		err := s.VerifyEmail(context.Background(), token, "en")
+		assert.ErrorIs(t, err, app.ErrInvalidToken)
+	})
+
+	t.Run("empty token", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.VerifyEmail(context.Background(), "", "en")
+		assert.ErrorIs(t, err, app.ErrInvalidInput)
+	})
+}
+
+func TestService_ResendVerificationEmail(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		user    func(user *domain.User)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "unverified email",
+			setup: func(m testMocks) {
+				m.verifications.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, verification *domain.EmailVerification) error {
+						assert.Equal(t, "u1", verification.UserID)
+						assert.Equal(t, "anna@example.com", verification.Email)
+						return nil
+					})
+				m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplateVerifyEmail, "anna@example.com")).Return(nil)
+			},
+		},
+		{
+			name: "already verified",
+			user: func(user *domain.User) { user.EmailVerifiedAt = &verifiedAt },
+		},
+		{
+			name: "inactive user",
+			user: func(user *domain.User) { user.Status = domain.UserStatusInactive },
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.verifications.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, "Kx9!vQ2#mZ")
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := s.ResendVerificationEmail(context.Background(), ResendVerificationInput{Email: user.Email, Locale: "en"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown email is not disclosed", func(t *testing.T) {
+		s, m := newTestService(t)
+		m.users.EXPECT().GetUserByEmail(gomock.Any(), "nobody@example.com").Return(nil, domain.ErrUserNotFound)
+
+		err := s.ResendVerificationEmail(context.Background(), ResendVerificationInput{Email: "nobody@example.com"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.ResendVerificationEmail(context.Background(), ResendVerificationInput{Email: "anna"})
+		assert.ErrorIs(t, err, app.ErrInvalidInput)
+	})
+}
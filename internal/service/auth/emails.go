@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Коды событий для письма об изменениях безопасности
+const (
+	securityEventPasswordReset     = "password_reset"
+	securityEventRefreshTokenReuse = "refresh_token_reuse"
+	securityEventEmailChanged      = "email_changed"
+	securityEventPasswordChanged   = "password_changed"
+)
+
+// sendPasswordResetEmail отправляет письмо со ссылкой на сброс пароля
+func (s *Service) sendPasswordResetEmail(ctx context.Context, user *domain.User, token, locale string) {
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(s.config.AppBaseURL, "/"), url.QueryEscape(token))
+
+	s.sendEmail(ctx, user, domain.EmailTemplatePasswordReset, locale, map[string]any{
+		"ResetURL":     resetURL,
+		"ExpiresHours": app.PasswordResetExpiration,
+	})
+}
+
+// sendWelcomeEmail отправляет приветственное письмо после регистрации
+func (s *Service) sendWelcomeEmail(ctx context.Context, user *domain.User, locale string) {
+	s.sendEmail(ctx, user, domain.EmailTemplateWelcome, locale, nil)
+}
+
+// sendSecurityAlert уведомляет пользователя об изменениях безопасности аккаунта
+func (s *Service) sendSecurityAlert(ctx context.Context, user *domain.User, event, locale string) {
+	s.sendEmail(ctx, user, domain.EmailTemplateSecurityAlert, locale, map[string]any{
+		"Event":      event,
+		"OccurredAt": time.Now().UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// sendEmail ставит письмо в очередь; ошибка отправки не прерывает основной сценарий
+func (s *Service) sendEmail(ctx context.Context, user *domain.User, template domain.EmailTemplate, locale string, data map[string]any) {
+	email := domain.Email{
+		To:       user.Email,
+		ToName:   user.Name,
+		Template: template,
+		Locale:   locale,
+		Data:     data,
+	}
+
+	if err := s.mailer.Send(ctx, email); err != nil {
+		s.logger.Error("Failed to queue email",
+			zap.Error(err),
+			zap.String("template", string(template)),
+			zap.String("user_id", user.ID),
+		)
+	}
+}
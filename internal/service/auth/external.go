@@ -1,10 +1,13 @@
 package auth
 
+//go:generate mockgen -source=external.go -destination=./mock/external.go -package mock
+
 import (
 	"context"
 	"time"
 
 	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // UserRepository определяет интерфейс для работы с пользователями
@@ -13,26 +16,112 @@ type UserRepository interface {
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+	UpdateUserStatus(ctx context.Context, user *domain.User) error
+	UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error
+	RecordFailedLogin(ctx context.Context, userID string, resetAfter time.Duration) (int, error)
+	LockUser(ctx context.Context, userID string, until time.Time) error
+	ResetFailedLogins(ctx context.Context, userID string) error
+	SetTOTPSecret(ctx context.Context, userID, secret string) error
+	EnableTOTP(ctx context.Context, userID string, step int64) error
+	DisableTOTP(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error
+	UpdateUserEmail(ctx context.Context, userID, email string) error
 }
 
 // SessionRepository определяет интерфейс для работы с сессиями
 type SessionRepository interface {
 	CreateSession(ctx context.Context, session *domain.UserSession) error
+	GetSessionByID(ctx context.Context, id string) (*domain.UserSession, error)
+	GetSessionsByUserID(ctx context.Context, userID string) ([]*domain.UserSession, error)
+	DeleteSession(ctx context.Context, id string) error
+	DeleteSessionsByUserID(ctx context.Context, userID string) error
+	DeleteSessionsByDevice(ctx context.Context, userID, deviceID string) error
+	DeleteSessionsByFamily(ctx context.Context, familyID string) error
+	MarkSessionRotated(ctx context.Context, id, replacedBy string) error
 	DeleteExpiredSessions(ctx context.Context) error
 }
 
 // PasswordResetRepository определяет интерфейс для работы со сбросом паролей
 type PasswordResetRepository interface {
 	CreatePasswordReset(ctx context.Context, reset *domain.PasswordReset) error
-	GetPasswordResetByToken(ctx context.Context, token string) (*domain.PasswordReset, error)
+	GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordReset, error)
 	MarkPasswordResetAsUsed(ctx context.Context, id string) error
+	CancelPasswordResets(ctx context.Context, userID string) error
 	DeleteExpiredPasswordResets(ctx context.Context) error
 }
 
+// PasswordHistoryRepository определяет интерфейс для истории паролей
+type PasswordHistoryRepository interface {
+	AddPasswordHistory(ctx context.Context, userID, passwordHash string, keep int) error
+	GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+// EmailVerificationRepository определяет интерфейс для работы с подтверждением email
+type EmailVerificationRepository interface {
+	CreateEmailVerification(ctx context.Context, verification *domain.EmailVerification) error
+	GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error)
+	MarkEmailVerificationAsUsed(ctx context.Context, id string) error
+	DeleteEmailVerifications(ctx context.Context, userID string) error
+	DeleteExpiredEmailVerifications(ctx context.Context) error
+}
+
+// EmailChangeRepository определяет интерфейс для работы со сменой email
+type EmailChangeRepository interface {
+	CreateEmailChange(ctx context.Context, change *domain.EmailChange) error
+	GetEmailChangeByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailChange, error)
+	GetEmailChangeByCancelTokenHash(ctx context.Context, cancelTokenHash string) (*domain.EmailChange, error)
+	MarkEmailChangeAsUsed(ctx context.Context, id string) error
+	CancelEmailChanges(ctx context.Context, userID string) error
+}
+
+// RecoveryCodeRepository определяет интерфейс для кодов восстановления двухфакторной аутентификации
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userID string) error
+}
+
+// SecurityEventRepository определяет интерфейс для журнала событий безопасности
+type SecurityEventRepository interface {
+	CreateSecurityEvent(ctx context.Context, event *domain.SecurityEvent) error
+}
+
 // RedisRepository определяет интерфейс для работы с Redis
 type RedisRepository interface {
-	SetRefreshToken(ctx context.Context, userID, refreshToken string, expiration time.Duration) error
-	GetRefreshToken(ctx context.Context, userID string) (string, error)
-	DeleteRefreshToken(ctx context.Context, userID string) error
+	SetRefreshToken(ctx context.Context, userID, deviceID, refreshToken string, expiration time.Duration) error
+	GetRefreshToken(ctx context.Context, userID, deviceID string) (string, error)
+	DeleteRefreshToken(ctx context.Context, userID, deviceID string) error
 	DeleteAllUserRefreshTokens(ctx context.Context, userID string) error
+	RevokeAccessToken(ctx context.Context, tokenID string, ttl time.Duration) error
+	RevokeSessionTokens(ctx context.Context, sessionIDs []string, ttl time.Duration) error
+	SetTokensValidAfter(ctx context.Context, userID string, validAfter time.Time, ttl time.Duration) error
+	GetAccessTokenStatus(ctx context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error)
+	SetUserBan(ctx context.Context, userID string, ttl time.Duration) error
+	DeleteUserBan(ctx context.Context, userID string) error
+}
+
+// TxManager определяет интерфейс для выполнения операций в одной транзакции
+type TxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Mailer определяет интерфейс для отправки писем по шаблону
+type Mailer interface {
+	Send(ctx context.Context, email domain.Email) error
+}
+
+// TokenKeys определяет интерфейс подписи JWT и поиска ключей их проверки
+type TokenKeys interface {
+	Sign(claims jwt.MapClaims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	JWKS() domain.JSONWebKeySet
+}
+
+// Metrics определяет интерфейс записи бизнес метрик аутентификации
+type Metrics interface {
+	RecordUserLogin(success bool, reason string)
+	RecordUserRegistration(success bool)
+	RecordPasswordReset(success bool)
 }
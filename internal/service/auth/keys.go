@@ -0,0 +1,16 @@
+package auth
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keyfunc возвращает ключ проверки подписи токена по его kid
+func (s *Service) Keyfunc(token *jwt.Token) (interface{}, error) {
+	return s.tokenKeys.Keyfunc(token)
+}
+
+// PublicKeys возвращает открытые ключи проверки токенов для публикации в JWKS
+func (s *Service) PublicKeys() domain.JSONWebKeySet {
+	return s.tokenKeys.JWKS()
+}
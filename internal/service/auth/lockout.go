@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// LockedError сообщает, что вход временно запрещен после неудачных попыток
+type LockedError struct {
+	Until time.Time
+}
+
+// Error возвращает текст app.ErrAccountLocked
+func (e *LockedError) Error() string {
+	return app.ErrAccountLocked.Error()
+}
+
+// Is позволяет сравнивать ошибку с app.ErrAccountLocked через errors.Is
+func (e *LockedError) Is(target error) bool {
+	return target == app.ErrAccountLocked
+}
+
+// isLocked сообщает, запрещен ли пользователю вход после неудачных попыток
+func isLocked(user *domain.User, now time.Time) bool {
+	return user.LockedUntil != nil && now.Before(*user.LockedUntil)
+}
+
+// lockoutDelay возвращает срок блокировки входа после attempts неудачных попыток подряд:
+// экспоненциальная задержка начиная с LoginBackoffAfter и полная блокировка с LoginLockoutThreshold
+func (s *Service) lockoutDelay(attempts int) time.Duration {
+	maxDelay := s.config.LoginLockoutDuration
+
+	if s.config.LoginLockoutThreshold > 0 && attempts >= s.config.LoginLockoutThreshold {
+		return maxDelay
+	}
+
+	after := s.config.LoginBackoffAfter
+	if after <= 0 || attempts < after || s.config.LoginBackoffBase <= 0 {
+		return 0
+	}
+
+	delay := s.config.LoginBackoffBase
+	for i := after; i < attempts; i++ {
+		if maxDelay > 0 && delay >= maxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// registerFailedLogin учитывает неудачную попытку входа и при необходимости блокирует вход
+func (s *Service) registerFailedLogin(ctx context.Context, user *domain.User) {
+	attempts, err := s.userRepo.RecordFailedLogin(ctx, user.ID, s.config.LoginFailureWindow)
+	if err != nil {
+		s.logger.Error("Failed to record failed login", zap.Error(err), zap.String("user_id", user.ID))
+		return
+	}
+
+	delay := s.lockoutDelay(attempts)
+	if delay <= 0 {
+		return
+	}
+
+	until := time.Now().Add(delay)
+	if err := s.userRepo.LockUser(ctx, user.ID, until); err != nil {
+		s.logger.Error("Failed to lock user login", zap.Error(err), zap.String("user_id", user.ID))
+		return
+	}
+
+	s.logger.Warn("User login locked after failed attempts",
+		zap.String("user_id", user.ID),
+		zap.Int("attempts", attempts),
+		zap.Duration("delay", delay),
+	)
+}
+
+// resetFailedLogins сбрасывает счетчик неудачных попыток после успешного входа
+func (s *Service) resetFailedLogins(ctx context.Context, user *domain.User) {
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return
+	}
+
+	if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to reset failed logins", zap.Error(err), zap.String("user_id", user.ID))
+	}
+}
+
+// UnlockUser снимает блокировку входа и сбрасывает счетчик неудачных попыток.
+// Снять блокировку администратора может только администратор
+func (s *Service) UnlockUser(ctx context.Context, userID string, unlockedByRole domain.UserRole) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get user for unlock", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	if !app.CanManageUser(unlockedByRole, user) {
+		s.logger.Warn("Attempt to unlock admin by non-admin", zap.String("user_id", userID))
+		return nil, app.ErrForbidden
+	}
+
+	if err := s.userRepo.ResetFailedLogins(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to unlock user", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	user.FailedLoginAttempts = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+
+	s.logger.Info("User login unlocked", zap.String("user_id", userID))
+	return user, nil
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLockedError(t *testing.T) {
+	err := error(&LockedError{Until: time.Now().Add(time.Minute)})
+
+	assert.True(t, errors.Is(err, app.ErrAccountLocked))
+	assert.False(t, errors.Is(err, app.ErrInvalidCredentials))
+	assert.Equal(t, app.ErrAccountLocked.Error(), err.Error())
+}
+
+func TestIsLocked(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Second)
+	future := now.Add(time.Second)
+
+	tests := []struct {
+		name        string
+		lockedUntil *time.Time
+		want        bool
+	}{
+		{name: "never locked", lockedUntil: nil, want: false},
+		{name: "lock expired", lockedUntil: &past, want: false},
+		{name: "lock ends now", lockedUntil: &now, want: false},
+		{name: "lock active", lockedUntil: &future, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isLocked(&domain.User{LockedUntil: tt.lockedUntil}, now))
+		})
+	}
+}
+
+func TestService_lockoutDelay(t *testing.T) {
+	defaults := config.Config{
+		LoginBackoffAfter:     3,
+		LoginBackoffBase:      time.Second,
+		LoginLockoutThreshold: 10,
+		LoginLockoutDuration:  15 * time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(cfg *config.Config)
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first failure", attempts: 1, want: 0},
+		{name: "below back-off start", attempts: 2, want: 0},
+		{name: "back-off starts with base delay", attempts: 3, want: time.Second},
+		{name: "delay doubles", attempts: 4, want: 2 * time.Second},
+		{name: "delay doubles again", attempts: 5, want: 4 * time.Second},
+		{name: "last attempt before lockout", attempts: 9, want: 64 * time.Second},
+		{name: "threshold gives full lockout", attempts: 10, want: 15 * time.Minute},
+		{name: "above threshold keeps full lockout", attempts: 25, want: 15 * time.Minute},
+		{
+			name:     "delay capped at lockout duration",
+			modify:   func(cfg *config.Config) { cfg.LoginLockoutDuration = 10 * time.Second },
+			attempts: 8,
+			want:     10 * time.Second,
+		},
+		{
+			name:     "delay reaching cap exactly",
+			modify:   func(cfg *config.Config) { cfg.LoginLockoutDuration = 8 * time.Second },
+			attempts: 6,
+			want:     8 * time.Second,
+		},
+		{
+			name: "many attempts without threshold stay capped",
+			modify: func(cfg *config.Config) {
+				cfg.LoginLockoutThreshold = 0
+				cfg.LoginLockoutDuration = time.Minute
+			},
+			attempts: 1000,
+			want:     time.Minute,
+		},
+		{
+			name:     "back-off disabled by zero start",
+			modify:   func(cfg *config.Config) { cfg.LoginBackoffAfter = 0 },
+			attempts: 9,
+			want:     0,
+		},
+		{
+			name:     "lockout still applies with back-off disabled",
+			modify:   func(cfg *config.Config) { cfg.LoginBackoffAfter = 0 },
+			attempts: 10,
+			want:     15 * time.Minute,
+		},
+		{
+			name:     "back-off disabled by zero base",
+			modify:   func(cfg *config.Config) { cfg.LoginBackoffBase = 0 },
+			attempts: 5,
+			want:     0,
+		},
+		{
+			name:     "back-off disabled by negative base",
+			modify:   func(cfg *config.Config) { cfg.LoginBackoffBase = -time.Second },
+			attempts: 5,
+			want:     0,
+		},
+		{
+			name:     "lockout disabled by zero threshold",
+			modify:   func(cfg *config.Config) { cfg.LoginLockoutThreshold = 0 },
+			attempts: 10,
+			want:     128 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			s := &Service{config: &cfg}
+
+			assert.Equal(t, tt.want, s.lockoutDelay(tt.attempts))
+		})
+	}
+}
+
+func TestService_UnlockUser(t *testing.T) {
+	lockedUntil := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		role    domain.UserRole
+		stored  domain.User
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name:   "non-admin unlocks user",
+			role:   domain.UserRoleUser,
+			stored: domain.User{ID: "u1", Role: domain.UserRoleUser, FailedLoginAttempts: 7, LockedUntil: &lockedUntil},
+			setup: func(m testMocks) {
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+			},
+		},
+		{
+			name:   "admin unlocks admin",
+			role:   domain.UserRoleAdmin,
+			stored: domain.User{ID: "u1", Role: domain.UserRoleAdmin, FailedLoginAttempts: 7, LockedUntil: &lockedUntil},
+			setup: func(m testMocks) {
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+			},
+		},
+		{
+			name:    "non-admin unlocks admin",
+			role:    domain.UserRoleUser,
+			stored:  domain.User{ID: "u1", Role: domain.UserRoleAdmin, FailedLoginAttempts: 7, LockedUntil: &lockedUntil},
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			stored := tt.stored
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&stored, nil)
+			tt.setup(m)
+
+			got, err := s.UnlockUser(context.Background(), "u1", tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Zero(t, got.FailedLoginAttempts)
+			assert.Nil(t, got.LockedUntil)
+		})
+	}
+}
+
+func TestService_UnlockUser_NotFound(t *testing.T) {
+	s, m := newTestService(t)
+	m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+
+	_, err := s.UnlockUser(context.Background(), "u1", domain.UserRoleAdmin)
+	assert.ErrorIs(t, err, app.ErrUserNotFound)
+}
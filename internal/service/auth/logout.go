@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// LogoutInput представляет данные текущего access токена для выхода
+type LogoutInput struct {
+	UserID               string
+	SessionID            string // Пустой для токенов, выданных до появления claim sid
+	DeviceID             string // Устройство из заголовка запроса, используется без sid
+	AccessTokenID        string // jti access токена (хеш токена для токенов без jti)
+	AccessTokenExpiresAt time.Time
+}
+
+// Logout завершает текущую сессию устройства и блокирует access токены, выданные на ее семейство ротации
+func (s *Service) Logout(ctx context.Context, input LogoutInput) error {
+	deviceID := input.DeviceID
+	var familySessionIDs []string
+
+	if input.SessionID != "" {
+		// Устройство берем из сессии, на которую выписан токен
+		session, err := s.sessionRepo.GetSessionByID(ctx, input.SessionID)
+		switch {
+		case err == nil && session.UserID == input.UserID:
+			deviceID = session.DeviceID
+			// Как и при отзыве сессии, отзываем access токены ротированных сессий семейства
+			familySessionIDs, err = s.familySessionIDs(ctx, session)
+			if err != nil {
+				return app.ErrInternalServer
+			}
+			if err := s.sessionRepo.DeleteSessionsByFamily(ctx, session.FamilyID); err != nil {
+				s.logger.Error("Failed to delete session on logout", zap.Error(err), zap.String("user_id", input.UserID))
+				return app.ErrInternalServer
+			}
+		case err != nil && !errors.Is(err, domain.ErrUserNotFound):
+			s.logger.Error("Failed to get session on logout", zap.Error(err), zap.String("user_id", input.UserID))
+			return app.ErrInternalServer
+		}
+	}
+
+	if deviceID != "" {
+		if err := s.redisRepo.DeleteRefreshToken(ctx, input.UserID, deviceID); err != nil {
+			s.logger.Error("Failed to delete refresh token on logout", zap.Error(err), zap.String("user_id", input.UserID))
+			return app.ErrInternalServer
+		}
+	}
+
+	if err := s.revokeAccessToken(ctx, input); err != nil {
+		return err
+	}
+
+	if len(familySessionIDs) > 0 {
+		if err := s.redisRepo.RevokeSessionTokens(ctx, familySessionIDs, s.config.JWTExpiration); err != nil {
+			s.logger.Error("Failed to revoke access tokens of session family on logout", zap.Error(err), zap.String("user_id", input.UserID))
+			return app.ErrInternalServer
+		}
+		s.notifyAccessTokenRevoked(input.UserID, "")
+	}
+
+	s.logger.Info("User logged out", zap.String("user_id", input.UserID), zap.String("device_id", deviceID))
+	return nil
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func (s *Service) LogoutAll(ctx context.Context, input LogoutInput) error {
+	if err := s.sessionRepo.DeleteSessionsByUserID(ctx, input.UserID); err != nil {
+		s.logger.Error("Failed to delete sessions on logout-all", zap.Error(err), zap.String("user_id", input.UserID))
+		return app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.DeleteAllUserRefreshTokens(ctx, input.UserID); err != nil {
+		s.logger.Error("Failed to delete refresh tokens on logout-all", zap.Error(err), zap.String("user_id", input.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Отзываем все выданные ранее access токены, включая текущий
+	if err := s.invalidateAccessTokens(ctx, input.UserID, time.Now()); err != nil {
+		return app.ErrInternalServer
+	}
+
+	s.logger.Info("User logged out from all devices", zap.String("user_id", input.UserID))
+	return nil
+}
+
+// AccessTokenStatus возвращает состояние отзыва access токена для проверки в JWTAuth
+func (s *Service) AccessTokenStatus(ctx context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error) {
+	return s.redisRepo.GetAccessTokenStatus(ctx, userID, sessionID, tokenID)
+}
+
+// OnAccessTokenRevoked регистрирует fn, который вызывается после отзыва access токенов в этом процессе:
+// с tokenID для одного токена и с пустым tokenID для всех токенов пользователя
+func (s *Service) OnAccessTokenRevoked(fn func(userID, tokenID string)) {
+	s.revocationMu.Lock()
+	defer s.revocationMu.Unlock()
+	s.revocationListeners = append(s.revocationListeners, fn)
+}
+
+// notifyAccessTokenRevoked сообщает подписчикам об отзыве access токенов
+func (s *Service) notifyAccessTokenRevoked(userID, tokenID string) {
+	s.revocationMu.RLock()
+	defer s.revocationMu.RUnlock()
+	for _, fn := range s.revocationListeners {
+		fn(userID, tokenID)
+	}
+}
+
+// revokeAccessToken блокирует access токен до истечения его срока действия
+func (s *Service) revokeAccessToken(ctx context.Context, input LogoutInput) error {
+	ttl := time.Until(input.AccessTokenExpiresAt)
+	if err := s.redisRepo.RevokeAccessToken(ctx, input.AccessTokenID, ttl); err != nil {
+		s.logger.Error("Failed to revoke access token", zap.Error(err), zap.String("user_id", input.UserID))
+		return app.ErrInternalServer
+	}
+	s.notifyAccessTokenRevoked(input.UserID, input.AccessTokenID)
+	return nil
+}
+
+// invalidateAccessTokens делает недействительными все access токены пользователя, выданные не позже validAfter
+func (s *Service) invalidateAccessTokens(ctx context.Context, userID string, validAfter time.Time) error {
+	if err := s.redisRepo.SetTokensValidAfter(ctx, userID, validAfter, s.config.JWTExpiration); err != nil {
+		s.logger.Error("Failed to invalidate access tokens", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+	s.notifyAccessTokenRevoked(userID, "")
+	return nil
+}
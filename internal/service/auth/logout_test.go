@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// revocation описывает вызов подписчика OnAccessTokenRevoked
+type revocation struct {
+	userID  string
+	tokenID string
+}
+
+func TestService_Logout_NotifiesRevocation(t *testing.T) {
+	input := LogoutInput{UserID: "u1", DeviceID: "d1", AccessTokenID: "t1", AccessTokenExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		logout  func(s *Service) error
+		setup   func(m testMocks)
+		want    []revocation
+		wantErr error
+	}{
+		{
+			name:   "logout revokes current token",
+			logout: func(s *Service) error { return s.Logout(context.Background(), input) },
+			setup: func(m testMocks) {
+				m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "d1").Return(nil)
+				m.redis.EXPECT().RevokeAccessToken(gomock.Any(), "t1", gomock.Any()).Return(nil)
+			},
+			want: []revocation{{userID: "u1", tokenID: "t1"}},
+		},
+		{
+			name:   "logout-all revokes all user tokens",
+			logout: func(s *Service) error { return s.LogoutAll(context.Background(), input) },
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), time.Hour).Return(nil)
+			},
+			want: []revocation{{userID: "u1"}},
+		},
+		{
+			name:   "failed revocation not reported",
+			logout: func(s *Service) error { return s.Logout(context.Background(), input) },
+			setup: func(m testMocks) {
+				m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "d1").Return(nil)
+				m.redis.EXPECT().RevokeAccessToken(gomock.Any(), "t1", gomock.Any()).Return(errors.New("redis unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+			var got []revocation
+			s.OnAccessTokenRevoked(func(userID, tokenID string) {
+				got = append(got, revocation{userID: userID, tokenID: tokenID})
+			})
+
+			err := tt.logout(s)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_Logout_RevokesSessionFamily(t *testing.T) {
+	s, m := newTestService(t)
+	current := &domain.UserSession{ID: "s2", UserID: "u1", DeviceID: "d1", FamilyID: "f1"}
+	m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s2").Return(current, nil)
+	m.sessions.EXPECT().GetSessionsByUserID(gomock.Any(), "u1").Return([]*domain.UserSession{
+		{ID: "s1", UserID: "u1", FamilyID: "f1"},
+		current,
+		{ID: "s3", UserID: "u1", FamilyID: "f2"},
+	}, nil)
+	m.sessions.EXPECT().DeleteSessionsByFamily(gomock.Any(), "f1").Return(nil)
+	m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "d1").Return(nil)
+	m.redis.EXPECT().RevokeAccessToken(gomock.Any(), "t1", gomock.Any()).Return(nil)
+	// Токены ротированной сессии s1 того же семейства отзываются вместе с текущей
+	m.redis.EXPECT().RevokeSessionTokens(gomock.Any(), []string{"s2", "s1"}, time.Hour).Return(nil)
+
+	err := s.Logout(context.Background(), LogoutInput{
+		UserID:               "u1",
+		SessionID:            "s2",
+		AccessTokenID:        "t1",
+		AccessTokenExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ResetPasswordInput представляет входные данные для сброса пароля
+type ResetPasswordInput struct {
+	Email  string `json:"email"`
+	Locale string `json:"locale"` // Язык письма
+}
+
+// ConfirmPasswordResetInput представляет входные данные для подтверждения сброса пароля
+type ConfirmPasswordResetInput struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+	Locale      string `json:"locale"` // Язык уведомления
+}
+
+// RequestPasswordReset создает запрос на сброс пароля
+func (s *Service) RequestPasswordReset(ctx context.Context, input ResetPasswordInput) error {
+	// Валидация email
+	if !app.ValidateEmail(input.Email) {
+		s.logger.Warn("Invalid email format", zap.String("email", input.Email))
+		return app.ErrInvalidInput
+	}
+
+	// Получаем пользователя по email
+	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		// Не раскрываем информацию о существовании пользователя
+		s.logger.Debug("User not found for password reset", zap.String("email", input.Email))
+		return nil // Возвращаем успех даже если пользователь не найден
+	}
+
+	// Проверяем активность пользователя
+	if app.CurrentUserStatus(user, time.Now()) != domain.UserStatusActive {
+		s.logger.Debug("Inactive user requested password reset", zap.String("user_id", user.ID))
+		return nil // Возвращаем успех даже для неактивных и заблокированных пользователей
+	}
+
+	// Генерируем токен для сброса пароля
+	token, err := app.GenerateRandomToken(app.PasswordResetTokenLength)
+	if err != nil {
+		s.logger.Error("Failed to generate password reset token", zap.Error(err), zap.String("user_id", user.ID))
+		s.metrics.RecordPasswordReset(false)
+		return app.ErrInternalServer
+	}
+
+	// Создаем запрос на сброс пароля
+	reset := &domain.PasswordReset{
+		ID:        app.GenerateUUID(),
+		UserID:    user.ID,
+		TokenHash: app.HashToken(token), // В БД хранится только хеш токена из письма
+		ExpiresAt: time.Now().Add(time.Duration(app.PasswordResetExpiration) * time.Hour),
+		Used:      false,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.passwordResetRepo.CreatePasswordReset(ctx, reset)
+	if err != nil {
+		s.logger.Error("Failed to create password reset", zap.Error(err), zap.String("user_id", user.ID))
+		s.metrics.RecordPasswordReset(false)
+		return app.ErrInternalServer
+	}
+
+	// Письмо отправляется асинхронно и не задерживает ответ
+	s.sendPasswordResetEmail(ctx, user, token, input.Locale)
+	s.metrics.RecordPasswordReset(true)
+
+	s.logger.Info("Password reset requested", zap.String("user_id", user.ID), zap.String("email", user.Email))
+	return nil
+}
+
+// ConfirmPasswordReset устанавливает новый пароль по токену сброса и завершает все сессии пользователя
+func (s *Service) ConfirmPasswordReset(ctx context.Context, input ConfirmPasswordResetInput) error {
+	// Валидация входных данных
+	if input.Token == "" {
+		return app.ErrInvalidInput
+	}
+
+	// Получаем запрос на сброс пароля по токену
+	reset, err := s.passwordResetRepo.GetPasswordResetByTokenHash(ctx, app.HashToken(input.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.Warn("Password reset token not found")
+			return app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get password reset", zap.Error(err))
+		return app.ErrInternalServer
+	}
+
+	if reset.Used {
+		s.logger.Warn("Password reset token already used", zap.String("reset_id", reset.ID))
+		return app.ErrPasswordResetUsed
+	}
+
+	if app.IsExpired(reset.ExpiresAt) {
+		s.logger.Warn("Password reset token expired", zap.String("reset_id", reset.ID))
+		return app.ErrPasswordResetExpired
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, reset.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to get user for password reset", zap.Error(err), zap.String("user_id", reset.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Ссылка, выданная до блокировки или деактивации, не должна вернуть доступ к аккаунту
+	if app.CurrentUserStatus(user, time.Now()) != domain.UserStatusActive {
+		s.logger.Warn("Password reset for inactive user", zap.String("user_id", user.ID), zap.String("status", string(user.Status)))
+		return app.ErrInvalidToken
+	}
+
+	if err := s.passwordPolicy.Validate(input.NewPassword, user.Email, user.Name); err != nil {
+		s.logger.Warn("Password does not meet policy", zap.String("user_id", user.ID))
+		return err
+	}
+
+	if err := s.checkPasswordReuse(ctx, user, input.NewPassword); err != nil {
+		return err
+	}
+
+	// Хешируем новый пароль
+	passwordHash, err := app.HashPassword(input.NewPassword)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return app.ErrInternalServer
+	}
+
+	// Помечаем токен использованным, меняем пароль и удаляем сессии атомарно
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.passwordResetRepo.MarkPasswordResetAsUsed(ctx, reset.ID); err != nil {
+			return err
+		}
+		if err := s.savePasswordHistory(ctx, user); err != nil {
+			return err
+		}
+		if err := s.userRepo.UpdatePassword(ctx, reset.UserID, passwordHash); err != nil {
+			return err
+		}
+		// Владелец подтвердил доступ к почте: снимаем блокировку входа после неудачных попыток
+		if err := s.userRepo.ResetFailedLogins(ctx, reset.UserID); err != nil {
+			return err
+		}
+		return s.sessionRepo.DeleteSessionsByUserID(ctx, reset.UserID)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrPasswordResetUsed) {
+			s.logger.Warn("Password reset token used concurrently", zap.String("reset_id", reset.ID))
+			return app.ErrPasswordResetUsed
+		}
+		s.logger.Error("Failed to confirm password reset", zap.Error(err), zap.String("user_id", reset.UserID))
+		return app.ErrInternalServer
+	}
+
+	// Redis не участвует в транзакции, поэтому refresh токены удаляем после коммита
+	if err := s.redisRepo.DeleteAllUserRefreshTokens(ctx, reset.UserID); err != nil {
+		s.logger.Error("Failed to revoke refresh tokens after password reset", zap.Error(err), zap.String("user_id", reset.UserID))
+	}
+	if err := s.invalidateAccessTokens(ctx, reset.UserID, time.Now()); err != nil {
+		s.logger.Error("Access tokens stay valid until expiry after password reset", zap.String("user_id", reset.UserID))
+	}
+
+	// Уведомляем владельца аккаунта о смене пароля
+	s.sendSecurityAlert(ctx, user, securityEventPasswordReset, input.Locale)
+
+	s.logger.Info("Password reset confirmed", zap.String("user_id", reset.UserID))
+	return nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_RequestPasswordReset_StoresTokenHash(t *testing.T) {
+	s, m := newTestService(t)
+	user := testUser(t, "Kx9!vQ2#mZ")
+
+	var stored *domain.PasswordReset
+	m.users.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil)
+	m.resets.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, reset *domain.PasswordReset) error {
+			stored = reset
+			return nil
+		})
+	m.mailer.EXPECT().Send(gomock.Any(), withTemplate(domain.EmailTemplatePasswordReset, user.Email)).
+		DoAndReturn(func(_ context.Context, email domain.Email) error {
+			resetURL, err := url.Parse(email.Data["ResetURL"].(string))
+			require.NoError(t, err)
+			token := resetURL.Query().Get("token")
+
+			require.NotEmpty(t, token)
+			assert.Equal(t, app.HashToken(token), stored.TokenHash)
+			assert.NotEqual(t, token, stored.TokenHash)
+			return nil
+		})
+	m.metrics.EXPECT().RecordPasswordReset(true)
+
+	err := s.RequestPasswordReset(context.Background(), ResetPasswordInput{Email: user.Email, Locale: "en"})
+	require.NoError(t, err)
+}
+
+func TestService_ConfirmPasswordReset_LooksUpTokenHash(t *testing.T) {
+	s, m := newTestService(t)
+	m.resets.EXPECT().GetPasswordResetByTokenHash(gomock.Any(), app.HashToken("reset-token")).Return(nil, domain.ErrUserNotFound)
+
+	err := s.ConfirmPasswordReset(context.Background(), ConfirmPasswordResetInput{Token: "reset-token", NewPassword: "Kx9!vQ2#mZ"})
+	assert.ErrorIs(t, err, app.ErrInvalidToken)
+}
+
+func TestService_ConfirmPasswordReset_UserStatus(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		user    func(user *domain.User)
+		wantErr error
+	}{
+		{
+			name:    "banned after link was sent",
+			user:    func(user *domain.User) { user.Status = domain.UserStatusBanned },
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:    "deactivated after link was sent",
+			user:    func(user *domain.User) { user.Status = domain.UserStatusInactive },
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			// Истекшая блокировка не мешает сбросу: слабый пароль отклоняется уже после проверки статуса
+			name: "ban expired",
+			user: func(user *domain.User) {
+				user.Status = domain.UserStatusBanned
+				user.BannedUntil = &expired
+			},
+			wantErr: app.ErrWeakPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, "Kx9!vQ2#mZ")
+			tt.user(user)
+			reset := &domain.PasswordReset{ID: "r1", UserID: "u1", TokenHash: app.HashToken("reset-token"), ExpiresAt: time.Now().Add(time.Hour)}
+			m.resets.EXPECT().GetPasswordResetByTokenHash(gomock.Any(), app.HashToken("reset-token")).Return(reset, nil)
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+
+			err := s.ConfirmPasswordReset(context.Background(), ConfirmPasswordResetInput{Token: "reset-token", NewPassword: "short"})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// GetCurrentUser возвращает авторизованного пользователя по ID из access токена
+func (s *Service) GetCurrentUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.Warn("Authenticated user not found", zap.String("user_id", userID))
+			return nil, app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get current user", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	// Токен деактивированного пользователя может быть еще валиден, но доступ запрещаем
+	switch app.CurrentUserStatus(user, time.Now()) {
+	case domain.UserStatusBanned:
+		s.logger.Warn("Banned user requested profile", zap.String("user_id", userID))
+		return nil, app.ErrUserBanned
+	case domain.UserStatusInactive:
+		s.logger.Warn("Inactive user requested profile", zap.String("user_id", userID))
+		return nil, app.ErrForbidden
+	}
+
+	return user, nil
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_SetUserRole(t *testing.T) {
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name     string
+		input    SetUserRoleInput
+		setup    func(m testMocks)
+		wantRole domain.UserRole
+		wantErr  error
+	}{
+		{
+			name:  "role changed and all tokens revoked",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleAdmin, ChangedBy: "admin"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+				gomock.InOrder(
+					m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx),
+					m.users.EXPECT().UpdateUserRole(gomock.Any(), "u1", domain.UserRoleAdmin).Return(nil),
+					m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil),
+					m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil),
+					m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), time.Hour).Return(nil),
+				)
+			},
+			wantRole: domain.UserRoleAdmin,
+		},
+		{
+			name:  "changed from cli",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleGuest},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleAdmin}, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.users.EXPECT().UpdateUserRole(gomock.Any(), "u1", domain.UserRoleGuest).Return(nil)
+				m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantRole: domain.UserRoleGuest,
+		},
+		{
+			name:  "same role keeps sessions",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleUser, ChangedBy: "admin"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+			},
+			wantRole: domain.UserRoleUser,
+		},
+		{
+			name:    "own role",
+			input:   SetUserRoleInput{UserID: "admin", Role: domain.UserRoleUser, ChangedBy: "admin"},
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "invalid role",
+			input:   SetUserRoleInput{UserID: "u1", Role: "root", ChangedBy: "admin"},
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:  "user not found",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleAdmin, ChangedBy: "admin"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+			},
+			wantErr: app.ErrUserNotFound,
+		},
+		{
+			name:  "transaction fails, tokens untouched",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleAdmin, ChangedBy: "admin"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.users.EXPECT().UpdateUserRole(gomock.Any(), "u1", domain.UserRoleAdmin).Return(nil)
+				m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(errDB)
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name:  "watermark not stored",
+			input: SetUserRoleInput{UserID: "u1", Role: domain.UserRoleAdmin, ChangedBy: "admin"},
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.users.EXPECT().UpdateUserRole(gomock.Any(), "u1", domain.UserRoleAdmin).Return(nil)
+				m.sessions.EXPECT().DeleteSessionsByUserID(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().DeleteAllUserRefreshTokens(gomock.Any(), "u1").Return(nil)
+				m.redis.EXPECT().SetTokensValidAfter(gomock.Any(), "u1", gomock.Any(), gomock.Any()).Return(errDB)
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+
+			got, err := s.SetUserRole(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRole, got.Role)
+		})
+	}
+}
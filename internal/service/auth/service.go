@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"sync"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
 	"github.com/TeDenis/bukhindor-backend/internal/config"
 	"go.uber.org/zap"
 )
@@ -10,9 +13,22 @@ type Service struct {
 	userRepo          UserRepository
 	sessionRepo       SessionRepository
 	passwordResetRepo PasswordResetRepository
+	passwordHistory   PasswordHistoryRepository
+	verificationRepo  EmailVerificationRepository
+	emailChangeRepo   EmailChangeRepository
+	securityEventRepo SecurityEventRepository
+	recoveryCodeRepo  RecoveryCodeRepository
 	redisRepo         RedisRepository
+	txManager         TxManager
+	mailer            Mailer
+	tokenKeys         TokenKeys
+	metrics           Metrics
+	passwordPolicy    *app.PasswordPolicy
 	config            *config.Config
 	logger            *zap.Logger
+
+	revocationMu        sync.RWMutex
+	revocationListeners []func(userID, tokenID string)
 }
 
 // NewService создает новый сервис аутентификации
@@ -20,7 +36,17 @@ func NewService(
 	userRepo UserRepository,
 	sessionRepo SessionRepository,
 	passwordResetRepo PasswordResetRepository,
+	passwordHistory PasswordHistoryRepository,
+	verificationRepo EmailVerificationRepository,
+	emailChangeRepo EmailChangeRepository,
+	securityEventRepo SecurityEventRepository,
+	recoveryCodeRepo RecoveryCodeRepository,
 	redisRepo RedisRepository,
+	txManager TxManager,
+	mailer Mailer,
+	tokenKeys TokenKeys,
+	metrics Metrics,
+	passwordPolicy *app.PasswordPolicy,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *Service {
@@ -28,7 +54,17 @@ func NewService(
 		userRepo:          userRepo,
 		sessionRepo:       sessionRepo,
 		passwordResetRepo: passwordResetRepo,
+		passwordHistory:   passwordHistory,
+		verificationRepo:  verificationRepo,
+		emailChangeRepo:   emailChangeRepo,
+		securityEventRepo: securityEventRepo,
+		recoveryCodeRepo:  recoveryCodeRepo,
 		redisRepo:         redisRepo,
+		txManager:         txManager,
+		mailer:            mailer,
+		tokenKeys:         tokenKeys,
+		metrics:           metrics,
+		passwordPolicy:    passwordPolicy,
 		config:            cfg,
 		logger:            logger,
 	}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth/mock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testSecret ключ подписи токенов в тестах
+var testSecret = []byte("test-secret")
+
+// testMocks содержит моки зависимостей сервиса
+type testMocks struct {
+	users          *mock.MockUserRepository
+	sessions       *mock.MockSessionRepository
+	resets         *mock.MockPasswordResetRepository
+	history        *mock.MockPasswordHistoryRepository
+	verifications  *mock.MockEmailVerificationRepository
+	emailChanges   *mock.MockEmailChangeRepository
+	securityEvents *mock.MockSecurityEventRepository
+	recoveryCodes  *mock.MockRecoveryCodeRepository
+	redis          *mock.MockRedisRepository
+	tx             *mock.MockTxManager
+	mailer         *mock.MockMailer
+	keys           *mock.MockTokenKeys
+	metrics        *mock.MockMetrics
+}
+
+// newTestService создает сервис с моками зависимостей. Токены подписываются HS256 ключом testSecret
+func newTestService(t *testing.T) (*Service, testMocks) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	m := testMocks{
+		users:          mock.NewMockUserRepository(ctrl),
+		sessions:       mock.NewMockSessionRepository(ctrl),
+		resets:         mock.NewMockPasswordResetRepository(ctrl),
+		history:        mock.NewMockPasswordHistoryRepository(ctrl),
+		verifications:  mock.NewMockEmailVerificationRepository(ctrl),
+		emailChanges:   mock.NewMockEmailChangeRepository(ctrl),
+		securityEvents: mock.NewMockSecurityEventRepository(ctrl),
+		recoveryCodes:  mock.NewMockRecoveryCodeRepository(ctrl),
+		redis:          mock.NewMockRedisRepository(ctrl),
+		tx:             mock.NewMockTxManager(ctrl),
+		mailer:         mock.NewMockMailer(ctrl),
+		keys:           mock.NewMockTokenKeys(ctrl),
+		metrics:        mock.NewMockMetrics(ctrl),
+	}
+	m.keys.EXPECT().Sign(gomock.Any()).DoAndReturn(signTestToken).AnyTimes()
+	m.keys.EXPECT().Keyfunc(gomock.Any()).Return(testSecret, nil).AnyTimes()
+
+	cfg := &config.Config{
+		JWTExpiration:          time.Hour,
+		RefreshTokenExpiration: 24 * time.Hour,
+		MinPasswordLength:      8,
+		LoginBackoffAfter:      3,
+		LoginBackoffBase:       time.Second,
+		LoginLockoutThreshold:  5,
+		LoginLockoutDuration:   15 * time.Minute,
+		LoginFailureWindow:     time.Hour,
+		PasswordHistorySize:    3,
+		MFAPendingTokenTTL:     5 * time.Minute,
+		MFARecoveryCodes:       10,
+	}
+	s := NewService(
+		m.users, m.sessions, m.resets, m.history,
+		m.verifications, m.emailChanges, m.securityEvents, m.recoveryCodes,
+		m.redis, m.tx, m.mailer, m.keys, m.metrics, cfg.PasswordPolicy(), cfg, zap.NewNop(),
+	)
+	return s, m
+}
+
+func TestNewService(t *testing.T) {
+	policy := &app.PasswordPolicy{MinLength: 12, RequireDigit: true}
+
+	s := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, policy, &config.Config{}, zap.NewNop())
+	assert.Same(t, policy, s.passwordPolicy)
+}
+
+// signTestToken подписывает claims ключом testSecret
+func signTestToken(claims jwt.MapClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSecret)
+}
+
+// runInTx выполняет функцию транзакции без БД
+func runInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// testUser возвращает активного пользователя с паролем password
+func testUser(t *testing.T, password string) *domain.User {
+	t.Helper()
+	hash, err := app.HashPassword(password)
+	require.NoError(t, err)
+	return &domain.User{
+		ID:           "u1",
+		Email:        "anna@example.com",
+		Name:         "Anna",
+		PasswordHash: hash,
+		Role:         domain.UserRoleUser,
+		Status:       domain.UserStatusActive,
+	}
+}
+
+// withTemplate сопоставляет письмо по шаблону и получателю
+func withTemplate(template domain.EmailTemplate, to string) gomock.Matcher {
+	return gomock.Cond(func(email domain.Email) bool {
+		return email.Template == template && email.To == to
+	})
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Ограничения длины данных устройства (соответствуют колонкам user_sessions)
+const (
+	maxDeviceIDLength   = 255
+	maxAppVersionLength = 50
+	maxUserAgentLength  = 512
+)
+
+// issueSession открывает новую сессию на устройстве и выдает для нее пару токенов.
+// Предыдущие сессии пользователя на этом устройстве удаляются, другие устройства не затрагиваются.
+// Ненулевой revokedAt - отметка отзыва токенов пользователя, после которой должен быть выдан новый access токен
+func (s *Service) issueSession(ctx context.Context, user *domain.User, device domain.DeviceInfo, revokedAt time.Time) (*domain.AuthTokens, error) {
+	userID := user.ID
+	if device.DeviceID == "" || len(device.DeviceID) > maxDeviceIDLength {
+		s.logger.Warn("Missing device ID for session", zap.String("user_id", userID))
+		return nil, app.ErrInvalidInput
+	}
+
+	sessionID := app.GenerateUUID()
+	tokens, err := s.generateTokens(userID, sessionID, user.Role, revokedAt)
+	if err != nil {
+		s.logger.Error("Failed to generate tokens", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	// Новая сессия открывает собственное семейство ротации
+	session := s.newSession(sessionID, sessionID, userID, tokens.RefreshToken, device)
+
+	// Заменяем сессию устройства атомарно
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.DeleteSessionsByDevice(ctx, userID, device.DeviceID); err != nil {
+			return err
+		}
+		return s.sessionRepo.CreateSession(ctx, session)
+	})
+	if err != nil {
+		s.logger.Error("Failed to create session", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	// Сохраняем refresh токен устройства в Redis
+	err = s.redisRepo.SetRefreshToken(ctx, userID, device.DeviceID, tokens.RefreshToken, s.config.RefreshTokenExpiration)
+	if err != nil {
+		s.logger.Error("Failed to save refresh token", zap.Error(err), zap.String("user_id", userID))
+		// Удаляем сессию, если не удалось сохранить refresh токен
+		_ = s.sessionRepo.DeleteSession(ctx, sessionID)
+		return nil, app.ErrInternalServer
+	}
+
+	return tokens, nil
+}
+
+// rotateSession заменяет сессию новой в том же семействе ротации.
+// Старая сессия помечается ротированной и ссылается на новую, поэтому повторное
+// предъявление ее refresh токена распознается как кража
+func (s *Service) rotateSession(ctx context.Context, old *domain.UserSession, user *domain.User, device domain.DeviceInfo) (*domain.AuthTokens, error) {
+	sessionID := app.GenerateUUID()
+	tokens, err := s.generateTokens(old.UserID, sessionID, user.Role, time.Time{})
+	if err != nil {
+		s.logger.Error("Failed to generate tokens", zap.Error(err), zap.String("user_id", old.UserID))
+		return nil, app.ErrInternalServer
+	}
+
+	device.DeviceID = old.DeviceID
+	session := s.newSession(sessionID, old.FamilyID, old.UserID, tokens.RefreshToken, device)
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.MarkSessionRotated(ctx, old.ID, sessionID); err != nil {
+			return err
+		}
+		return s.sessionRepo.CreateSession(ctx, session)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionRotated) {
+			// Параллельный запрос уже ротировал сессию этим же токеном
+			s.handleRefreshTokenReuse(ctx, old, device)
+			return nil, app.ErrInvalidToken
+		}
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Сессию завершили параллельно (выход, отзыв, вход заново), это не кража токена
+			s.logger.Info("Session ended during refresh", zap.String("session_id", old.ID))
+			return nil, app.ErrInvalidToken
+		}
+		s.logger.Error("Failed to rotate session", zap.Error(err), zap.String("session_id", old.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	err = s.redisRepo.SetRefreshToken(ctx, old.UserID, old.DeviceID, tokens.RefreshToken, s.config.RefreshTokenExpiration)
+	if err != nil {
+		s.logger.Error("Failed to save refresh token", zap.Error(err), zap.String("user_id", old.UserID))
+		// Без refresh токена в Redis семейство непригодно, завершаем его целиком
+		_ = s.sessionRepo.DeleteSessionsByFamily(ctx, old.FamilyID)
+		return nil, app.ErrInternalServer
+	}
+
+	return tokens, nil
+}
+
+// handleRefreshTokenReuse отзывает все семейство сессий при повторном использовании
+// ротированного refresh токена, записывает событие безопасности и уведомляет пользователя
+func (s *Service) handleRefreshTokenReuse(ctx context.Context, session *domain.UserSession, device domain.DeviceInfo) {
+	s.logger.Warn("Refresh token reuse detected",
+		zap.String("user_id", session.UserID),
+		zap.String("session_id", session.ID),
+		zap.String("family_id", session.FamilyID),
+	)
+
+	if err := s.sessionRepo.DeleteSessionsByFamily(ctx, session.FamilyID); err != nil {
+		s.logger.Error("Failed to revoke session family", zap.Error(err), zap.String("family_id", session.FamilyID))
+	}
+
+	if err := s.redisRepo.DeleteRefreshToken(ctx, session.UserID, session.DeviceID); err != nil {
+		s.logger.Error("Failed to delete refresh token of revoked family", zap.Error(err), zap.String("family_id", session.FamilyID))
+	}
+
+	// Злоумышленник мог получить access токен по украденному refresh токену; остальные устройства
+	// получат новые access токены через refresh
+	_ = s.invalidateAccessTokens(ctx, session.UserID, time.Now())
+
+	event := &domain.SecurityEvent{
+		ID:        app.GenerateUUID(),
+		UserID:    session.UserID,
+		Type:      domain.SecurityEventRefreshTokenReuse,
+		IPAddress: device.IPAddress,
+		UserAgent: truncate(device.UserAgent, maxUserAgentLength),
+		Details: map[string]any{
+			"session_id":  session.ID,
+			"family_id":   session.FamilyID,
+			"device_id":   session.DeviceID,
+			"replaced_by": session.ReplacedBy,
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := s.securityEventRepo.CreateSecurityEvent(ctx, event); err != nil {
+		s.logger.Error("Failed to record security event", zap.Error(err), zap.String("user_id", session.UserID))
+	}
+
+	if user, err := s.userRepo.GetUserByID(ctx, session.UserID); err == nil {
+		s.sendSecurityAlert(ctx, user, securityEventRefreshTokenReuse, "")
+	} else {
+		s.logger.Error("Failed to load user for security alert", zap.Error(err), zap.String("user_id", session.UserID))
+	}
+}
+
+// newSession собирает запись сессии устройства для выданного refresh токена
+func (s *Service) newSession(id, familyID, userID, refreshToken string, device domain.DeviceInfo) *domain.UserSession {
+	now := time.Now()
+	return &domain.UserSession{
+		ID:         id,
+		UserID:     userID,
+		TokenHash:  app.HashToken(refreshToken), // Хешируем refresh токен для БД
+		DeviceID:   device.DeviceID,
+		AppType:    device.AppType,
+		AppVersion: truncate(device.AppVersion, maxAppVersionLength),
+		IPAddress:  device.IPAddress,
+		UserAgent:  truncate(device.UserAgent, maxUserAgentLength),
+		LastSeenAt: now,
+		FamilyID:   familyID,
+		ExpiresAt:  now.Add(s.config.RefreshTokenExpiration),
+		CreatedAt:  now,
+	}
+}
+
+// ListSessions возвращает активные (неистекшие и не ротированные) сессии пользователя
+func (s *Service) ListSessions(ctx context.Context, userID string) ([]*domain.UserSession, error) {
+	sessions, err := s.sessionRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list sessions", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	active := make([]*domain.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		// Ротированные сессии заменены новыми и не считаются активными
+		if session.RotatedAt == nil && !app.IsExpired(session.ExpiresAt) {
+			active = append(active, session)
+		}
+	}
+
+	return active, nil
+}
+
+// RevokeSession завершает сессию пользователя, удаляет refresh токен ее устройства и отзывает выданные на нее access токены.
+// Чужая сессия считается несуществующей, чтобы не раскрывать ее наличие
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return app.ErrSessionNotFound
+		}
+		s.logger.Error("Failed to get session", zap.Error(err), zap.String("session_id", sessionID))
+		return app.ErrInternalServer
+	}
+
+	if session.UserID != userID {
+		s.logger.Warn("Attempt to revoke another user's session",
+			zap.String("user_id", userID),
+			zap.String("session_id", sessionID),
+		)
+		return app.ErrSessionNotFound
+	}
+
+	// Access токены выписываются на конкретную сессию, поэтому отзываем все сессии семейства,
+	// включая ротированные: их access токены могут быть еще не истекшими
+	familySessionIDs, err := s.familySessionIDs(ctx, session)
+	if err != nil {
+		return app.ErrInternalServer
+	}
+
+	// Удаляем все семейство, чтобы ротированные записи не пережили отзыв
+	if err := s.sessionRepo.DeleteSessionsByFamily(ctx, session.FamilyID); err != nil {
+		s.logger.Error("Failed to delete session", zap.Error(err), zap.String("session_id", sessionID))
+		return app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.DeleteRefreshToken(ctx, userID, session.DeviceID); err != nil {
+		s.logger.Error("Failed to delete refresh token of revoked session", zap.Error(err), zap.String("session_id", sessionID))
+		return app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.RevokeSessionTokens(ctx, familySessionIDs, s.config.JWTExpiration); err != nil {
+		s.logger.Error("Failed to revoke access tokens of revoked session", zap.Error(err), zap.String("session_id", sessionID))
+		return app.ErrInternalServer
+	}
+	// Кеш хранит статусы по токенам, а не по сессиям, поэтому сбрасываем все токены пользователя
+	s.notifyAccessTokenRevoked(userID, "")
+
+	s.logger.Info("Session revoked", zap.String("user_id", userID), zap.String("session_id", sessionID))
+	return nil
+}
+
+// familySessionIDs возвращает идентификаторы всех сессий семейства ротации, к которому относится session
+func (s *Service) familySessionIDs(ctx context.Context, session *domain.UserSession) ([]string, error) {
+	sessions, err := s.sessionRepo.GetSessionsByUserID(ctx, session.UserID)
+	if err != nil {
+		s.logger.Error("Failed to list session family", zap.Error(err), zap.String("family_id", session.FamilyID))
+		return nil, err
+	}
+
+	ids := []string{session.ID}
+	for _, other := range sessions {
+		if other.FamilyID == session.FamilyID && other.ID != session.ID {
+			ids = append(ids, other.ID)
+		}
+	}
+	return ids, nil
+}
+
+// RevokeUserSessions завершает все сессии пользователя по решению администратора
+func (s *Service) RevokeUserSessions(ctx context.Context, userID string) error {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return app.ErrUserNotFound
+		}
+		s.logger.Error("Failed to get user for session revocation", zap.Error(err), zap.String("user_id", userID))
+		return app.ErrInternalServer
+	}
+
+	return s.TerminateUserSessions(ctx, userID)
+}
+
+// TerminateUserSessions удаляет сессии и refresh токены пользователя и отзывает выданные ему access токены.
+// Существование пользователя не проверяется, поэтому метод подходит и для только что удаленного пользователя
+func (s *Service) TerminateUserSessions(ctx context.Context, userID string) error {
+	if err := s.sessionRepo.DeleteSessionsByUserID(ctx, userID); err != nil {
+		s.logger.Error("Failed to delete user sessions", zap.Error(err), zap.String("user_id", userID))
+		return app.ErrInternalServer
+	}
+
+	if err := s.redisRepo.DeleteAllUserRefreshTokens(ctx, userID); err != nil {
+		s.logger.Error("Failed to delete user refresh tokens", zap.Error(err), zap.String("user_id", userID))
+		return app.ErrInternalServer
+	}
+
+	if err := s.invalidateAccessTokens(ctx, userID, time.Now()); err != nil {
+		return app.ErrInternalServer
+	}
+
+	s.logger.Info("All user sessions revoked", zap.String("user_id", userID))
+	return nil
+}
+
+// truncate обрезает строку до limit байт, не оставляя разрезанных UTF-8 символов
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
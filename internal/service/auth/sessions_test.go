@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_RevokeSession(t *testing.T) {
+	rotatedAt := time.Now().Add(-time.Minute)
+	current := &domain.UserSession{ID: "s2", UserID: "u1", FamilyID: "f1", DeviceID: "device-1"}
+
+	tests := []struct {
+		name    string
+		userID  string
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name:   "revokes access tokens of the whole family",
+			userID: "u1",
+			setup: func(m testMocks) {
+				m.sessions.EXPECT().GetSessionsByUserID(gomock.Any(), "u1").Return([]*domain.UserSession{
+					current,
+					{ID: "s1", UserID: "u1", FamilyID: "f1", DeviceID: "device-1", RotatedAt: &rotatedAt, ReplacedBy: "s2"},
+					{ID: "s3", UserID: "u1", FamilyID: "f3", DeviceID: "device-2"},
+				}, nil)
+				m.sessions.EXPECT().DeleteSessionsByFamily(gomock.Any(), "f1").Return(nil)
+				m.redis.EXPECT().DeleteRefreshToken(gomock.Any(), "u1", "device-1").Return(nil)
+				m.redis.EXPECT().RevokeSessionTokens(gomock.Any(), []string{"s2", "s1"}, time.Hour).Return(nil)
+			},
+		},
+		{
+			name:    "another user's session",
+			userID:  "u2",
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			m.sessions.EXPECT().GetSessionByID(gomock.Any(), "s2").Return(current, nil)
+			tt.setup(m)
+
+			err := s.RevokeSession(context.Background(), tt.userID, "s2")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// DisableTwoFactorInput входные данные для отключения двухфакторной аутентификации
+type DisableTwoFactorInput struct {
+	UserID       string
+	Password     string
+	Code         string
+	RecoveryCode string
+}
+
+// TwoFactorStatus возвращает состояние двухфакторной аутентификации пользователя
+func (s *Service) TwoFactorStatus(ctx context.Context, userID string) (*domain.TwoFactorStatus, error) {
+	user, err := s.GetCurrentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.TwoFactorStatus{Enabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.recoveryCodeRepo.CountRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, app.ErrInternalServer
+		}
+	}
+
+	return status, nil
+}
+
+// EnrollTOTP генерирует новый секрет TOTP. Двухфакторная аутентификация включается после ConfirmTOTP
+func (s *Service) EnrollTOTP(ctx context.Context, userID string) (*domain.TOTPEnrollment, error) {
+	user, err := s.GetCurrentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, app.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := app.GenerateTOTPSecret()
+	if err != nil {
+		s.logger.Error("Failed to generate TOTP secret", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	encrypted, err := app.EncryptString(s.mfaKey(), secret)
+	if err != nil {
+		s.logger.Error("Failed to encrypt TOTP secret", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, encrypted); err != nil {
+		s.logger.Error("Failed to save TOTP secret", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	s.logger.Info("TOTP enrollment started", zap.String("user_id", userID))
+	return &domain.TOTPEnrollment{
+		Secret: secret,
+		URI:    app.TOTPURI(s.config.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP включает двухфакторную аутентификацию после проверки первого кода и возвращает коды восстановления
+func (s *Service) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.GetCurrentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, app.ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, app.ErrMFANotEnabled
+	}
+
+	step, err := s.checkTOTPCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.EnableTOTP(ctx, userID, step); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		s.logger.Error("Failed to enable TOTP", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	s.logger.Info("Two-factor authentication enabled", zap.String("user_id", userID))
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes выдает новый набор кодов восстановления взамен прежних; требует действующий код TOTP.
+// Неверные коды учитываются в счетчике неудачных попыток входа
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.GetCurrentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, app.ErrMFANotEnabled
+	}
+
+	if isLocked(user, time.Now()) {
+		s.logger.Warn("Locked user attempted to regenerate recovery codes", zap.String("user_id", user.ID))
+		return nil, &LockedError{Until: *user.LockedUntil}
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		if errors.Is(err, app.ErrInvalidMFACode) {
+			s.registerFailedLogin(ctx, user)
+		}
+		return nil, err
+	}
+
+	s.resetFailedLogins(ctx, user)
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		return s.recoveryCodeRepo.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		s.logger.Error("Failed to replace recovery codes", zap.Error(err), zap.String("user_id", userID))
+		return nil, app.ErrInternalServer
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor отключает двухфакторную аутентификацию после проверки пароля и второго фактора.
+// Неверный пароль и неверный код неразличимы для клиента и учитываются в счетчике неудачных попыток входа
+func (s *Service) DisableTwoFactor(ctx context.Context, input DisableTwoFactorInput) error {
+	user, err := s.GetCurrentUser(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return app.ErrMFANotEnabled
+	}
+
+	if isLocked(user, time.Now()) {
+		s.logger.Warn("Locked user attempted to disable two-factor authentication", zap.String("user_id", user.ID))
+		return &LockedError{Until: *user.LockedUntil}
+	}
+
+	if !app.CheckPasswordHash(input.Password, user.PasswordHash) {
+		s.logger.Warn("Invalid password for disabling two-factor authentication", zap.String("user_id", user.ID))
+		s.registerFailedLogin(ctx, user)
+		return app.ErrInvalidCredentials
+	}
+
+	if err := s.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode); err != nil {
+		if !errors.Is(err, app.ErrInvalidMFACode) {
+			return err
+		}
+		s.registerFailedLogin(ctx, user)
+		return app.ErrInvalidCredentials
+	}
+
+	s.resetFailedLogins(ctx, user)
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.DisableTOTP(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.DeleteRecoveryCodes(ctx, user.ID)
+	})
+	if err != nil {
+		s.logger.Error("Failed to disable TOTP", zap.Error(err), zap.String("user_id", user.ID))
+		return app.ErrInternalServer
+	}
+
+	s.logger.Info("Two-factor authentication disabled", zap.String("user_id", user.ID))
+	return nil
+}
+
+// generateRecoveryCodes генерирует коды восстановления и их хеши для хранения
+func (s *Service) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, s.config.MFARecoveryCodes)
+	hashes := make([]string, 0, s.config.MFARecoveryCodes)
+	for range s.config.MFARecoveryCodes {
+		code, err := app.GenerateRecoveryCode()
+		if err != nil {
+			s.logger.Error("Failed to generate recovery code", zap.Error(err))
+			return nil, nil, app.ErrInternalServer
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, app.HashToken(app.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// mfaKey возвращает ключ шифрования секретов TOTP.
+// Без MFA_ENCRYPTION_KEY (допустимо только вне production) ключ выводится из JWT_SECRET
+func (s *Service) mfaKey() []byte {
+	if s.config.MFAEncryptionKey.IsSet() {
+		return s.config.MFAEncryptionKey.Bytes()
+	}
+	key := sha256.Sum256([]byte("mfa:" + s.config.JWTSecret))
+	return key[:]
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// mfaTokenType тип токена, выдаваемого после проверки пароля до ввода второго фактора
+const mfaTokenType = "mfa_pending"
+
+// MFALoginInput входные данные для завершения входа вторым фактором
+type MFALoginInput struct {
+	MFAToken     string
+	Code         string // Код TOTP
+	RecoveryCode string // Одноразовый код восстановления вместо кода TOTP
+	Device       domain.DeviceInfo
+}
+
+// LoginTwoFactor завершает вход пользователя с двухфакторной аутентификацией кодом TOTP или кодом восстановления
+func (s *Service) LoginTwoFactor(ctx context.Context, input MFALoginInput) (*domain.AuthTokens, error) {
+	if (input.Code == "") == (input.RecoveryCode == "") {
+		s.metrics.RecordUserLogin(false, "invalid_input")
+		return nil, app.ErrInvalidInput
+	}
+
+	userID, err := s.parseMFAToken(input.MFAToken, input.Device.DeviceID)
+	if err != nil {
+		s.metrics.RecordUserLogin(false, "invalid_mfa_token")
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.logger.Warn("User not found during two-factor login", zap.String("user_id", userID))
+		s.metrics.RecordUserLogin(false, "user_not_found")
+		return nil, app.ErrInvalidCredentials
+	}
+
+	// Статус мог измениться после проверки пароля
+	now := time.Now()
+	switch app.CurrentUserStatus(user, now) {
+	case domain.UserStatusInactive:
+		s.metrics.RecordUserLogin(false, "inactive")
+		return nil, app.ErrInvalidCredentials
+	case domain.UserStatusBanned:
+		s.metrics.RecordUserLogin(false, "banned")
+		return nil, app.ErrUserBanned
+	}
+
+	if !user.TOTPEnabled {
+		s.metrics.RecordUserLogin(false, "invalid_mfa_token")
+		return nil, app.ErrInvalidToken
+	}
+
+	// Перебор кодов ограничивается той же блокировкой, что и перебор паролей
+	if isLocked(user, now) {
+		s.logger.Warn("Locked user attempted two-factor login", zap.String("user_id", user.ID))
+		s.metrics.RecordUserLogin(false, "locked")
+		return nil, &LockedError{Until: *user.LockedUntil}
+	}
+
+	if err := s.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode); err != nil {
+		if errors.Is(err, app.ErrInvalidMFACode) {
+			s.metrics.RecordUserLogin(false, "invalid_mfa_code")
+			s.registerFailedLogin(ctx, user)
+		} else {
+			s.metrics.RecordUserLogin(false, "internal")
+		}
+		return nil, err
+	}
+
+	s.resetFailedLogins(ctx, user)
+
+	tokens, err := s.issueSession(ctx, user, input.Device, time.Time{})
+	if err != nil {
+		s.metrics.RecordUserLogin(false, "internal")
+		return nil, err
+	}
+
+	s.metrics.RecordUserLogin(true, "")
+	s.logger.Info("User logged in with two-factor authentication", zap.String("user_id", user.ID))
+	return tokens, nil
+}
+
+// issueMFAChallenge выдает короткоживущий токен, подтверждающий проверку пароля на устройстве
+func (s *Service) issueMFAChallenge(user *domain.User, device domain.DeviceInfo) (*domain.MFAChallenge, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.MFAPendingTokenTTL)
+
+	token, err := s.tokenKeys.Sign(jwt.MapClaims{
+		"jti":       app.GenerateUUID(),
+		"user_id":   user.ID,
+		"device_id": device.DeviceID,
+		"exp":       expiresAt.Unix(),
+		"iat":       now.Unix(),
+		"type":      mfaTokenType,
+	})
+	if err != nil {
+		s.logger.Error("Failed to sign MFA token", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, app.ErrInternalServer
+	}
+
+	return &domain.MFAChallenge{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// parseMFAToken проверяет токен ожидания второго фактора и возвращает ID пользователя
+func (s *Service) parseMFAToken(tokenString, deviceID string) (string, error) {
+	if tokenString == "" {
+		return "", app.ErrInvalidInput
+	}
+
+	token, err := jwt.Parse(tokenString, s.tokenKeys.Keyfunc)
+	if err != nil || !token.Valid {
+		s.logger.Warn("Invalid MFA token", zap.Error(err))
+		return "", app.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", app.ErrInvalidToken
+	}
+
+	if tokenType, _ := claims["type"].(string); tokenType != mfaTokenType {
+		s.logger.Warn("Invalid token type", zap.String("type", tokenType))
+		return "", app.ErrInvalidToken
+	}
+
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return "", app.ErrInvalidToken
+	}
+
+	// Второй фактор вводится на том же устройстве, где проверен пароль
+	if tokenDevice, _ := claims["device_id"].(string); tokenDevice != deviceID {
+		s.logger.Warn("MFA token used from another device", zap.String("user_id", userID))
+		return "", app.ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+// verifySecondFactor проверяет код TOTP или код восстановления
+func (s *Service) verifySecondFactor(ctx context.Context, user *domain.User, code, recoveryCode string) error {
+	if code != "" {
+		return s.verifyTOTP(ctx, user, code)
+	}
+	return s.useRecoveryCode(ctx, user, recoveryCode)
+}
+
+// verifyTOTP проверяет код TOTP и не допускает его повторного использования
+func (s *Service) verifyTOTP(ctx context.Context, user *domain.User, code string) error {
+	step, err := s.checkTOTPCode(user, code)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return app.ErrInternalServer
+	}
+	if !accepted {
+		s.logger.Warn("TOTP code reused", zap.String("user_id", user.ID))
+		return app.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// checkTOTPCode расшифровывает секрет пользователя и проверяет код, возвращая его шаг
+func (s *Service) checkTOTPCode(user *domain.User, code string) (int64, error) {
+	secret, err := app.DecryptString(s.mfaKey(), user.TOTPSecret)
+	if err != nil {
+		s.logger.Error("Failed to decrypt TOTP secret", zap.Error(err), zap.String("user_id", user.ID))
+		return 0, app.ErrInternalServer
+	}
+
+	step, ok := app.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		s.logger.Warn("Invalid TOTP code", zap.String("user_id", user.ID))
+		return 0, app.ErrInvalidMFACode
+	}
+
+	return step, nil
+}
+
+// useRecoveryCode погашает одноразовый код восстановления
+func (s *Service) useRecoveryCode(ctx context.Context, user *domain.User, recoveryCode string) error {
+	code := app.NormalizeRecoveryCode(recoveryCode)
+	if code == "" {
+		return app.ErrInvalidMFACode
+	}
+
+	used, err := s.recoveryCodeRepo.UseRecoveryCode(ctx, user.ID, app.HashToken(code))
+	if err != nil {
+		return app.ErrInternalServer
+	}
+	if !used {
+		s.logger.Warn("Invalid recovery code", zap.String("user_id", user.ID))
+		return app.ErrInvalidMFACode
+	}
+
+	s.logger.Info("Recovery code used", zap.String("user_id", user.ID))
+	return nil
+}
@@ -0,0 +1,450 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// enrollTestTOTP сохраняет пользователю зашифрованный секрет TOTP и возвращает текущий код
+func enrollTestTOTP(t *testing.T, s *Service, user *domain.User) string {
+	t.Helper()
+	secret, err := app.GenerateTOTPSecret()
+	require.NoError(t, err)
+	user.TOTPSecret, err = app.EncryptString(s.mfaKey(), secret)
+	require.NoError(t, err)
+	code, err := app.TOTPCode(secret, app.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+// expectIssueSession ожидает открытие сессии на устройстве device-1
+func expectIssueSession(m testMocks) {
+	m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+	m.sessions.EXPECT().DeleteSessionsByDevice(gomock.Any(), "u1", "device-1").Return(nil)
+	m.sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+	m.redis.EXPECT().SetRefreshToken(gomock.Any(), "u1", "device-1", gomock.Any(), 24*time.Hour).Return(nil)
+}
+
+func TestService_LoginTwoFactor(t *testing.T) {
+	device := domain.DeviceInfo{DeviceID: "device-1", AppType: "web"}
+	lockedUntil := time.Now().Add(time.Minute)
+	recoveryHash := app.HashToken(app.NormalizeRecoveryCode("abcd-efgh"))
+
+	tests := []struct {
+		name    string
+		input   func(input *MFALoginInput)
+		user    func(user *domain.User)
+		setup   func(m testMocks, user *domain.User)
+		wantErr error
+	}{
+		{
+			name: "totp code",
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+				expectIssueSession(m)
+				m.metrics.EXPECT().RecordUserLogin(true, "")
+			},
+		},
+		{
+			name: "recovery code resets failed attempts",
+			input: func(input *MFALoginInput) {
+				input.Code = ""
+				input.RecoveryCode = "ABCD-EFGH"
+			},
+			user: func(user *domain.User) { user.FailedLoginAttempts = 2 },
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.recoveryCodes.EXPECT().UseRecoveryCode(gomock.Any(), "u1", recoveryHash).Return(true, nil)
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+				expectIssueSession(m)
+				m.metrics.EXPECT().RecordUserLogin(true, "")
+			},
+		},
+		{
+			name:  "wrong code counts failure",
+			input: func(input *MFALoginInput) { input.Code = "000000" },
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_mfa_code")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name: "reused code counts failure",
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(false, nil)
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_mfa_code")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(5, nil)
+				m.users.EXPECT().LockUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name: "unknown recovery code counts failure",
+			input: func(input *MFALoginInput) {
+				input.Code = ""
+				input.RecoveryCode = "ABCD-EFGH"
+			},
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.recoveryCodes.EXPECT().UseRecoveryCode(gomock.Any(), "u1", recoveryHash).Return(false, nil)
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_mfa_code")
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name: "locked",
+			user: func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.metrics.EXPECT().RecordUserLogin(false, "locked")
+			},
+			wantErr: app.ErrAccountLocked,
+		},
+		{
+			name: "storage error is not counted",
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(false, errors.New("db unavailable"))
+				m.metrics.EXPECT().RecordUserLogin(false, "internal")
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name: "two-factor disabled after password check",
+			user: func(user *domain.User) { user.TOTPEnabled = false },
+			setup: func(m testMocks, user *domain.User) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_mfa_token")
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+		{
+			name:  "code and recovery code together",
+			input: func(input *MFALoginInput) { input.RecoveryCode = "ABCD-EFGH" },
+			setup: func(m testMocks, user *domain.User) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_input")
+			},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:  "token from another device",
+			input: func(input *MFALoginInput) { input.Device.DeviceID = "device-2" },
+			setup: func(m testMocks, user *domain.User) {
+				m.metrics.EXPECT().RecordUserLogin(false, "invalid_mfa_token")
+			},
+			wantErr: app.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, "Kx9!vQ2#mZ")
+			user.TOTPEnabled = true
+			code := enrollTestTOTP(t, s, user)
+			if tt.user != nil {
+				tt.user(user)
+			}
+			challenge, err := s.issueMFAChallenge(user, device)
+			require.NoError(t, err)
+
+			input := MFALoginInput{MFAToken: challenge.Token, Code: code, Device: device}
+			if tt.input != nil {
+				tt.input(&input)
+			}
+			tt.setup(m, user)
+
+			tokens, err := s.LoginTwoFactor(context.Background(), input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, tokens)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.AccessToken)
+			assert.NotEmpty(t, tokens.RefreshToken)
+		})
+	}
+}
+
+func TestService_ConfirmTOTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		user    func(user *domain.User)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "enables two-factor",
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.users.EXPECT().EnableTOTP(gomock.Any(), "u1", gomock.Any()).Return(nil)
+				m.recoveryCodes.EXPECT().ReplaceRecoveryCodes(gomock.Any(), "u1", gomock.Len(10)).Return(nil)
+			},
+		},
+		{
+			name:    "wrong code",
+			code:    "000000",
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name:    "already enabled",
+			user:    func(user *domain.User) { user.TOTPEnabled = true },
+			wantErr: app.ErrMFAAlreadyEnabled,
+		},
+		{
+			name:    "enrollment not started",
+			user:    func(user *domain.User) { user.TOTPSecret = "" },
+			wantErr: app.ErrMFANotEnabled,
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.users.EXPECT().EnableTOTP(gomock.Any(), "u1", gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, "Kx9!vQ2#mZ")
+			code := enrollTestTOTP(t, s, user)
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			codes, err := s.ConfirmTOTP(context.Background(), "u1", code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, codes)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, codes, 10)
+		})
+	}
+}
+
+func TestService_DisableTwoFactor(t *testing.T) {
+	const password = "Kx9!vQ2#mZ"
+	lockedUntil := time.Now().Add(time.Minute)
+	recoveryHash := app.HashToken(app.NormalizeRecoveryCode("abcd-efgh"))
+
+	// expectDisable ожидает отключение 2FA и удаление кодов восстановления
+	expectDisable := func(m testMocks) {
+		m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+		m.users.EXPECT().DisableTOTP(gomock.Any(), "u1").Return(nil)
+		m.recoveryCodes.EXPECT().DeleteRecoveryCodes(gomock.Any(), "u1").Return(nil)
+	}
+
+	tests := []struct {
+		name    string
+		input   func(input *DisableTwoFactorInput)
+		user    func(user *domain.User)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "totp code",
+			setup: func(m testMocks) {
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+				expectDisable(m)
+			},
+		},
+		{
+			name: "recovery code resets failed attempts",
+			input: func(input *DisableTwoFactorInput) {
+				input.Code = ""
+				input.RecoveryCode = "ABCD-EFGH"
+			},
+			user: func(user *domain.User) { user.FailedLoginAttempts = 2 },
+			setup: func(m testMocks) {
+				m.recoveryCodes.EXPECT().UseRecoveryCode(gomock.Any(), "u1", recoveryHash).Return(true, nil)
+				m.users.EXPECT().ResetFailedLogins(gomock.Any(), "u1").Return(nil)
+				expectDisable(m)
+			},
+		},
+		{
+			name:  "wrong password counts failure",
+			input: func(input *DisableTwoFactorInput) { input.Password = "wrong-password" },
+			setup: func(m testMocks) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:  "wrong code counts failure and looks like wrong password",
+			input: func(input *DisableTwoFactorInput) { input.Code = "000000" },
+			setup: func(m testMocks) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(5, nil)
+				m.users.EXPECT().LockUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name: "unknown recovery code counts failure",
+			input: func(input *DisableTwoFactorInput) {
+				input.Code = ""
+				input.RecoveryCode = "ABCD-EFGH"
+			},
+			setup: func(m testMocks) {
+				m.recoveryCodes.EXPECT().UseRecoveryCode(gomock.Any(), "u1", recoveryHash).Return(false, nil)
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidCredentials,
+		},
+		{
+			name:    "locked",
+			user:    func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			wantErr: app.ErrAccountLocked,
+		},
+		{
+			name:    "not enabled",
+			user:    func(user *domain.User) { user.TOTPEnabled = false },
+			wantErr: app.ErrMFANotEnabled,
+		},
+		{
+			name: "storage error is not counted",
+			setup: func(m testMocks) {
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(false, errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, password)
+			user.TOTPEnabled = true
+			code := enrollTestTOTP(t, s, user)
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			input := DisableTwoFactorInput{UserID: "u1", Password: password, Code: code}
+			if tt.input != nil {
+				tt.input(&input)
+			}
+
+			err := s.DisableTwoFactor(context.Background(), input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_RegenerateRecoveryCodes(t *testing.T) {
+	lockedUntil := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		code    string
+		user    func(user *domain.User)
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "replaces codes",
+			setup: func(m testMocks) {
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.recoveryCodes.EXPECT().ReplaceRecoveryCodes(gomock.Any(), "u1", gomock.Len(10)).Return(nil)
+			},
+		},
+		{
+			name: "wrong code counts failure",
+			code: "000000",
+			setup: func(m testMocks) {
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name: "reused code counts failure",
+			setup: func(m testMocks) {
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(false, nil)
+				m.users.EXPECT().RecordFailedLogin(gomock.Any(), "u1", time.Hour).Return(1, nil)
+			},
+			wantErr: app.ErrInvalidMFACode,
+		},
+		{
+			name:    "locked",
+			user:    func(user *domain.User) { user.LockedUntil = &lockedUntil },
+			wantErr: app.ErrAccountLocked,
+		},
+		{
+			name:    "not enabled",
+			user:    func(user *domain.User) { user.TOTPEnabled = false },
+			wantErr: app.ErrMFANotEnabled,
+		},
+		{
+			name: "storage error",
+			setup: func(m testMocks) {
+				m.users.EXPECT().UseTOTPStep(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+				m.tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				m.recoveryCodes.EXPECT().ReplaceRecoveryCodes(gomock.Any(), "u1", gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			user := testUser(t, "Kx9!vQ2#mZ")
+			user.TOTPEnabled = true
+			code := enrollTestTOTP(t, s, user)
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.user != nil {
+				tt.user(user)
+			}
+			m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			codes, err := s.RegenerateRecoveryCodes(context.Background(), "u1", code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, codes)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, codes, 10)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// HasPermission проверяет, есть ли право у пользователя через его роль или напрямую
+func (s *Service) HasPermission(ctx context.Context, userID string, role domain.UserRole, permission domain.Permission) (bool, error) {
+	if role != "" {
+		rolePermissions, err := s.rolePermissions(ctx, role)
+		if err != nil {
+			return false, err
+		}
+		if containsPermission(rolePermissions, permission) {
+			return true, nil
+		}
+	}
+
+	userPermissions, err := s.userPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return containsPermission(userPermissions, permission), nil
+}
+
+// EffectivePermissions возвращает все права пользователя: права роли и выданные напрямую
+func (s *Service) EffectivePermissions(ctx context.Context, userID string, role domain.UserRole) ([]domain.Permission, error) {
+	rolePermissions, err := s.rolePermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	userPermissions, err := s.userPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[domain.Permission]bool, len(rolePermissions)+len(userPermissions))
+	result := make([]domain.Permission, 0, len(rolePermissions)+len(userPermissions))
+	for _, permission := range append(rolePermissions, userPermissions...) {
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result, nil
+}
+
+// ListPermissions возвращает справочник всех известных прав
+func (s *Service) ListPermissions(ctx context.Context) ([]domain.Permission, error) {
+	permissions, err := s.permissionRepo.ListPermissions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list permissions", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+	return permissions, nil
+}
+
+// RolePermissions возвращает права, выданные роли
+func (s *Service) RolePermissions(ctx context.Context, role domain.UserRole) ([]domain.Permission, error) {
+	if !app.IsValidRole(role) {
+		return nil, app.ErrInvalidInput
+	}
+	return s.rolePermissions(ctx, role)
+}
+
+// GrantRolePermission выдает право роли и сбрасывает кеш прав роли
+func (s *Service) GrantRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error {
+	if !app.IsValidRole(role) || permission == "" {
+		return app.ErrInvalidInput
+	}
+
+	if err := s.permissionRepo.GrantRolePermission(ctx, role, permission); err != nil {
+		return s.mapRepoError(err, "Failed to grant role permission")
+	}
+
+	s.invalidate(ctx, domain.PermissionScopeRole, string(role))
+	s.logger.Info("Permission granted to role", zap.String("role", string(role)), zap.String("permission", string(permission)))
+	return nil
+}
+
+// RevokeRolePermission отзывает право у роли и сбрасывает кеш прав роли
+func (s *Service) RevokeRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error {
+	if !app.IsValidRole(role) || permission == "" {
+		return app.ErrInvalidInput
+	}
+
+	if err := s.permissionRepo.RevokeRolePermission(ctx, role, permission); err != nil {
+		return s.mapRepoError(err, "Failed to revoke role permission")
+	}
+
+	s.invalidate(ctx, domain.PermissionScopeRole, string(role))
+	s.logger.Info("Permission revoked from role", zap.String("role", string(role)), zap.String("permission", string(permission)))
+	return nil
+}
+
+// GrantUserPermission выдает право пользователю и сбрасывает кеш его прав
+func (s *Service) GrantUserPermission(ctx context.Context, userID string, permission domain.Permission) error {
+	if userID == "" || permission == "" {
+		return app.ErrInvalidInput
+	}
+
+	if err := s.permissionRepo.GrantUserPermission(ctx, userID, permission); err != nil {
+		return s.mapRepoError(err, "Failed to grant user permission")
+	}
+
+	s.invalidate(ctx, domain.PermissionScopeUser, userID)
+	s.logger.Info("Permission granted to user", zap.String("user_id", userID), zap.String("permission", string(permission)))
+	return nil
+}
+
+// RevokeUserPermission отзывает право у пользователя и сбрасывает кеш его прав
+func (s *Service) RevokeUserPermission(ctx context.Context, userID string, permission domain.Permission) error {
+	if userID == "" || permission == "" {
+		return app.ErrInvalidInput
+	}
+
+	if err := s.permissionRepo.RevokeUserPermission(ctx, userID, permission); err != nil {
+		return s.mapRepoError(err, "Failed to revoke user permission")
+	}
+
+	s.invalidate(ctx, domain.PermissionScopeUser, userID)
+	s.logger.Info("Permission revoked from user", zap.String("user_id", userID), zap.String("permission", string(permission)))
+	return nil
+}
+
+// rolePermissions возвращает права роли из кеша или БД
+func (s *Service) rolePermissions(ctx context.Context, role domain.UserRole) ([]domain.Permission, error) {
+	return s.cached(ctx, domain.PermissionScopeRole, string(role), func(ctx context.Context) ([]domain.Permission, error) {
+		return s.permissionRepo.GetRolePermissions(ctx, role)
+	})
+}
+
+// userPermissions возвращает права, выданные пользователю напрямую, из кеша или БД
+func (s *Service) userPermissions(ctx context.Context, userID string) ([]domain.Permission, error) {
+	return s.cached(ctx, domain.PermissionScopeUser, userID, func(ctx context.Context) ([]domain.Permission, error) {
+		return s.permissionRepo.GetUserPermissions(ctx, userID)
+	})
+}
+
+// cached читает права из Redis, а при промахе загружает их из БД и кеширует.
+// Недоступность кеша не блокирует проверку прав: запрос уходит в БД
+func (s *Service) cached(ctx context.Context, scope domain.PermissionScope, id string, load func(ctx context.Context) ([]domain.Permission, error)) ([]domain.Permission, error) {
+	permissions, found, err := s.cache.GetCachedPermissions(ctx, scope, id)
+	if err != nil {
+		s.logger.Warn("Permissions cache unavailable", zap.Error(err), zap.String("scope", string(scope)))
+	}
+	if found {
+		return permissions, nil
+	}
+
+	permissions, err = load(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load permissions", zap.Error(err), zap.String("scope", string(scope)), zap.String("id", id))
+		return nil, app.ErrInternalServer
+	}
+
+	if err := s.cache.SetCachedPermissions(ctx, scope, id, permissions, s.config.PermissionsCacheTTL); err != nil {
+		s.logger.Warn("Failed to cache permissions", zap.Error(err), zap.String("scope", string(scope)))
+	}
+
+	return permissions, nil
+}
+
+// invalidate сбрасывает кеш прав; при ошибке устаревшие права живут не дольше PermissionsCacheTTL
+func (s *Service) invalidate(ctx context.Context, scope domain.PermissionScope, id string) {
+	if err := s.cache.DeleteCachedPermissions(ctx, scope, id); err != nil {
+		s.logger.Error("Failed to invalidate permissions cache",
+			zap.Error(err),
+			zap.String("scope", string(scope)),
+			zap.Duration("stale_for", s.config.PermissionsCacheTTL),
+		)
+	}
+}
+
+// mapRepoError преобразует ошибки хранилища в ошибки приложения
+func (s *Service) mapRepoError(err error, msg string) error {
+	switch {
+	case errors.Is(err, domain.ErrPermissionNotFound):
+		return app.ErrPermissionNotFound
+	case errors.Is(err, domain.ErrUserNotFound):
+		return app.ErrUserNotFound
+	default:
+		s.logger.Error(msg, zap.Error(err))
+		return app.ErrInternalServer
+	}
+}
+
+// containsPermission проверяет наличие права в списке
+func containsPermission(permissions []domain.Permission, permission domain.Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/authz/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+const cacheTTL = 5 * time.Minute
+
+// newTestService создает сервис с моками хранилища и кеша
+func newTestService(t *testing.T) (*Service, *mock.MockPermissionRepository, *mock.MockPermissionCache) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockPermissionRepository(ctrl)
+	cache := mock.NewMockPermissionCache(ctrl)
+	return NewService(repo, cache, &config.Config{PermissionsCacheTTL: cacheTTL}, zap.NewNop()), repo, cache
+}
+
+func TestService_HasPermission(t *testing.T) {
+	errCache := errors.New("redis unavailable")
+
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		permission domain.Permission
+		setup      func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache)
+		want       bool
+		wantErr    error
+	}{
+		{
+			name:       "granted to role, cached",
+			role:       domain.UserRoleAdmin,
+			permission: domain.PermissionUsersWrite,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "admin").
+					Return([]domain.Permission{domain.PermissionUsersRead, domain.PermissionUsersWrite}, true, nil)
+			},
+			want: true,
+		},
+		{
+			name:       "granted to role, loaded and cached",
+			role:       domain.UserRoleAdmin,
+			permission: domain.PermissionUsersWrite,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				permissions := []domain.Permission{domain.PermissionUsersWrite}
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "admin").Return(nil, false, nil)
+				repo.EXPECT().GetRolePermissions(gomock.Any(), domain.UserRoleAdmin).Return(permissions, nil)
+				cache.EXPECT().SetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "admin", permissions, cacheTTL).Return(nil)
+			},
+			want: true,
+		},
+		{
+			name:       "granted to user directly",
+			role:       domain.UserRoleUser,
+			permission: domain.PermissionSessionsRevoke,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "user").Return(nil, true, nil)
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeUser, "user-1").
+					Return([]domain.Permission{domain.PermissionSessionsRevoke}, true, nil)
+			},
+			want: true,
+		},
+		{
+			name:       "not granted",
+			role:       domain.UserRoleUser,
+			permission: domain.PermissionUsersWrite,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "user").
+					Return([]domain.Permission{domain.PermissionUsersRead}, true, nil)
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeUser, "user-1").Return(nil, true, nil)
+			},
+			want: false,
+		},
+		{
+			name:       "token without role checks only user permissions",
+			permission: domain.PermissionUsersRead,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeUser, "user-1").Return(nil, true, nil)
+			},
+			want: false,
+		},
+		{
+			name:       "cache unavailable falls back to database",
+			role:       domain.UserRoleAdmin,
+			permission: domain.PermissionUsersRead,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "admin").Return(nil, false, errCache)
+				repo.EXPECT().GetRolePermissions(gomock.Any(), domain.UserRoleAdmin).Return([]domain.Permission{domain.PermissionUsersRead}, nil)
+				cache.EXPECT().SetCachedPermissions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errCache)
+			},
+			want: true,
+		},
+		{
+			name:       "database error",
+			role:       domain.UserRoleAdmin,
+			permission: domain.PermissionUsersRead,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				cache.EXPECT().GetCachedPermissions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
+				repo.EXPECT().GetRolePermissions(gomock.Any(), domain.UserRoleAdmin).Return(nil, errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, cache := newTestService(t)
+			tt.setup(repo, cache)
+
+			got, err := s.HasPermission(context.Background(), "user-1", tt.role, tt.permission)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_EffectivePermissions(t *testing.T) {
+	s, _, cache := newTestService(t)
+	cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "user").
+		Return([]domain.Permission{domain.PermissionUsersWrite, domain.PermissionUsersRead}, true, nil)
+	cache.EXPECT().GetCachedPermissions(gomock.Any(), domain.PermissionScopeUser, "user-1").
+		Return([]domain.Permission{domain.PermissionUsersRead, domain.PermissionSessionsRevoke}, true, nil)
+
+	got, err := s.EffectivePermissions(context.Background(), "user-1", domain.UserRoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Permission{domain.PermissionSessionsRevoke, domain.PermissionUsersRead, domain.PermissionUsersWrite}, got)
+}
+
+func TestService_GrantRolePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		permission domain.Permission
+		setup      func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache)
+		wantErr    error
+	}{
+		{
+			name:       "granted and cache invalidated",
+			role:       domain.UserRoleUser,
+			permission: domain.PermissionUsersRead,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				gomock.InOrder(
+					repo.EXPECT().GrantRolePermission(gomock.Any(), domain.UserRoleUser, domain.PermissionUsersRead).Return(nil),
+					cache.EXPECT().DeleteCachedPermissions(gomock.Any(), domain.PermissionScopeRole, "user").Return(nil),
+				)
+			},
+		},
+		{
+			name:       "cache invalidation failure is not an error",
+			role:       domain.UserRoleUser,
+			permission: domain.PermissionUsersRead,
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				repo.EXPECT().GrantRolePermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				cache.EXPECT().DeleteCachedPermissions(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("redis unavailable"))
+			},
+		},
+		{
+			name:       "unknown permission",
+			role:       domain.UserRoleUser,
+			permission: "reports:read",
+			setup: func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {
+				repo.EXPECT().GrantRolePermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.ErrPermissionNotFound)
+			},
+			wantErr: app.ErrPermissionNotFound,
+		},
+		{
+			name:       "invalid role",
+			role:       "root",
+			permission: domain.PermissionUsersRead,
+			setup:      func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {},
+			wantErr:    app.ErrInvalidInput,
+		},
+		{
+			name:    "empty permission",
+			role:    domain.UserRoleUser,
+			setup:   func(repo *mock.MockPermissionRepository, cache *mock.MockPermissionCache) {},
+			wantErr: app.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, cache := newTestService(t)
+			tt.setup(repo, cache)
+
+			err := s.GrantRolePermission(context.Background(), tt.role, tt.permission)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package authz
+
+//go:generate mockgen -source=external.go -destination=./mock/external.go -package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+)
+
+// PermissionRepository определяет интерфейс для хранения прав доступа
+type PermissionRepository interface {
+	ListPermissions(ctx context.Context) ([]domain.Permission, error)
+	GetRolePermissions(ctx context.Context, role domain.UserRole) ([]domain.Permission, error)
+	GetUserPermissions(ctx context.Context, userID string) ([]domain.Permission, error)
+	GrantRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error
+	RevokeRolePermission(ctx context.Context, role domain.UserRole, permission domain.Permission) error
+	GrantUserPermission(ctx context.Context, userID string, permission domain.Permission) error
+	RevokeUserPermission(ctx context.Context, userID string, permission domain.Permission) error
+}
+
+// PermissionCache определяет интерфейс кеша прав доступа
+type PermissionCache interface {
+	GetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) ([]domain.Permission, bool, error)
+	SetCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string, permissions []domain.Permission, ttl time.Duration) error
+	DeleteCachedPermissions(ctx context.Context, scope domain.PermissionScope, id string) error
+}
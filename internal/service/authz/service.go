@@ -0,0 +1,24 @@
+package authz
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// Service представляет сервис проверки прав доступа
+type Service struct {
+	permissionRepo PermissionRepository
+	cache          PermissionCache
+	config         *config.Config
+	logger         *zap.Logger
+}
+
+// NewService создает новый сервис прав доступа
+func NewService(permissionRepo PermissionRepository, cache PermissionCache, cfg *config.Config, logger *zap.Logger) *Service {
+	return &Service{
+		permissionRepo: permissionRepo,
+		cache:          cache,
+		config:         cfg,
+		logger:         logger,
+	}
+}
@@ -0,0 +1,44 @@
+package maintenance
+
+//go:generate mockgen -source=external.go -destination=./mock/external.go -package mock
+
+import (
+	"context"
+	"time"
+)
+
+// SessionRepository определяет интерфейс очистки сессий
+type SessionRepository interface {
+	DeleteExpiredSessions(ctx context.Context) error
+}
+
+// PasswordResetRepository определяет интерфейс очистки запросов на сброс пароля
+type PasswordResetRepository interface {
+	DeleteExpiredPasswordResets(ctx context.Context) error
+}
+
+// EmailVerificationRepository определяет интерфейс очистки запросов на подтверждение email
+type EmailVerificationRepository interface {
+	DeleteExpiredEmailVerifications(ctx context.Context) error
+}
+
+// EmailChangeRepository определяет интерфейс очистки запросов на смену email
+type EmailChangeRepository interface {
+	DeleteExpiredEmailChanges(ctx context.Context) error
+}
+
+// Locker определяет интерфейс блокировки, не дающей выполнять задачу на нескольких репликах одновременно
+type Locker interface {
+	RunWithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+// RunRepository определяет интерфейс учета запусков задач обслуживания
+type RunRepository interface {
+	GetMaintenanceRun(ctx context.Context, job string) (time.Time, error)
+	SetMaintenanceRun(ctx context.Context, job string, runAt time.Time) error
+}
+
+// Metrics определяет интерфейс записи метрик задач обслуживания
+type Metrics interface {
+	RecordMaintenanceJob(job, status string, duration time.Duration)
+}
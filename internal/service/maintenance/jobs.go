@@ -0,0 +1,71 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"go.uber.org/zap"
+)
+
+// Jobs возвращает названия всех задач обслуживания
+func Jobs() []string {
+	return []string{JobExpiredSessions, JobExpiredPasswordResets, JobExpiredVerifications, JobExpiredEmailChanges}
+}
+
+// Run выполняет задачу name вне расписания (например, из CLI).
+// Возвращает app.ErrJobLocked, если задача уже выполняется на другой реплике
+func (s *Service) Run(ctx context.Context, name string) error {
+	for _, job := range s.jobs {
+		if job.Name != name {
+			continue
+		}
+
+		ran, err := s.runLocked(ctx, job, true)
+		if err != nil {
+			return err
+		}
+		if !ran {
+			return app.ErrJobLocked
+		}
+		return nil
+	}
+
+	return app.ErrJobNotFound
+}
+
+// runLocked выполняет задачу под блокировкой и сохраняет время запуска в той же транзакции.
+// Плановый запуск (force=false) пропускается, если задачу уже выполнила другая реплика в текущем интервале
+// расписания. false - задача не выполнялась: блокировку держит другая реплика или запуск еще не нужен
+func (s *Service) runLocked(ctx context.Context, job Job, force bool) (bool, error) {
+	start := time.Now()
+	ran := false
+
+	_, err := s.locker.RunWithAdvisoryLock(ctx, lockPrefix+job.Name, func(ctx context.Context) error {
+		if !force {
+			lastRun, err := s.runRepo.GetMaintenanceRun(ctx, job.Name)
+			if err != nil {
+				return err
+			}
+			if !lastRun.IsZero() && job.Schedule.Next(lastRun).After(start) {
+				s.logger.Debug("Maintenance job already ran in this interval", zap.String("job", job.Name), zap.Time("last_run", lastRun))
+				return nil
+			}
+		}
+
+		if err := job.run(ctx); err != nil {
+			return err
+		}
+		ran = true
+		return s.runRepo.SetMaintenanceRun(ctx, job.Name, start)
+	})
+	if err != nil {
+		s.logger.Error("Maintenance job failed", zap.Error(err), zap.String("job", job.Name))
+		return false, err
+	}
+	if ran {
+		s.logger.Info("Maintenance job completed", zap.String("job", job.Name), zap.Duration("duration", time.Since(start)))
+	}
+
+	return ran, nil
+}
@@ -0,0 +1,145 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/service/maintenance/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testMocks содержит моки зависимостей сервиса
+type testMocks struct {
+	sessions      *mock.MockSessionRepository
+	resets        *mock.MockPasswordResetRepository
+	verifications *mock.MockEmailVerificationRepository
+	emailChanges  *mock.MockEmailChangeRepository
+	locker        *mock.MockLocker
+	runs          *mock.MockRunRepository
+	metrics       *mock.MockMetrics
+}
+
+// newTestService создает сервис с моками зависимостей
+func newTestService(t *testing.T, cfg *config.Config) (*Service, testMocks) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	m := testMocks{
+		sessions:      mock.NewMockSessionRepository(ctrl),
+		resets:        mock.NewMockPasswordResetRepository(ctrl),
+		verifications: mock.NewMockEmailVerificationRepository(ctrl),
+		emailChanges:  mock.NewMockEmailChangeRepository(ctrl),
+		locker:        mock.NewMockLocker(ctrl),
+		runs:          mock.NewMockRunRepository(ctrl),
+		metrics:       mock.NewMockMetrics(ctrl),
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	s := NewService(m.sessions, m.resets, m.verifications, m.emailChanges, m.locker, m.runs, m.metrics, cfg, zap.NewNop())
+	return s, m
+}
+
+// runUnderLock имитирует захват блокировки и выполняет задачу
+func runUnderLock(ctx context.Context, _ string, fn func(ctx context.Context) error) (bool, error) {
+	return true, fn(ctx)
+}
+
+func TestJobs(t *testing.T) {
+	s, _ := newTestService(t, nil)
+
+	names := make([]string, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	assert.Equal(t, Jobs(), names)
+}
+
+func TestService_Run(t *testing.T) {
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		name    string
+		job     string
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "expired sessions",
+			job:  JobExpiredSessions,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), "maintenance:expired_sessions", gomock.Any()).DoAndReturn(runUnderLock)
+				m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(nil)
+				m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "expired password resets",
+			job:  JobExpiredPasswordResets,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), "maintenance:expired_password_resets", gomock.Any()).DoAndReturn(runUnderLock)
+				m.resets.EXPECT().DeleteExpiredPasswordResets(gomock.Any()).Return(nil)
+				m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "expired email verifications",
+			job:  JobExpiredVerifications,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), "maintenance:expired_email_verifications", gomock.Any()).DoAndReturn(runUnderLock)
+				m.verifications.EXPECT().DeleteExpiredEmailVerifications(gomock.Any()).Return(nil)
+				m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "expired email changes",
+			job:  JobExpiredEmailChanges,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), "maintenance:expired_email_changes", gomock.Any()).DoAndReturn(runUnderLock)
+				m.emailChanges.EXPECT().DeleteExpiredEmailChanges(gomock.Any()).Return(nil)
+				m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "job fails",
+			job:  JobExpiredSessions,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(errDB)
+			},
+			wantErr: errDB,
+		},
+		{
+			name: "locked by another instance",
+			job:  JobExpiredSessions,
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			wantErr: app.ErrJobLocked,
+		},
+		{
+			name:    "unknown job",
+			job:     "vacuum",
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrJobNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t, nil)
+			tt.setup(m)
+
+			err := s.Run(context.Background(), tt.job)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
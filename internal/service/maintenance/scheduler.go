@@ -0,0 +1,101 @@
+package maintenance
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Start запускает задачи по расписанию; каждая задача работает в своей горутине
+func (s *Service) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		if !job.Schedule.Enabled() {
+			s.logger.Info("Maintenance job disabled", zap.String("job", job.Name))
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.schedule(ctx, job)
+	}
+}
+
+// Stop останавливает планировщик и дожидается завершения выполняющихся задач
+func (s *Service) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// schedule запускает задачу по расписанию со случайной задержкой до MAINTENANCE_JITTER,
+// чтобы одновременно стартовавшие реплики не конкурировали за блокировку. Реплики, опоздавшие
+// к запуску, пропускают его по сохраненному времени последнего запуска
+func (s *Service) schedule(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	// Задача с интервалом запускается и при старте: иначе при перезапусках чаще интервала она не выполнится ни разу
+	next := time.Now()
+	if job.Schedule.Every <= 0 {
+		next = job.Schedule.Next(next)
+	}
+
+	for {
+		if next.IsZero() {
+			s.logger.Warn("Maintenance job schedule has no upcoming runs", zap.String("job", job.Name), zap.Stringer("schedule", job.Schedule))
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next) + s.jitter())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runScheduled(ctx, job)
+		next = job.Schedule.Next(time.Now())
+	}
+}
+
+// runScheduled выполняет задачу и записывает метрики запуска
+func (s *Service) runScheduled(ctx context.Context, job Job) {
+	start := time.Now()
+
+	ran, err := s.runLocked(ctx, job, false)
+	switch {
+	case err != nil:
+		s.metrics.RecordMaintenanceJob(job.Name, "failed", time.Since(start))
+	case !ran:
+		s.logger.Debug("Maintenance job skipped: running or already done on another instance", zap.String("job", job.Name))
+		s.metrics.RecordMaintenanceJob(job.Name, "skipped", time.Since(start))
+	default:
+		s.metrics.RecordMaintenanceJob(job.Name, "success", time.Since(start))
+	}
+}
+
+// jitter возвращает случайную задержку в пределах MAINTENANCE_JITTER
+func (s *Service) jitter() time.Duration {
+	if s.config.MaintenanceJitter <= 0 {
+		return 0
+	}
+	return rand.N(s.config.MaintenanceJitter)
+}
@@ -0,0 +1,137 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_runScheduled(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		setup      func(m testMocks)
+		wantStatus string
+	}{
+		{
+			name: "first run",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				gomock.InOrder(
+					m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(time.Time{}, nil),
+					m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(nil),
+					m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), JobExpiredSessions, gomock.Any()).Return(nil),
+				)
+			},
+			wantStatus: "success",
+		},
+		{
+			name: "interval passed since last run",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(now.Add(-2*time.Hour), nil)
+				m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(nil)
+				m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), JobExpiredSessions, gomock.Any()).Return(nil)
+			},
+			wantStatus: "success",
+		},
+		{
+			name: "already ran on another instance in this interval",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(now.Add(-time.Minute), nil)
+			},
+			wantStatus: "skipped",
+		},
+		{
+			name: "failed",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(time.Time{}, nil)
+				m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantStatus: "failed",
+		},
+		{
+			name: "last run not read",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(runUnderLock)
+				m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(time.Time{}, errors.New("db unavailable"))
+			},
+			wantStatus: "failed",
+		},
+		{
+			name: "skipped when locked",
+			setup: func(m testMocks) {
+				m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			wantStatus: "skipped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schedule config.Schedule
+			require.NoError(t, schedule.UnmarshalText([]byte("1h")))
+			s, m := newTestService(t, &config.Config{MaintenanceSessionsSchedule: schedule})
+			tt.setup(m)
+			m.metrics.EXPECT().RecordMaintenanceJob(JobExpiredSessions, tt.wantStatus, gomock.Any())
+
+			s.runScheduled(context.Background(), s.jobs[0])
+		})
+	}
+}
+
+func TestService_Start(t *testing.T) {
+	schedule := func(t *testing.T, value string) config.Schedule {
+		t.Helper()
+		var s config.Schedule
+		require.NoError(t, s.UnmarshalText([]byte(value)))
+		return s
+	}
+
+	t.Run("interval job runs at start, disabled jobs do not run", func(t *testing.T) {
+		cfg := &config.Config{
+			MaintenanceSessionsSchedule: schedule(t, "1h"),
+			// Остальные расписания пустые - задачи отключены
+		}
+		s, m := newTestService(t, cfg)
+
+		done := make(chan struct{})
+		m.locker.EXPECT().RunWithAdvisoryLock(gomock.Any(), "maintenance:expired_sessions", gomock.Any()).DoAndReturn(runUnderLock)
+		m.runs.EXPECT().GetMaintenanceRun(gomock.Any(), JobExpiredSessions).Return(time.Time{}, nil)
+		m.sessions.EXPECT().DeleteExpiredSessions(gomock.Any()).Return(nil)
+		m.runs.EXPECT().SetMaintenanceRun(gomock.Any(), JobExpiredSessions, gomock.Any()).Return(nil)
+		m.metrics.EXPECT().RecordMaintenanceJob(JobExpiredSessions, "success", gomock.Any()).Do(func(string, string, time.Duration) { close(done) })
+
+		s.Start()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run at start")
+		}
+		assert.NoError(t, s.Stop(context.Background()))
+	})
+
+	t.Run("cron job waits for its time", func(t *testing.T) {
+		// Следующий запуск "@yearly" не раньше 1 января, поэтому задача не должна выполниться
+		cfg := &config.Config{MaintenanceSessionsSchedule: schedule(t, "@yearly")}
+		s, _ := newTestService(t, cfg)
+
+		s.Start()
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, s.Stop(context.Background()))
+	})
+
+	t.Run("stop without start", func(t *testing.T) {
+		s, _ := newTestService(t, nil)
+		assert.NoError(t, s.Stop(context.Background()))
+	})
+}
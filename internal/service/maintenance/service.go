@@ -0,0 +1,67 @@
+package maintenance
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// Названия задач обслуживания
+const (
+	JobExpiredSessions       = "expired_sessions"
+	JobExpiredPasswordResets = "expired_password_resets"
+	JobExpiredVerifications  = "expired_email_verifications"
+	JobExpiredEmailChanges   = "expired_email_changes"
+)
+
+// Префикс имени advisory-блокировки задачи
+const lockPrefix = "maintenance:"
+
+// Job представляет периодическую задачу обслуживания
+type Job struct {
+	Name     string
+	Schedule config.Schedule // Пустое расписание - задача не запускается планировщиком
+	run      func(ctx context.Context) error
+}
+
+// Service представляет сервис задач обслуживания и их планировщик
+type Service struct {
+	jobs    []Job
+	locker  Locker
+	runRepo RunRepository
+	metrics Metrics
+	config  *config.Config
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService создает сервис задач обслуживания с расписанием из конфигурации
+func NewService(
+	sessionRepo SessionRepository,
+	passwordResetRepo PasswordResetRepository,
+	verificationRepo EmailVerificationRepository,
+	emailChangeRepo EmailChangeRepository,
+	locker Locker,
+	runRepo RunRepository,
+	metrics Metrics,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		jobs: []Job{
+			{Name: JobExpiredSessions, Schedule: cfg.MaintenanceSessionsSchedule, run: sessionRepo.DeleteExpiredSessions},
+			{Name: JobExpiredPasswordResets, Schedule: cfg.MaintenancePasswordResetsSchedule, run: passwordResetRepo.DeleteExpiredPasswordResets},
+			{Name: JobExpiredVerifications, Schedule: cfg.MaintenanceVerificationsSchedule, run: verificationRepo.DeleteExpiredEmailVerifications},
+			{Name: JobExpiredEmailChanges, Schedule: cfg.MaintenanceEmailChangesSchedule, run: emailChangeRepo.DeleteExpiredEmailChanges},
+		},
+		locker:  locker,
+		runRepo: runRepo,
+		metrics: metrics,
+		config:  cfg,
+		logger:  logger,
+	}
+}
@@ -0,0 +1,38 @@
+package users
+
+//go:generate mockgen -source=external.go -destination=./mock/external.go -package mock
+
+import (
+	"context"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+)
+
+// UserRepository определяет интерфейс для работы с пользователями
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *domain.User) error
+	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+	ListUsers(ctx context.Context, filter domain.UserListFilter) ([]*domain.User, int, error)
+	UpdateUser(ctx context.Context, userID string, update domain.UserUpdate) error
+	DeleteUser(ctx context.Context, id string) error
+}
+
+// EmailChangeRepository определяет интерфейс отмены запросов на смену email
+type EmailChangeRepository interface {
+	CancelEmailChanges(ctx context.Context, userID string) error
+}
+
+// PasswordResetRepository определяет интерфейс отмены запросов на сброс пароля
+type PasswordResetRepository interface {
+	CancelPasswordResets(ctx context.Context, userID string) error
+}
+
+// TxManager определяет интерфейс для выполнения операций в одной транзакции
+type TxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SessionRevoker завершает все сессии пользователя и отзывает уже выданные ему токены
+type SessionRevoker interface {
+	TerminateUserSessions(ctx context.Context, userID string) error
+}
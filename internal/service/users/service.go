@@ -0,0 +1,42 @@
+package users
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// Service представляет сервис управления пользователями
+type Service struct {
+	userRepo          UserRepository
+	emailChangeRepo   EmailChangeRepository
+	passwordResetRepo PasswordResetRepository
+	txManager         TxManager
+	sessionRevoker    SessionRevoker
+	passwordPolicy    *app.PasswordPolicy
+	config            *config.Config
+	logger            *zap.Logger
+}
+
+// NewService создает новый сервис управления пользователями
+func NewService(
+	userRepo UserRepository,
+	emailChangeRepo EmailChangeRepository,
+	passwordResetRepo PasswordResetRepository,
+	txManager TxManager,
+	sessionRevoker SessionRevoker,
+	passwordPolicy *app.PasswordPolicy,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		userRepo:          userRepo,
+		emailChangeRepo:   emailChangeRepo,
+		passwordResetRepo: passwordResetRepo,
+		txManager:         txManager,
+		sessionRevoker:    sessionRevoker,
+		passwordPolicy:    passwordPolicy,
+		config:            cfg,
+		logger:            logger,
+	}
+}
@@ -0,0 +1,337 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Параметры пагинации списка пользователей
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// sortFields допустимые поля сортировки списка пользователей
+var sortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"email":      true,
+	"name":       true,
+}
+
+// ListUsersInput представляет параметры получения списка пользователей
+type ListUsersInput struct {
+	Page        int
+	Limit       int
+	Sort        string // Поле сортировки, префикс "-" означает сортировку по убыванию
+	Status      domain.UserStatus
+	EmailPrefix string
+}
+
+// ListUsersResult представляет страницу списка пользователей
+type ListUsersResult struct {
+	Users []*domain.User
+	Total int
+	Page  int
+	Limit int
+}
+
+// CreateUserInput представляет входные данные для создания пользователя
+type CreateUserInput struct {
+	Name          string
+	Email         string
+	Password      string
+	Role          domain.UserRole   // Пустая роль означает domain.UserRoleUser
+	Status        domain.UserStatus // Пустой статус означает domain.UserStatusActive
+	CreatedByRole domain.UserRole   // Роль создающего; другую роль, кроме user, может назначить только admin
+}
+
+// UpdateUserInput представляет входные данные для обновления пользователя (nil - поле не меняется).
+// Роль меняется отдельно, вместе с отзывом токенов пользователя
+type UpdateUserInput struct {
+	Name          *string
+	Email         *string
+	Status        *domain.UserStatus // Только active или inactive, блокировка выполняется отдельно
+	UpdatedByRole domain.UserRole    // Роль изменяющего; аккаунт admin может изменить только admin
+}
+
+// ListUsers возвращает список пользователей с пагинацией, сортировкой и фильтрацией
+func (s *Service) ListUsers(ctx context.Context, input ListUsersInput) (*ListUsersResult, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// По умолчанию сначала новые пользователи
+	sortBy, sortDesc := "created_at", true
+	if input.Sort != "" {
+		sortBy = strings.TrimPrefix(input.Sort, "-")
+		sortDesc = strings.HasPrefix(input.Sort, "-")
+		if !sortFields[sortBy] {
+			s.logger.Warn("Invalid sort field", zap.String("sort", input.Sort))
+			return nil, app.ErrInvalidInput
+		}
+	}
+
+	if input.Status != "" && !app.IsValidStatus(input.Status) {
+		s.logger.Warn("Invalid status filter", zap.String("status", string(input.Status)))
+		return nil, app.ErrInvalidInput
+	}
+
+	filter := domain.UserListFilter{
+		Status:      input.Status,
+		EmailPrefix: strings.TrimSpace(input.EmailPrefix),
+		SortBy:      sortBy,
+		SortDesc:    sortDesc,
+		Limit:       limit,
+		Offset:      (page - 1) * limit,
+	}
+
+	users, total, err := s.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list users", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	return &ListUsersResult{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// GetUser возвращает пользователя по ID
+func (s *Service) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, s.mapRepoError(err, "Failed to get user", id)
+	}
+
+	return user, nil
+}
+
+// CreateUser создает нового пользователя
+func (s *Service) CreateUser(ctx context.Context, input CreateUserInput) (*domain.User, error) {
+	// Валидация входных данных
+	if !app.ValidateName(input.Name) {
+		s.logger.Warn("Invalid name format", zap.String("name", input.Name))
+		return nil, app.ErrInvalidInput
+	}
+
+	if !app.ValidateEmail(input.Email) {
+		s.logger.Warn("Invalid email format", zap.String("email", input.Email))
+		return nil, app.ErrInvalidInput
+	}
+
+	if err := s.passwordPolicy.Validate(input.Password, input.Email, input.Name); err != nil {
+		s.logger.Warn("Password does not meet policy")
+		return nil, err
+	}
+
+	role := input.Role
+	if role == "" {
+		role = domain.UserRoleUser
+	}
+	if !app.IsValidRole(role) {
+		s.logger.Warn("Invalid role", zap.String("role", string(role)))
+		return nil, app.ErrInvalidInput
+	}
+	if role != domain.UserRoleUser && input.CreatedByRole != domain.UserRoleAdmin {
+		s.logger.Warn("Role assignment by non-admin", zap.String("role", string(role)))
+		return nil, app.ErrForbidden
+	}
+
+	// Хешируем пароль
+	passwordHash, err := app.HashPassword(input.Password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return nil, app.ErrInternalServer
+	}
+
+	status := input.Status
+	if status == "" {
+		status = domain.UserStatusActive
+	}
+	if !isAssignableStatus(status) {
+		s.logger.Warn("Invalid status", zap.String("status", string(status)))
+		return nil, app.ErrInvalidInput
+	}
+
+	now := time.Now()
+	user := &domain.User{
+		ID:           app.GenerateUUID(),
+		Email:        input.Email,
+		Name:         strings.TrimSpace(input.Name),
+		PasswordHash: passwordHash,
+		Role:         role,
+		Status:       status,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	// Уникальность email гарантируется ограничением в БД
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, s.mapRepoError(err, "Failed to create user", user.ID)
+	}
+
+	s.logger.Info("User created", zap.String("user_id", user.ID), zap.String("email", user.Email))
+	return user, nil
+}
+
+// UpdateUser обновляет данные пользователя
+func (s *Service) UpdateUser(ctx context.Context, id string, input UpdateUserInput) (*domain.User, error) {
+	if input.Email != nil {
+		// Адрес нормализуется так же, как при самостоятельной смене email
+		email := strings.TrimSpace(*input.Email)
+		input.Email = &email
+	}
+
+	if input.Name != nil && !app.ValidateName(*input.Name) {
+		s.logger.Warn("Invalid name format", zap.String("name", *input.Name))
+		return nil, app.ErrInvalidInput
+	}
+
+	if input.Email != nil && !app.ValidateEmail(*input.Email) {
+		s.logger.Warn("Invalid email format", zap.String("email", *input.Email))
+		return nil, app.ErrInvalidInput
+	}
+
+	if input.Status != nil && !isAssignableStatus(*input.Status) {
+		s.logger.Warn("Invalid status", zap.String("status", string(*input.Status)))
+		return nil, app.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, s.mapRepoError(err, "Failed to get user for update", id)
+	}
+
+	// Иначе смена email администратора позволила бы захватить его аккаунт через сброс пароля
+	if !app.CanManageUser(input.UpdatedByRole, user) {
+		s.logger.Warn("Attempt to update admin by non-admin", zap.String("user_id", id))
+		return nil, app.ErrForbidden
+	}
+
+	// В хранилище пишутся только измененные поля, чтобы не затереть параллельную блокировку или смену роли
+	var update domain.UserUpdate
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		update.Name = &name
+		user.Name = name
+	}
+	if input.Email != nil && *input.Email != user.Email {
+		// Владение новым адресом не подтверждено
+		update.Email = input.Email
+		user.Email = *input.Email
+		user.EmailVerifiedAt = nil
+	}
+	if input.Status != nil && *input.Status != user.Status {
+		// Действующую блокировку снимают только через unban, чтобы не потерять ее историю по ошибке
+		if app.CurrentUserStatus(user, time.Now()) == domain.UserStatusBanned {
+			s.logger.Warn("Attempt to change status of banned user", zap.String("user_id", id))
+			return nil, app.ErrInvalidInput
+		}
+		update.Status = input.Status
+		update.FromStatus = user.Status
+		user.Status = *input.Status
+		// Данные истекшей блокировки больше не нужны
+		user.BanReason = ""
+		user.BannedUntil = nil
+		user.BannedBy = ""
+		user.BannedAt = nil
+	}
+
+	// Ссылки из писем незавершенной смены email не должны перезаписать адрес, заданный администратором
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateUser(ctx, id, update); err != nil {
+			return err
+		}
+		if update.Email != nil {
+			if err := s.emailChangeRepo.CancelEmailChanges(ctx, id); err != nil {
+				return err
+			}
+		}
+		// Ссылка сброса пароля, выданная до деактивации, не должна вернуть доступ к аккаунту
+		if update.Status != nil && *update.Status == domain.UserStatusInactive {
+			return s.passwordResetRepo.CancelPasswordResets(ctx, id)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserStatusChanged) {
+			s.logger.Warn("User status changed concurrently", zap.String("user_id", id))
+			return nil, app.ErrInvalidInput
+		}
+		return nil, s.mapRepoError(err, "Failed to update user", id)
+	}
+
+	// Деактивированный пользователь теряет сессии и уже выданные access токены сразу
+	if update.Status != nil && *update.Status == domain.UserStatusInactive {
+		if err := s.sessionRevoker.TerminateUserSessions(ctx, id); err != nil {
+			s.logger.Error("User deactivated but sessions were not revoked", zap.Error(err), zap.String("user_id", id))
+			return nil, app.ErrInternalServer
+		}
+	}
+
+	s.logger.Info("User updated", zap.String("user_id", id))
+	return user, nil
+}
+
+// DeleteUser удаляет пользователя и отзывает его токены; администратора может удалить только администратор
+func (s *Service) DeleteUser(ctx context.Context, id string, deletedByRole domain.UserRole) error {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return s.mapRepoError(err, "Failed to get user for delete", id)
+	}
+
+	if !app.CanManageUser(deletedByRole, user) {
+		s.logger.Warn("Attempt to delete admin by non-admin", zap.String("user_id", id))
+		return app.ErrForbidden
+	}
+
+	if err := s.userRepo.DeleteUser(ctx, id); err != nil {
+		return s.mapRepoError(err, "Failed to delete user", id)
+	}
+
+	// Сессии удаляются каскадно, а refresh токены в Redis и access токены отзываем явно
+	if err := s.sessionRevoker.TerminateUserSessions(ctx, id); err != nil {
+		s.logger.Error("User deleted but tokens were not revoked", zap.Error(err), zap.String("user_id", id))
+		return app.ErrInternalServer
+	}
+
+	s.logger.Info("User deleted", zap.String("user_id", id))
+	return nil
+}
+
+// isAssignableStatus проверяет, что статус можно установить при создании или обновлении пользователя
+func isAssignableStatus(status domain.UserStatus) bool {
+	return status == domain.UserStatusActive || status == domain.UserStatusInactive
+}
+
+// mapRepoError преобразует ошибки хранилища в ошибки приложения
+func (s *Service) mapRepoError(err error, msg, userID string) error {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return app.ErrUserNotFound
+	case errors.Is(err, domain.ErrUserExists):
+		return app.ErrUserExists
+	default:
+		s.logger.Error(msg, zap.Error(err), zap.String("user_id", userID))
+		return app.ErrInternalServer
+	}
+}
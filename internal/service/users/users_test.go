@@ -0,0 +1,453 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/users/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testMocks содержит моки зависимостей сервиса
+type testMocks struct {
+	users        *mock.MockUserRepository
+	emailChanges *mock.MockEmailChangeRepository
+	resets       *mock.MockPasswordResetRepository
+	revoker      *mock.MockSessionRevoker
+}
+
+// newTestService создает сервис с моками хранилища и отзыва сессий. Транзакция выполняет функцию сразу
+func newTestService(t *testing.T) (*Service, testMocks) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	m := testMocks{
+		users:        mock.NewMockUserRepository(ctrl),
+		emailChanges: mock.NewMockEmailChangeRepository(ctrl),
+		resets:       mock.NewMockPasswordResetRepository(ctrl),
+		revoker:      mock.NewMockSessionRevoker(ctrl),
+	}
+	tx := mock.NewMockTxManager(ctrl)
+	tx.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+
+	s := NewService(m.users, m.emailChanges, m.resets, tx, m.revoker, &app.PasswordPolicy{MinLength: 8}, &config.Config{}, zap.NewNop())
+	return s, m
+}
+
+func TestNewService(t *testing.T) {
+	policy := &app.PasswordPolicy{MinLength: 10, ForbidCommon: true}
+
+	s := NewService(nil, nil, nil, nil, nil, policy, &config.Config{}, zap.NewNop())
+	assert.Same(t, policy, s.passwordPolicy)
+}
+
+func TestService_CreateUser(t *testing.T) {
+	valid := CreateUserInput{Name: "Anna", Email: "anna@example.com", Password: "Kx9!vQ2#mZ", CreatedByRole: domain.UserRoleAdmin}
+	with := func(change func(input *CreateUserInput)) CreateUserInput {
+		input := valid
+		change(&input)
+		return input
+	}
+
+	tests := []struct {
+		name       string
+		input      CreateUserInput
+		setup      func(m testMocks)
+		wantRole   domain.UserRole
+		wantStatus domain.UserStatus
+		wantErr    error
+	}{
+		{
+			name:       "defaults",
+			input:      valid,
+			setup:      func(m testMocks) { m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil) },
+			wantRole:   domain.UserRoleUser,
+			wantStatus: domain.UserStatusActive,
+		},
+		{
+			name:       "admin assigns role",
+			input:      with(func(input *CreateUserInput) { input.Role = domain.UserRoleAdmin }),
+			setup:      func(m testMocks) { m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil) },
+			wantRole:   domain.UserRoleAdmin,
+			wantStatus: domain.UserStatusActive,
+		},
+		{
+			name: "non-admin creates user with default role",
+			input: with(func(input *CreateUserInput) {
+				input.CreatedByRole = domain.UserRoleUser
+				input.Status = domain.UserStatusInactive
+			}),
+			setup:      func(m testMocks) { m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil) },
+			wantRole:   domain.UserRoleUser,
+			wantStatus: domain.UserStatusInactive,
+		},
+		{
+			name: "non-admin assigns admin role",
+			input: with(func(input *CreateUserInput) {
+				input.Role = domain.UserRoleAdmin
+				input.CreatedByRole = domain.UserRoleUser
+			}),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:    "non-admin assigns guest role",
+			input:   with(func(input *CreateUserInput) { input.Role = domain.UserRoleGuest; input.CreatedByRole = "" }),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:    "invalid role",
+			input:   with(func(input *CreateUserInput) { input.Role = "root" }),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "banned status",
+			input:   with(func(input *CreateUserInput) { input.Status = domain.UserStatusBanned }),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "invalid email",
+			input:   with(func(input *CreateUserInput) { input.Email = "anna" }),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "weak password",
+			input:   with(func(input *CreateUserInput) { input.Password = "short" }),
+			setup:   func(m testMocks) {},
+			wantErr: app.ErrWeakPassword,
+		},
+		{
+			name:  "email taken",
+			input: valid,
+			setup: func(m testMocks) {
+				m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(domain.ErrUserExists)
+			},
+			wantErr: app.ErrUserExists,
+		},
+		{
+			name:  "storage error",
+			input: valid,
+			setup: func(m testMocks) {
+				m.users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+
+			got, err := s.CreateUser(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, got.ID)
+			assert.Equal(t, tt.input.Email, got.Email)
+			assert.Equal(t, tt.wantRole, got.Role)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.True(t, app.CheckPasswordHash(tt.input.Password, got.PasswordHash))
+		})
+	}
+}
+
+func TestService_UpdateUser(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	status := func(s domain.UserStatus) *domain.UserStatus { return &s }
+	verifiedAt := time.Now().Add(-time.Hour)
+	bannedUntil := time.Now().Add(time.Hour)
+	expiredBan := time.Now().Add(-time.Hour)
+	active := domain.User{ID: "u1", Name: "Anna", Email: "anna@example.com", Role: domain.UserRoleUser, Status: domain.UserStatusActive}
+	admin := domain.User{ID: "u1", Name: "Anna", Email: "anna@example.com", Role: domain.UserRoleAdmin, Status: domain.UserStatusActive}
+
+	// expectUpdate ожидает запись только измененных полей
+	expectUpdate := func(update domain.UserUpdate) func(m testMocks) {
+		return func(m testMocks) {
+			m.users.EXPECT().UpdateUser(gomock.Any(), "u1", update).Return(nil)
+		}
+	}
+
+	// expectEmailUpdate ожидает смену email и отмену незавершенных запросов пользователя на смену
+	expectEmailUpdate := func(email string) func(m testMocks) {
+		return func(m testMocks) {
+			gomock.InOrder(
+				m.users.EXPECT().UpdateUser(gomock.Any(), "u1", domain.UserUpdate{Email: &email}).Return(nil),
+				m.emailChanges.EXPECT().CancelEmailChanges(gomock.Any(), "u1").Return(nil),
+			)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		stored  *domain.User
+		input   UpdateUserInput
+		setup   func(m testMocks)
+		check   func(t *testing.T, user *domain.User)
+		wantErr error
+	}{
+		{
+			name:   "name trimmed",
+			stored: &active,
+			input:  UpdateUserInput{Name: ptr("  Anna Maria ")},
+			setup:  expectUpdate(domain.UserUpdate{Name: ptr("Anna Maria")}),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, "Anna Maria", user.Name)
+			},
+		},
+		{
+			name:   "new email is unverified",
+			stored: &domain.User{ID: "u1", Name: "Anna", Email: "anna@example.com", EmailVerifiedAt: &verifiedAt, Status: domain.UserStatusActive},
+			input:  UpdateUserInput{Email: ptr("maria@example.com")},
+			setup:  expectEmailUpdate("maria@example.com"),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, "maria@example.com", user.Email)
+				assert.Nil(t, user.EmailVerifiedAt)
+			},
+		},
+		{
+			name:   "new email trimmed",
+			stored: &active,
+			input:  UpdateUserInput{Email: ptr("  maria@example.com ")},
+			setup:  expectEmailUpdate("maria@example.com"),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, "maria@example.com", user.Email)
+			},
+		},
+		{
+			name:   "pending email changes not cancelled",
+			stored: &active,
+			input:  UpdateUserInput{Email: ptr("maria@example.com")},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+				m.emailChanges.EXPECT().CancelEmailChanges(gomock.Any(), "u1").Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name:   "same email keeps verification",
+			stored: &domain.User{ID: "u1", Name: "Anna", Email: "anna@example.com", EmailVerifiedAt: &verifiedAt, Status: domain.UserStatusActive},
+			input:  UpdateUserInput{Email: ptr("anna@example.com")},
+			setup:  expectUpdate(domain.UserUpdate{}),
+			check: func(t *testing.T, user *domain.User) {
+				assert.NotNil(t, user.EmailVerifiedAt)
+			},
+		},
+		{
+			name: "expired ban cleared on status change",
+			stored: &domain.User{
+				ID: "u1", Name: "Anna", Email: "anna@example.com",
+				Status: domain.UserStatusBanned, BanReason: "spam", BannedUntil: &expiredBan, BannedBy: "admin",
+			},
+			input: UpdateUserInput{Status: status(domain.UserStatusActive)},
+			setup: expectUpdate(domain.UserUpdate{Status: status(domain.UserStatusActive), FromStatus: domain.UserStatusBanned}),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, domain.UserStatusActive, user.Status)
+				assert.Empty(t, user.BanReason)
+				assert.Nil(t, user.BannedUntil)
+				assert.Empty(t, user.BannedBy)
+			},
+		},
+		{
+			name:   "deactivation revokes sessions and password resets",
+			stored: &active,
+			input:  UpdateUserInput{Status: status(domain.UserStatusInactive)},
+			setup: func(m testMocks) {
+				gomock.InOrder(
+					m.users.EXPECT().UpdateUser(gomock.Any(), "u1", domain.UserUpdate{Status: status(domain.UserStatusInactive), FromStatus: domain.UserStatusActive}).Return(nil),
+					m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(nil),
+					m.revoker.EXPECT().TerminateUserSessions(gomock.Any(), "u1").Return(nil),
+				)
+			},
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, domain.UserStatusInactive, user.Status)
+			},
+		},
+		{
+			name:   "deactivated but sessions not revoked",
+			stored: &active,
+			input:  UpdateUserInput{Status: status(domain.UserStatusInactive)},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+				m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(nil)
+				m.revoker.EXPECT().TerminateUserSessions(gomock.Any(), "u1").Return(app.ErrInternalServer)
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name:   "password resets not cancelled",
+			stored: &active,
+			input:  UpdateUserInput{Status: status(domain.UserStatusInactive)},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUser(gomock.Any(), "u1", gomock.Any()).Return(nil)
+				m.resets.EXPECT().CancelPasswordResets(gomock.Any(), "u1").Return(errors.New("db unavailable"))
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name:   "banned concurrently",
+			stored: &active,
+			input:  UpdateUserInput{Status: status(domain.UserStatusInactive)},
+			setup: func(m testMocks) {
+				m.users.EXPECT().UpdateUser(gomock.Any(), "u1", gomock.Any()).Return(domain.ErrUserStatusChanged)
+			},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name: "active ban is not lifted",
+			stored: &domain.User{
+				ID: "u1", Name: "Anna", Email: "anna@example.com",
+				Status: domain.UserStatusBanned, BannedUntil: &bannedUntil,
+			},
+			input:   UpdateUserInput{Status: status(domain.UserStatusActive)},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "non-admin updates admin",
+			stored:  &admin,
+			input:   UpdateUserInput{Email: ptr("attacker@example.com"), UpdatedByRole: domain.UserRoleUser},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name:   "admin updates admin",
+			stored: &admin,
+			input:  UpdateUserInput{Email: ptr("maria@example.com"), UpdatedByRole: domain.UserRoleAdmin},
+			setup:  expectEmailUpdate("maria@example.com"),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, "maria@example.com", user.Email)
+			},
+		},
+		{
+			name:   "non-admin updates user",
+			stored: &active,
+			input:  UpdateUserInput{Name: ptr("Maria"), UpdatedByRole: domain.UserRoleUser},
+			setup:  expectUpdate(domain.UserUpdate{Name: ptr("Maria")}),
+			check: func(t *testing.T, user *domain.User) {
+				assert.Equal(t, "Maria", user.Name)
+			},
+		},
+		{
+			name:    "banned status is not assignable",
+			input:   UpdateUserInput{Status: status(domain.UserStatusBanned)},
+			wantErr: app.ErrInvalidInput,
+		},
+		{
+			name:    "invalid email",
+			input:   UpdateUserInput{Email: ptr("anna")},
+			wantErr: app.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			if tt.stored != nil {
+				stored := *tt.stored
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&stored, nil)
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			got, err := s.UpdateUser(context.Background(), "u1", tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, got)
+		})
+	}
+
+	t.Run("user not found", func(t *testing.T) {
+		s, m := newTestService(t)
+		m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+
+		_, err := s.UpdateUser(context.Background(), "u1", UpdateUserInput{Name: ptr("Anna")})
+		assert.ErrorIs(t, err, app.ErrUserNotFound)
+	})
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    domain.UserRole
+		setup   func(m testMocks)
+		wantErr error
+	}{
+		{
+			name: "non-admin deletes user",
+			role: domain.UserRoleUser,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+				m.users.EXPECT().DeleteUser(gomock.Any(), "u1").Return(nil)
+				m.revoker.EXPECT().TerminateUserSessions(gomock.Any(), "u1").Return(nil)
+			},
+		},
+		{
+			name: "admin deletes admin",
+			role: domain.UserRoleAdmin,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleAdmin}, nil)
+				m.users.EXPECT().DeleteUser(gomock.Any(), "u1").Return(nil)
+				m.revoker.EXPECT().TerminateUserSessions(gomock.Any(), "u1").Return(nil)
+			},
+		},
+		{
+			name: "non-admin deletes admin",
+			role: domain.UserRoleUser,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleAdmin}, nil)
+			},
+			wantErr: app.ErrForbidden,
+		},
+		{
+			name: "deleted but tokens not revoked",
+			role: domain.UserRoleAdmin,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Role: domain.UserRoleUser}, nil)
+				m.users.EXPECT().DeleteUser(gomock.Any(), "u1").Return(nil)
+				m.revoker.EXPECT().TerminateUserSessions(gomock.Any(), "u1").Return(app.ErrInternalServer)
+			},
+			wantErr: app.ErrInternalServer,
+		},
+		{
+			name: "user not found",
+			role: domain.UserRoleAdmin,
+			setup: func(m testMocks) {
+				m.users.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrUserNotFound)
+			},
+			wantErr: app.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestService(t)
+			tt.setup(m)
+
+			err := s.DeleteUser(context.Background(), "u1", tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
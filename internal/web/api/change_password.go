@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// changePassword меняет пароль текущего пользователя
+// @Summary Сменить пароль
+// @Description Меняет пароль после проверки текущего. Новый пароль не должен совпадать с PASSWORD_HISTORY_SIZE последними. При revoke_other_sessions завершает сессии на всех устройствах и возвращает новую пару токенов для текущего
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} ChangePasswordResponse "Пароль изменен"
+// @Failure 400 {object} ErrorResponse "Пароль не соответствует политике (violations) или использовался недавно"
+// @Failure 401 {object} ErrorResponse "Не авторизован или неверный текущий пароль"
+// @Failure 403 {object} ErrorResponse "Пользователь деактивирован или заблокирован"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов или вход временно заблокирован после неудачных попыток, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/change-password [post]
+func (s *Service) changePassword(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse change password request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	input := auth.ChangePasswordInput{
+		UserID:              userID,
+		CurrentPassword:     req.CurrentPassword,
+		NewPassword:         req.NewPassword,
+		RevokeOtherSessions: req.RevokeOtherSessions,
+		Device:              requestDevice(c),
+		Locale:              requestLocale(c),
+	}
+
+	tokens, err := s.authService.ChangePassword(c.Context(), input)
+	if err != nil {
+		s.logger.Warn("Password change failed", zap.Error(err), zap.String("user_id", userID))
+		return s.sendLoginError(c, err)
+	}
+
+	if tokens == nil {
+		return c.JSON(fiber.Map{
+			"message": "Password changed successfully",
+		})
+	}
+
+	// Прежний access токен отозван вместе с остальными, выдаем текущему устройству новый
+	s.setAccessTokenCookie(c, tokens.AccessToken)
+
+	return c.JSON(fiber.Map{
+		"message": "Password changed successfully, other sessions have been signed out",
+		"tokens": fiber.Map{
+			"access_token":  tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+		},
+	})
+}
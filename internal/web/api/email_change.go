@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// changeEmail запрашивает смену email текущего пользователя
+// @Summary Сменить email
+// @Description Проверяет текущий пароль, отправляет ссылку подтверждения на новый адрес и уведомление со ссылкой отмены на текущий. Email меняется только после подтверждения; новый запрос отменяет предыдущие
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ChangeEmailRequest true "Текущий пароль и новый email"
+// @Success 202 {object} MessageResponse "Письмо подтверждения отправлено на новый адрес"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или новый email совпадает с текущим"
+// @Failure 401 {object} ErrorResponse "Не авторизован или неверный пароль"
+// @Failure 403 {object} ErrorResponse "Пользователь деактивирован или заблокирован"
+// @Failure 409 {object} ErrorResponse "Email уже используется"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов или вход временно заблокирован после неудачных попыток, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/change-email [post]
+func (s *Service) changeEmail(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req ChangeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse change email request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	input := auth.ChangeEmailInput{
+		UserID:   userID,
+		Password: req.Password,
+		NewEmail: req.NewEmail,
+		Locale:   requestLocale(c),
+	}
+
+	if err := s.authService.RequestEmailChange(c.Context(), input); err != nil {
+		s.logger.Warn("Email change request failed", zap.Error(err), zap.String("user_id", userID))
+		return s.sendLoginError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Confirmation link has been sent to the new email",
+	})
+}
+
+// confirmEmailChange меняет email по токену из письма на новый адрес
+// @Summary Подтвердить смену email
+// @Description Меняет email по одноразовому токену из письма на новый адрес, отменяет ссылки сброса пароля и подтверждения email, отправленные на прежний адрес, и уведомляет его
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body EmailChangeTokenRequest true "Токен подтверждения"
+// @Success 200 {object} MessageResponse "Email изменен"
+// @Failure 400 {object} ErrorResponse "Неверный, истекший или использованный токен"
+// @Failure 409 {object} ErrorResponse "Email уже используется"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/change-email/confirm [post]
+func (s *Service) confirmEmailChange(c *fiber.Ctx) error {
+	var req EmailChangeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse confirm email change request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	if err := s.authService.ConfirmEmailChange(c.Context(), req.Token, requestLocale(c)); err != nil {
+		s.logger.Warn("Email change confirmation failed", zap.Error(err))
+		return sendEmailChangeError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email has been changed successfully",
+	})
+}
+
+// cancelEmailChange отменяет смену email по токену из уведомления на текущий адрес
+// @Summary Отменить смену email
+// @Description Отменяет неподтвержденный запрос на смену email по токену из уведомления, отправленного на текущий адрес
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body EmailChangeTokenRequest true "Токен отмены"
+// @Success 200 {object} MessageResponse "Смена email отменена"
+// @Failure 400 {object} ErrorResponse "Неверный, истекший или использованный токен"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/change-email/cancel [post]
+func (s *Service) cancelEmailChange(c *fiber.Ctx) error {
+	var req EmailChangeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse cancel email change request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	if err := s.authService.CancelEmailChange(c.Context(), req.Token); err != nil {
+		s.logger.Warn("Email change cancellation failed", zap.Error(err))
+		return sendEmailChangeError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email change has been cancelled",
+	})
+}
+
+// sendEmailChangeError отправляет ошибку ручек с токеном смены email:
+// любая проблема с токеном - ошибка запроса, а не авторизации
+func sendEmailChangeError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, app.ErrInvalidToken) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+	return sendError(c, err)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// verifyEmail подтверждает email по токену из письма
+// @Summary Подтвердить email
+// @Description Подтверждает владение email по одноразовому токену из письма, отправленного при регистрации
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Токен подтверждения"
+// @Success 200 {object} MessageResponse "Email подтвержден"
+// @Failure 400 {object} ErrorResponse "Неверный, истекший или использованный токен"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/verify-email [post]
+func (s *Service) verifyEmail(c *fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse verify email request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	if err := s.authService.VerifyEmail(c.Context(), req.Token, requestLocale(c)); err != nil {
+		s.logger.Warn("Email verification failed", zap.Error(err))
+		// Любая проблема с токеном - ошибка запроса, а не авторизации
+		code := fiber.StatusBadRequest
+		if errors.Is(err, app.ErrInternalServer) {
+			code = fiber.StatusInternalServerError
+		}
+		return c.Status(code).JSON(fiber.Map{
+			"error": err.Error(),
+			"code":  code,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email verified successfully",
+	})
+}
+
+// resendVerificationEmail повторно отправляет письмо подтверждения email
+// @Summary Повторно отправить письмо подтверждения
+// @Description Отправляет новое письмо со ссылкой подтверждения, если email зарегистрирован и еще не подтвержден. Ответ не раскрывает, существует ли аккаунт
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Email аккаунта"
+// @Success 200 {object} MessageResponse "Запрос принят"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/verify-email/resend [post]
+func (s *Service) resendVerificationEmail(c *fiber.Ctx) error {
+	var req ResendVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse resend verification request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	input := auth.ResendVerificationInput{
+		Email:  req.Email,
+		Locale: requestLocale(c),
+	}
+
+	if err := s.authService.ResendVerificationEmail(c.Context(), input); err != nil {
+		s.logger.Warn("Resend verification email failed", zap.Error(err), zap.String("email", req.Email))
+		return sendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the email is registered and not verified, a verification link has been sent",
+	})
+}
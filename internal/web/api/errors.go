@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorStatus возвращает HTTP статус, соответствующий ошибке приложения
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrInvalidInput), errors.Is(err, app.ErrPasswordResetExpired),
+		errors.Is(err, app.ErrPasswordResetUsed), errors.Is(err, app.ErrMFANotEnabled),
+		errors.Is(err, app.ErrVerificationExpired), errors.Is(err, app.ErrVerificationUsed),
+		errors.Is(err, app.ErrEmailChangeExpired), errors.Is(err, app.ErrEmailChangeUsed),
+		errors.Is(err, app.ErrPasswordReused), errors.Is(err, app.ErrWeakPassword):
+		return fiber.StatusBadRequest
+	case errors.Is(err, app.ErrInvalidCredentials), errors.Is(err, app.ErrUnauthorized),
+		errors.Is(err, app.ErrInvalidToken), errors.Is(err, app.ErrTokenExpired),
+		errors.Is(err, app.ErrInvalidMFACode):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, app.ErrForbidden), errors.Is(err, app.ErrUserBanned), errors.Is(err, app.ErrEmailNotVerified):
+		return fiber.StatusForbidden
+	case errors.Is(err, app.ErrUserNotFound), errors.Is(err, app.ErrSessionNotFound),
+		errors.Is(err, app.ErrPermissionNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, app.ErrUserExists), errors.Is(err, app.ErrMFAAlreadyEnabled), errors.Is(err, app.ErrEmailInUse),
+		errors.Is(err, app.ErrUserInactive):
+		return fiber.StatusConflict
+	case errors.Is(err, app.ErrTooManyRequests), errors.Is(err, app.ErrAccountLocked):
+		return fiber.StatusTooManyRequests
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// sendError отправляет JSON ответ с ошибкой и соответствующим ей статусом
+func sendError(c *fiber.Ctx, err error) error {
+	code := errorStatus(err)
+	return c.Status(code).JSON(errorBody(err, code))
+}
+
+// errorBody формирует тело ответа с ошибкой; нарушения политики паролей перечисляются по правилам,
+// чтобы клиент мог показать их у поля ввода
+func errorBody(err error, code int) fiber.Map {
+	body := fiber.Map{
+		"error": err.Error(),
+		"code":  code,
+	}
+	var policyErr *app.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		body["violations"] = policyErr.Violations
+	}
+	return body
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "invalid input", err: app.ErrInvalidInput, want: fiber.StatusBadRequest},
+		{name: "weak password", err: &app.PasswordPolicyError{}, want: fiber.StatusBadRequest},
+		{name: "invalid credentials", err: app.ErrInvalidCredentials, want: fiber.StatusUnauthorized},
+		{name: "invalid token", err: app.ErrInvalidToken, want: fiber.StatusUnauthorized},
+		{name: "banned user", err: app.ErrUserBanned, want: fiber.StatusForbidden},
+		{name: "inactive user", err: app.ErrForbidden, want: fiber.StatusForbidden},
+		{name: "unverified email", err: app.ErrEmailNotVerified, want: fiber.StatusForbidden},
+		{name: "user not found", err: app.ErrUserNotFound, want: fiber.StatusNotFound},
+		{name: "user exists", err: app.ErrUserExists, want: fiber.StatusConflict},
+		{name: "wrapped user exists", err: fmt.Errorf("register: %w", app.ErrUserExists), want: fiber.StatusConflict},
+		{name: "ban of inactive user", err: app.ErrUserInactive, want: fiber.StatusConflict},
+		{name: "too many requests", err: app.ErrTooManyRequests, want: fiber.StatusTooManyRequests},
+		{name: "unknown error", err: errors.New("boom"), want: fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorStatus(tt.err))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestLocale определяет язык писем по заголовку Accept-Language (ru или en)
+func requestLocale(c *fiber.Ctx) string {
+	for _, lang := range strings.Split(c.Get(fiber.HeaderAcceptLanguage), ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(lang, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, "ru"):
+			return "ru"
+		case strings.HasPrefix(tag, "en"):
+			return "en"
+		}
+	}
+	// Пустая локаль - используется локаль по умолчанию из конфигурации
+	return ""
+}
+
+// requestDevice собирает данные устройства из заголовков, сохраненных ValidateHeaders
+func requestDevice(c *fiber.Ctx) domain.DeviceInfo {
+	device := domain.DeviceInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	if deviceID, ok := c.Locals("device_id").(string); ok {
+		device.DeviceID = deviceID
+	}
+	if appType, ok := c.Locals("app_type").(string); ok {
+		device.AppType = appType
+	}
+	if appVersion, ok := c.Locals("app_version").(string); ok {
+		device.AppVersion = appVersion
+	}
+	return device
+}
+
+// setAccessTokenCookie устанавливает куки с access токеном
+func (s *Service) setAccessTokenCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     app.JWTCookieName,
+		Value:    token,
+		HTTPOnly: true,
+		Secure:   s.config.ServerHost != "localhost", // Secure только для продакшена
+		SameSite: "Lax",
+		MaxAge:   int(s.config.JWTExpiration.Seconds()),
+	})
+}
+
+// clearAccessTokenCookie удаляет куки с access токеном
+func (s *Service) clearAccessTokenCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     app.JWTCookieName,
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   s.config.ServerHost != "localhost",
+		SameSite: "Lax",
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+	})
+}
@@ -0,0 +1,18 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// getJWKS возвращает открытые ключи проверки подписи JWT
+// @Summary Получить JWKS
+// @Description Возвращает открытые ключи (RS256, ES256, EdDSA), которыми проверяются access и refresh токены. При подписи HS256 список пуст
+// @Tags auth
+// @Produce json
+// @Success 200 {object} domain.JSONWebKeySet "Набор ключей"
+// @Router /.well-known/jwks.json [get]
+func (s *Service) getJWKS(c *fiber.Ctx) error {
+	// Проверяющие сервисы кешируют набор ключей и перечитывают его при встрече неизвестного kid
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(s.authService.PublicKeys())
+}
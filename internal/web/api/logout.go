@@ -0,0 +1,77 @@
+package api
+
+import (
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// logout завершает сессию текущего устройства
+// @Summary Выйти из системы
+// @Description Завершает сессию текущего устройства, отзывает access токен и удаляет куки
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} MessageResponse "Выход выполнен"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/logout [post]
+func (s *Service) logout(c *fiber.Ctx) error {
+	input := logoutInput(c)
+
+	if err := s.authService.Logout(c.Context(), input); err != nil {
+		s.logger.Warn("Logout failed", zap.Error(err), zap.String("user_id", input.UserID))
+		return sendError(c, err)
+	}
+
+	s.clearAccessTokenCookie(c)
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
+// logoutAll завершает все сессии пользователя
+// @Summary Выйти на всех устройствах
+// @Description Завершает все сессии пользователя и отзывает все refresh токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} MessageResponse "Все сессии завершены"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/logout-all [post]
+func (s *Service) logoutAll(c *fiber.Ctx) error {
+	input := logoutInput(c)
+
+	if err := s.authService.LogoutAll(c.Context(), input); err != nil {
+		s.logger.Warn("Logout from all devices failed", zap.Error(err), zap.String("user_id", input.UserID))
+		return sendError(c, err)
+	}
+
+	s.clearAccessTokenCookie(c)
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out from all devices successfully",
+	})
+}
+
+// logoutInput собирает данные текущего токена, сохраненные JWTAuth
+func logoutInput(c *fiber.Ctx) auth.LogoutInput {
+	input := auth.LogoutInput{
+		UserID:        c.Locals("user_id").(string),
+		AccessTokenID: c.Locals("token_id").(string),
+	}
+	if sessionID, ok := c.Locals("session_id").(string); ok {
+		input.SessionID = sessionID
+	}
+	if deviceID, ok := c.Locals("device_id").(string); ok {
+		input.DeviceID = deviceID
+	}
+	if expiresAt, ok := c.Locals("token_expires_at").(time.Time); ok {
+		input.AccessTokenExpiresAt = expiresAt
+	}
+	return input
+}
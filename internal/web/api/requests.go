@@ -1,11 +1,36 @@
 package api
 
+import (
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+)
+
 // LoginRequest запрос на вход
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// LoginTwoFactorRequest запрос на завершение входа вторым фактором (указывается code или recovery_code)
+type LoginTwoFactorRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code,omitempty" validate:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// TwoFactorCodeRequest запрос с кодом TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// DisableTwoFactorRequest запрос на отключение двухфакторной аутентификации (указывается code или recovery_code)
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" validate:"required"`
+	Code         string `json:"code,omitempty" validate:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
 // RegisterRequest запрос на регистрацию
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,min=1,max=100"`
@@ -18,11 +43,73 @@ type ResetPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
+// ConfirmResetPasswordRequest запрос на подтверждение сброса пароля
+type ConfirmResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6,max=128"`
+}
+
+// VerifyEmailRequest запрос на подтверждение email
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest запрос на повторную отправку письма подтверждения
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ChangePasswordRequest запрос на смену пароля
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"current_password" validate:"required"`
+	NewPassword         string `json:"new_password" validate:"required"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions"` // Завершить сессии на других устройствах
+}
+
+// ChangeEmailRequest запрос на смену email
+type ChangeEmailRequest struct {
+	Password string `json:"password" validate:"required"`
+	NewEmail string `json:"new_email" validate:"required,email"`
+}
+
+// EmailChangeTokenRequest запрос на подтверждение или отмену смены email
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 // RefreshTokenRequest запрос на обновление токена
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// CreateUserRequest запрос на создание пользователя
+type CreateUserRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=100"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=6,max=128"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=admin user guest"`
+	Status   string `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+}
+
+// UpdateUserRequest запрос на обновление пользователя (передаются только изменяемые поля)
+type UpdateUserRequest struct {
+	Name   *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Email  *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Role   *string `json:"role,omitempty"` // Не принимается: роль меняется через PUT /users/{id}/role
+	Status *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+}
+
+// SetUserRoleRequest запрос на смену роли пользователя
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin user guest"`
+}
+
+// BanUserRequest запрос на блокировку пользователя
+type BanUserRequest struct {
+	Reason string     `json:"reason" validate:"max=500"`
+	Until  *time.Time `json:"until,omitempty"` // RFC3339, без значения - бессрочно
+}
+
 // LoginResponse ответ на вход
 type LoginResponse struct {
 	Message string `json:"message"`
@@ -31,6 +118,31 @@ type LoginResponse struct {
 	} `json:"user"`
 }
 
+// MFARequiredResponse ответ на вход по паролю, когда требуется код второго фактора
+type MFARequiredResponse struct {
+	Message     string `json:"message"`
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`  // Передается в /auth/login/2fa
+	ExpiresAt   string `json:"expires_at"` // RFC3339
+}
+
+// TOTPEnrollmentResponse данные для подключения приложения-аутентификатора
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`      // base32, для ручного ввода
+	OTPAuthURI string `json:"otpauth_uri"` // Для QR-кода
+}
+
+// RecoveryCodesResponse одноразовые коды восстановления, показываются один раз
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatusResponse состояние двухфакторной аутентификации
+type TwoFactorStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
 // RegisterResponse ответ на регистрацию
 type RegisterResponse struct {
 	Message string       `json:"message"`
@@ -46,20 +158,73 @@ type RefreshTokenResponse struct {
 	} `json:"tokens"`
 }
 
+// ChangePasswordResponse ответ на смену пароля
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+	Tokens  *struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	} `json:"tokens,omitempty"` // Новая пара токенов текущего устройства, только при revoke_other_sessions
+}
+
 // UserResponse информация о пользователе
 type UserResponse struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Name      string `json:"name"`
-	IsActive  bool   `json:"is_active"`
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	Status      string `json:"status"`
+	BanReason   string `json:"ban_reason,omitempty"`
+	BannedUntil string `json:"banned_until,omitempty"`
+	BannedBy    string `json:"banned_by,omitempty"`
+
+	FailedLoginAttempts int    `json:"failed_login_attempts"`  // Неудачные попытки входа подряд
+	LockedUntil         string `json:"locked_until,omitempty"` // Вход запрещен до указанного времени
+	TwoFactorEnabled    bool   `json:"two_factor_enabled"`
+	EmailVerified       bool   `json:"email_verified"`
+	EmailVerifiedAt     string `json:"email_verified_at,omitempty"`
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
 
+// CurrentUserResponse ответ с текущим пользователем
+type CurrentUserResponse struct {
+	User UserResponse `json:"user"`
+}
+
+// UsersListResponse страница списка пользователей
+type UsersListResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int            `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+// SessionResponse информация о сессии устройства
+type SessionResponse struct {
+	ID         string `json:"id"`
+	DeviceID   string `json:"device_id"`
+	AppType    string `json:"app_type"`
+	AppVersion string `json:"app_version"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	LastSeenAt string `json:"last_seen_at"`
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"` // Сессия, которой принадлежит текущий токен
+}
+
+// SessionsListResponse список сессий пользователя
+type SessionsListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
 // ErrorResponse ошибка API
 type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  int    `json:"code"`
+	Error      string                  `json:"error"`
+	Code       int                     `json:"code"`
+	Violations []app.PasswordViolation `json:"violations,omitempty"` // Нарушенные правила политики паролей
 }
 
 // MessageResponse простое сообщение
@@ -1,8 +1,17 @@
 package api
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
 	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
 	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/TeDenis/bukhindor-backend/internal/service/authz"
+	"github.com/TeDenis/bukhindor-backend/internal/service/users"
 	"github.com/TeDenis/bukhindor-backend/internal/web/middleware"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -10,43 +19,93 @@ import (
 
 // Service представляет API сервис
 type Service struct {
-	config      *config.Config
-	logger      *zap.Logger
-	authService *auth.Service
+	config       *config.Config
+	logger       *zap.Logger
+	authService  *auth.Service
+	usersService *users.Service
+	authzService *authz.Service
+	rateLimiter  middleware.RateLimiter
 }
 
 // NewService создает новый API сервис
-func NewService(cfg *config.Config, logger *zap.Logger, authService *auth.Service) *Service {
+func NewService(cfg *config.Config, logger *zap.Logger, authService *auth.Service, usersService *users.Service, authzService *authz.Service, rateLimiter middleware.RateLimiter) *Service {
 	return &Service{
-		config:      cfg,
-		logger:      logger,
-		authService: authService,
+		config:       cfg,
+		logger:       logger,
+		authService:  authService,
+		usersService: usersService,
+		authzService: authzService,
+		rateLimiter:  rateLimiter,
 	}
 }
 
 // SetupRoutes настраивает API роуты
 func (s *Service) SetupRoutes(app *fiber.App) {
+	// Открытые ключи проверки токенов для других сервисов
+	app.Get("/.well-known/jwks.json", s.getJWKS)
+
 	// API группа с валидацией заголовков
 	api := app.Group("/api/v1", middleware.ValidateHeaders())
 
 	// Аутентификация (без авторизации)
+	// Ручки, доступные без токена, защищены от перебора ограничением частоты запросов
 	auth := api.Group("/auth")
-	auth.Post("/login", s.login)
-	auth.Post("/register", s.register)
-	auth.Post("/reset-password", s.resetPassword)
-	auth.Post("/refresh", s.refreshTokens)
+	auth.Post("/login", s.rateLimit("login", s.config.RateLimitLogin, middleware.RateLimitByIP, middleware.RateLimitByEmail), s.login)
+	auth.Post("/register", s.rateLimit("register", s.config.RateLimitRegister, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.register)
+	auth.Post("/reset-password", s.rateLimit("reset_password", s.config.RateLimitResetPassword, middleware.RateLimitByIP, middleware.RateLimitByEmail), s.resetPassword)
+	auth.Post("/reset-password/confirm", s.rateLimit("reset_password_confirm", s.config.RateLimitConfirmToken, middleware.RateLimitByIP), s.confirmResetPassword)
+	auth.Post("/verify-email", s.rateLimit("verify_email_confirm", s.config.RateLimitConfirmToken, middleware.RateLimitByIP), s.verifyEmail)
+	auth.Post("/verify-email/resend", s.rateLimit("verify_email", s.config.RateLimitVerifyEmail, middleware.RateLimitByIP, middleware.RateLimitByEmail), s.resendVerificationEmail)
+	auth.Post("/change-email/confirm", s.rateLimit("change_email_confirm", s.config.RateLimitConfirmToken, middleware.RateLimitByIP), s.confirmEmailChange)
+	auth.Post("/change-email/cancel", s.rateLimit("change_email_cancel", s.config.RateLimitConfirmToken, middleware.RateLimitByIP), s.cancelEmailChange)
+	auth.Post("/refresh", s.rateLimit("refresh", s.config.RateLimitRefresh, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.refreshTokens)
+	auth.Post("/login/2fa", s.rateLimit("login_2fa", s.config.RateLimitLogin2FA, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.loginTwoFactor)
 
 	// Защищенные роуты (с авторизацией)
 	// Применяем JWT только к конкретному маршруту, чтобы не требовать токен на public-ручках
-	auth.Get("/me", middleware.JWTAuth(s.config, s.logger), s.getCurrentUser)
-
-	// Пользователи (защищенные)
-	users := api.Group("/users", middleware.JWTAuth(s.config, s.logger))
-	users.Get("/", s.getUsers)
-	users.Post("/", s.createUser)
-	users.Get("/:id", s.getUser)
-	users.Put("/:id", s.updateUser)
-	users.Delete("/:id", s.deleteUser)
+	jwtAuth := middleware.JWTAuth(s.config, s.logger, s.authService, s.authService)
+	auth.Get("/me", jwtAuth, s.getCurrentUser)
+	auth.Post("/logout", jwtAuth, s.logout)
+	auth.Post("/logout-all", jwtAuth, s.logoutAll)
+	auth.Get("/sessions", jwtAuth, s.getSessions)
+	auth.Delete("/sessions/:id", jwtAuth, s.deleteSession)
+	auth.Post("/change-password", jwtAuth, s.rateLimit("change_password", s.config.RateLimitChangePassword, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.changePassword)
+	auth.Post("/change-email", jwtAuth, s.rateLimit("change_email", s.config.RateLimitChangeEmail, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.changeEmail)
+
+	// Двухфакторная аутентификация
+	twoFactor := auth.Group("/2fa", jwtAuth)
+	twoFactor.Get("/", s.getTwoFactorStatus)
+	twoFactor.Post("/enroll", s.enrollTwoFactor)
+	twoFactor.Post("/confirm", s.rateLimit("2fa_confirm", s.config.RateLimitTwoFactor, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.confirmTwoFactor)
+	twoFactor.Post("/recovery-codes", s.rateLimit("2fa_recovery_codes", s.config.RateLimitTwoFactor, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.regenerateRecoveryCodes)
+	twoFactor.Post("/disable", s.rateLimit("2fa_disable", s.config.RateLimitTwoFactor, middleware.RateLimitByIP, middleware.RateLimitByDevice), s.disableTwoFactor)
+
+	// Пользователи (управление чужими аккаунтами требует соответствующих прав)
+	canRead := middleware.RequirePermission(s.logger, s.authzService, domain.PermissionUsersRead)
+	canWrite := middleware.RequirePermission(s.logger, s.authzService, domain.PermissionUsersWrite)
+	canRevokeSessions := middleware.RequirePermission(s.logger, s.authzService, domain.PermissionSessionsRevoke)
+	canBan := middleware.RequirePermission(s.logger, s.authzService, domain.PermissionUsersBan)
+
+	users := api.Group("/users", jwtAuth)
+	users.Get("/", canRead, s.getUsers)
+	users.Post("/", canWrite, s.createUser)
+	users.Get("/:id", canRead, s.getUser)
+	users.Put("/:id", canWrite, s.updateUser)
+	users.Put("/:id/role", middleware.RequireRole(s.logger, domain.UserRoleAdmin), s.setUserRole)
+	users.Delete("/:id", canWrite, s.deleteUser)
+	users.Delete("/:id/sessions", canRevokeSessions, s.revokeUserSessions)
+	users.Post("/:id/ban", canBan, s.banUser)
+	users.Post("/:id/unban", canBan, s.unbanUser)
+	users.Post("/:id/unlock", canBan, s.unlockUser)
+}
+
+// rateLimit создает middleware ограничения частоты запросов для ручки name
+func (s *Service) rateLimit(name string, limit config.RateLimit, keys ...middleware.RateLimitKey) fiber.Handler {
+	return middleware.RateLimit(s.config, s.logger, s.rateLimiter, middleware.RateLimitRule{
+		Name:  name,
+		Limit: limit,
+		Keys:  keys,
+	})
 }
 
 // login выполняет аутентификацию пользователя
@@ -57,8 +116,11 @@ func (s *Service) SetupRoutes(app *fiber.App) {
 // @Produce json
 // @Param credentials body LoginRequest true "Данные для входа"
 // @Success 200 {object} LoginResponse "Успешная аутентификация"
+// @Success 202 {object} MFARequiredResponse "Пароль верный, требуется код второго фактора"
 // @Failure 400 {object} ErrorResponse "Ошибка валидации"
-// @Failure 401 {object} ErrorResponse "Неверные учетные данные"
+// @Failure 401 {object} ErrorResponse "Неверные учетные данные или вход временно заблокирован после неудачных попыток"
+// @Failure 403 {object} ErrorResponse "Пользователь заблокирован или email не подтвержден (EMAIL_VERIFICATION_REQUIRED)"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/v1/auth/login [post]
 func (s *Service) login(c *fiber.Ctx) error {
@@ -74,26 +136,31 @@ func (s *Service) login(c *fiber.Ctx) error {
 	input := auth.LoginInput{
 		Email:    req.Email,
 		Password: req.Password,
+		Device:   requestDevice(c),
 	}
 
-	tokens, err := s.authService.Login(c.Context(), input)
+	result, err := s.authService.Login(c.Context(), input)
 	if err != nil {
 		s.logger.Warn("Login failed", zap.Error(err), zap.String("email", req.Email))
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-			"code":  fiber.StatusUnauthorized,
+		return sendError(c, err)
+	}
+
+	// Токены выдаются только после ввода кода второго фактора
+	if result.MFA != nil {
+		return c.Status(fiber.StatusAccepted).JSON(MFARequiredResponse{
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    result.MFA.Token,
+			ExpiresAt:   result.MFA.ExpiresAt.UTC().Format(time.RFC3339),
 		})
 	}
 
-	// Устанавливаем куки с access токеном
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    tokens.AccessToken,
-		HTTPOnly: true,
-		Secure:   s.config.ServerHost != "localhost", // Secure только для продакшена
-		SameSite: "Lax",
-		MaxAge:   int(s.config.JWTExpiration.Seconds()),
-	})
+	return s.sendLoginTokens(c, result.Tokens)
+}
+
+// sendLoginTokens устанавливает куки с access токеном и отправляет пару токенов
+func (s *Service) sendLoginTokens(c *fiber.Ctx, tokens *domain.AuthTokens) error {
+	s.setAccessTokenCookie(c, tokens.AccessToken)
 
 	return c.JSON(fiber.Map{
 		"message": "Login successful",
@@ -104,16 +171,27 @@ func (s *Service) login(c *fiber.Ctx) error {
 	})
 }
 
+// sendLoginError отправляет ошибку проверки пароля или второго фактора; при блокировке входа подсказывает клиенту, когда можно повторить попытку
+func (s *Service) sendLoginError(c *fiber.Ctx, err error) error {
+	var locked *auth.LockedError
+	if errors.As(err, &locked) {
+		retryAfter := math.Ceil(time.Until(locked.Until).Seconds())
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(int(retryAfter), 1)))
+	}
+	return sendError(c, err)
+}
+
 // register регистрирует нового пользователя
 // @Summary Зарегистрироваться
-// @Description Регистрирует нового пользователя в системе
+// @Description Регистрирует нового пользователя в системе и отправляет письмо со ссылкой подтверждения email
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body RegisterRequest true "Данные для регистрации"
 // @Success 201 {object} RegisterResponse "Пользователь зарегистрирован"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или пароль не соответствует политике (violations)"
 // @Failure 409 {object} ErrorResponse "Пользователь уже существует"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/v1/auth/register [post]
 func (s *Service) register(c *fiber.Ctx) error {
@@ -130,15 +208,13 @@ func (s *Service) register(c *fiber.Ctx) error {
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
+		Locale:   requestLocale(c),
 	}
 
 	user, err := s.authService.Register(c.Context(), input)
 	if err != nil {
 		s.logger.Warn("Registration failed", zap.Error(err), zap.String("email", req.Email))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-			"code":  fiber.StatusBadRequest,
-		})
+		return sendError(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -160,6 +236,7 @@ func (s *Service) register(c *fiber.Ctx) error {
 // @Param request body ResetPasswordRequest true "Email для сброса пароля"
 // @Success 200 {object} MessageResponse "Запрос на сброс пароля создан"
 // @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/v1/auth/reset-password [post]
 func (s *Service) resetPassword(c *fiber.Ctx) error {
@@ -173,7 +250,8 @@ func (s *Service) resetPassword(c *fiber.Ctx) error {
 	}
 
 	input := auth.ResetPasswordInput{
-		Email: req.Email,
+		Email:  req.Email,
+		Locale: requestLocale(c),
 	}
 
 	err := s.authService.RequestPasswordReset(c.Context(), input)
@@ -190,124 +268,72 @@ func (s *Service) resetPassword(c *fiber.Ctx) error {
 	})
 }
 
-// getCurrentUser возвращает текущего пользователя
-// @Summary Получить текущего пользователя
-// @Description Возвращает информацию о текущем авторизованном пользователе
+// confirmResetPassword устанавливает новый пароль по токену сброса
+// @Summary Подтвердить сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену и завершает все сессии пользователя
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Success 200 {object} UserResponse "Информация о пользователе"
-// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Param request body ConfirmResetPasswordRequest true "Токен сброса и новый пароль"
+// @Success 200 {object} MessageResponse "Пароль изменен"
+// @Failure 400 {object} ErrorResponse "Неверный, истекший или использованный токен, пароль не соответствует политике (violations) или использовался недавно"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/auth/me [get]
-func (s *Service) getCurrentUser(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
-
-	// TODO: Получить пользователя из БД через UserService
-	// Пока возвращаем заглушку
-	return c.JSON(fiber.Map{
-		"user": fiber.Map{
-			"id":    userID,
-			"email": "user@example.com", // TODO: получить из БД
-			"name":  "User Name",        // TODO: получить из БД
-		},
-	})
-}
+// @Router /api/v1/auth/reset-password/confirm [post]
+func (s *Service) confirmResetPassword(c *fiber.Ctx) error {
+	var req ConfirmResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse confirm reset password request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
 
-// getUsers возвращает список пользователей
-// @Summary Получить список пользователей
-// @Description Возвращает список всех пользователей в системе
-// @Tags users
-// @Accept json
-// @Produce json
-// @Success 200 {array} UserResponse "Список пользователей"
-// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/users [get]
-func (s *Service) getUsers(c *fiber.Ctx) error {
-	// TODO: Реализовать получение пользователей
-	return c.JSON(fiber.Map{
-		"users": []fiber.Map{},
-		"total": 0,
-	})
-}
+	input := auth.ConfirmPasswordResetInput{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+		Locale:      requestLocale(c),
+	}
 
-// createUser создает нового пользователя
-// @Summary Создать пользователя
-// @Description Создает нового пользователя в системе
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param user body CreateUserRequest true "Данные пользователя"
-// @Success 201 {object} UserResponse "Пользователь создан"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации"
-// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/users [post]
-func (s *Service) createUser(c *fiber.Ctx) error {
-	// TODO: Реализовать создание пользователя
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "User created successfully",
-	})
-}
+	if err := s.authService.ConfirmPasswordReset(c.Context(), input); err != nil {
+		s.logger.Warn("Password reset confirmation failed", zap.Error(err))
+		// Любая проблема с токеном - ошибка запроса, а не авторизации
+		code := fiber.StatusBadRequest
+		if errors.Is(err, app.ErrInternalServer) {
+			code = fiber.StatusInternalServerError
+		}
+		return c.Status(code).JSON(errorBody(err, code))
+	}
 
-// getUser возвращает пользователя по ID
-// @Summary Получить пользователя
-// @Description Возвращает пользователя по его ID
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path string true "ID пользователя"
-// @Success 200 {object} UserResponse "Пользователь найден"
-// @Failure 404 {object} ErrorResponse "Пользователь не найден"
-// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/users/{id} [get]
-func (s *Service) getUser(c *fiber.Ctx) error {
-	// TODO: Реализовать получение пользователя
-	id := c.Params("id")
 	return c.JSON(fiber.Map{
-		"id":      id,
-		"message": "User details",
+		"message": "Password has been reset successfully",
 	})
 }
 
-// updateUser обновляет пользователя
-// @Summary Обновить пользователя
-// @Description Обновляет данные пользователя
-// @Tags users
+// getCurrentUser возвращает текущего пользователя
+// @Summary Получить текущего пользователя
+// @Description Возвращает информацию о текущем авторизованном пользователе
+// @Tags auth
 // @Accept json
 // @Produce json
-// @Param id path string true "ID пользователя"
-// @Param user body UpdateUserRequest true "Данные для обновления"
-// @Success 200 {object} UserResponse "Пользователь обновлен"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Success 200 {object} CurrentUserResponse "Информация о пользователе"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Пользователь деактивирован или заблокирован"
 // @Failure 404 {object} ErrorResponse "Пользователь не найден"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/users/{id} [put]
-func (s *Service) updateUser(c *fiber.Ctx) error {
-	// TODO: Реализовать обновление пользователя
-	id := c.Params("id")
-	return c.JSON(fiber.Map{
-		"id":      id,
-		"message": "User updated successfully",
-	})
-}
+// @Router /api/v1/auth/me [get]
+func (s *Service) getCurrentUser(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
 
-// deleteUser удаляет пользователя
-// @Summary Удалить пользователя
-// @Description Удаляет пользователя из системы
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path string true "ID пользователя"
-// @Success 200 {object} MessageResponse "Пользователь удален"
-// @Failure 404 {object} ErrorResponse "Пользователь не найден"
-// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
-// @Router /api/v1/users/{id} [delete]
-func (s *Service) deleteUser(c *fiber.Ctx) error {
-	// TODO: Реализовать удаление пользователя
-	id := c.Params("id")
-	return c.JSON(fiber.Map{
-		"message": "User deleted successfully",
-		"id":      id,
+	user, err := s.authService.GetCurrentUser(c.Context(), userID)
+	if err != nil {
+		s.logger.Warn("Get current user failed", zap.Error(err), zap.String("user_id", userID))
+		return sendError(c, err)
+	}
+
+	return c.JSON(CurrentUserResponse{
+		User: toUserResponse(user),
 	})
 }
 
@@ -321,6 +347,8 @@ func (s *Service) deleteUser(c *fiber.Ctx) error {
 // @Success 200 {object} RefreshTokenResponse "Токены обновлены"
 // @Failure 400 {object} ErrorResponse "Ошибка валидации"
 // @Failure 401 {object} ErrorResponse "Неверный refresh токен"
+// @Failure 403 {object} ErrorResponse "Пользователь деактивирован или заблокирован"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/v1/auth/refresh [post]
 func (s *Service) refreshTokens(c *fiber.Ctx) error {
@@ -335,26 +363,17 @@ func (s *Service) refreshTokens(c *fiber.Ctx) error {
 
 	input := auth.RefreshTokensInput{
 		RefreshToken: req.RefreshToken,
+		Device:       requestDevice(c),
 	}
 
 	tokens, err := s.authService.RefreshTokens(c.Context(), input)
 	if err != nil {
 		s.logger.Warn("Token refresh failed", zap.Error(err))
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-			"code":  fiber.StatusUnauthorized,
-		})
+		return sendError(c, err)
 	}
 
 	// Устанавливаем куки с новым access токеном
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    tokens.AccessToken,
-		HTTPOnly: true,
-		Secure:   s.config.ServerHost != "localhost", // Secure только для продакшена
-		SameSite: "Lax",
-		MaxAge:   int(s.config.JWTExpiration.Seconds()),
-	})
+	s.setAccessTokenCookie(c, tokens.AccessToken)
 
 	return c.JSON(fiber.Map{
 		"message": "Tokens refreshed successfully",
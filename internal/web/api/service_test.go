@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestService_register(t *testing.T) {
+	const body = `{"name":"Anna","email":"anna@example.com","password":"Kx9!vQ2#mZ"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(users *mock.MockUserRepository, verifications *mock.MockEmailVerificationRepository, mailer *mock.MockMailer)
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name: "registered",
+			body: body,
+			setup: func(users *mock.MockUserRepository, verifications *mock.MockEmailVerificationRepository, mailer *mock.MockMailer) {
+				users.EXPECT().GetUserByEmail(gomock.Any(), "anna@example.com").Return(nil, domain.ErrUserNotFound)
+				users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+				verifications.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).Return(nil)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantStatus: fiber.StatusCreated,
+		},
+		{
+			name: "email taken",
+			body: body,
+			setup: func(users *mock.MockUserRepository, _ *mock.MockEmailVerificationRepository, _ *mock.MockMailer) {
+				users.EXPECT().GetUserByEmail(gomock.Any(), "anna@example.com").Return(&domain.User{ID: "u1"}, nil)
+			},
+			wantStatus: fiber.StatusConflict,
+			wantError:  "user already exists",
+		},
+		{
+			name: "concurrent registration with same email",
+			body: body,
+			setup: func(users *mock.MockUserRepository, _ *mock.MockEmailVerificationRepository, _ *mock.MockMailer) {
+				users.EXPECT().GetUserByEmail(gomock.Any(), "anna@example.com").Return(nil, domain.ErrUserNotFound)
+				users.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(domain.ErrUserExists)
+			},
+			wantStatus: fiber.StatusConflict,
+			wantError:  "user already exists",
+		},
+		{
+			name:       "weak password",
+			body:       `{"name":"Anna","email":"anna@example.com","password":"short"}`,
+			setup:      func(*mock.MockUserRepository, *mock.MockEmailVerificationRepository, *mock.MockMailer) {},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			setup:      func(*mock.MockUserRepository, *mock.MockEmailVerificationRepository, *mock.MockMailer) {},
+			wantStatus: fiber.StatusBadRequest,
+			wantError:  "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			users := mock.NewMockUserRepository(ctrl)
+			verifications := mock.NewMockEmailVerificationRepository(ctrl)
+			mailer := mock.NewMockMailer(ctrl)
+			metrics := mock.NewMockMetrics(ctrl)
+			metrics.EXPECT().RecordUserRegistration(gomock.Any()).AnyTimes()
+			tt.setup(users, verifications, mailer)
+
+			cfg := &config.Config{MinPasswordLength: 8}
+			authService := auth.NewService(users, nil, nil, nil, verifications, nil, nil, nil, nil, nil, mailer, nil, metrics, cfg.PasswordPolicy(), cfg, zap.NewNop())
+			s := NewService(cfg, zap.NewNop(), authService, nil, nil, nil)
+
+			app := fiber.New()
+			app.Post("/register", s.register)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/register", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantError != "" {
+				var got ErrorResponse
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				assert.Equal(t, tt.wantError, got.Error)
+				assert.Equal(t, tt.wantStatus, got.Code)
+			}
+		})
+	}
+}
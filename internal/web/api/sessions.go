@@ -0,0 +1,75 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// getSessions возвращает активные сессии текущего пользователя
+// @Summary Получить активные сессии
+// @Description Возвращает список активных сессий пользователя с данными устройств, текущая сессия помечена флагом current
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} SessionsListResponse "Список сессий"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/sessions [get]
+func (s *Service) getSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	currentSessionID, _ := c.Locals("session_id").(string)
+
+	sessions, err := s.authService.ListSessions(c.Context(), userID)
+	if err != nil {
+		s.logger.Warn("List sessions failed", zap.Error(err), zap.String("user_id", userID))
+		return sendError(c, err)
+	}
+
+	response := SessionsListResponse{
+		Sessions: make([]SessionResponse, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:         session.ID,
+			DeviceID:   session.DeviceID,
+			AppType:    session.AppType,
+			AppVersion: session.AppVersion,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			LastSeenAt: session.LastSeenAt.UTC().Format(time.RFC3339),
+			CreatedAt:  session.CreatedAt.UTC().Format(time.RFC3339),
+			ExpiresAt:  session.ExpiresAt.UTC().Format(time.RFC3339),
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// deleteSession завершает сессию пользователя
+// @Summary Завершить сессию
+// @Description Завершает сессию пользователя на устройстве и отзывает ее refresh и access токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сессии"
+// @Success 200 {object} MessageResponse "Сессия завершена"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 404 {object} ErrorResponse "Сессия не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (s *Service) deleteSession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	sessionID := c.Params("id")
+
+	if err := s.authService.RevokeSession(c.Context(), userID, sessionID); err != nil {
+		s.logger.Warn("Revoke session failed", zap.Error(err), zap.String("user_id", userID), zap.String("session_id", sessionID))
+		return sendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Session revoked successfully",
+	})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// loginTwoFactor завершает вход кодом второго фактора
+// @Summary Завершить вход вторым фактором
+// @Description Принимает токен ожидания из ответа /auth/login и код TOTP или одноразовый код восстановления, возвращает токены. Запрос выполняется с того же устройства (X-Device-ID), что и вход по паролю
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body LoginTwoFactorRequest true "Токен ожидания и код"
+// @Success 200 {object} LoginResponse "Успешная аутентификация"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 401 {object} ErrorResponse "Неверный или истекший токен, неверный код"
+// @Failure 403 {object} ErrorResponse "Пользователь заблокирован"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов или вход временно заблокирован после неудачных попыток, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/login/2fa [post]
+func (s *Service) loginTwoFactor(c *fiber.Ctx) error {
+	var req LoginTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse two-factor login request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	tokens, err := s.authService.LoginTwoFactor(c.Context(), auth.MFALoginInput{
+		MFAToken:     req.MFAToken,
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+		Device:       requestDevice(c),
+	})
+	if err != nil {
+		s.logger.Warn("Two-factor login failed", zap.Error(err))
+		return s.sendLoginError(c, err)
+	}
+
+	return s.sendLoginTokens(c, tokens)
+}
+
+// getTwoFactorStatus возвращает состояние двухфакторной аутентификации текущего пользователя
+// @Summary Состояние двухфакторной аутентификации
+// @Description Возвращает, включена ли двухфакторная аутентификация, и число оставшихся кодов восстановления
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} TwoFactorStatusResponse "Состояние двухфакторной аутентификации"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/2fa [get]
+func (s *Service) getTwoFactorStatus(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	status, err := s.authService.TwoFactorStatus(c.Context(), userID)
+	if err != nil {
+		s.logger.Warn("Get two-factor status failed", zap.Error(err), zap.String("user_id", userID))
+		return sendError(c, err)
+	}
+
+	return c.JSON(TwoFactorStatusResponse{
+		Enabled:                status.Enabled,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// enrollTwoFactor начинает подключение приложения-аутентификатора
+// @Summary Подключить TOTP
+// @Description Генерирует новый секрет TOTP и otpauth URI для QR-кода. Двухфакторная аутентификация включается после подтверждения кодом в /auth/2fa/confirm
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} TOTPEnrollmentResponse "Секрет TOTP"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 409 {object} ErrorResponse "Двухфакторная аутентификация уже включена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/2fa/enroll [post]
+func (s *Service) enrollTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	enrollment, err := s.authService.EnrollTOTP(c.Context(), userID)
+	if err != nil {
+		s.logger.Warn("TOTP enrollment failed", zap.Error(err), zap.String("user_id", userID))
+		return sendError(c, err)
+	}
+
+	return c.JSON(TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// confirmTwoFactor включает двухфакторную аутентификацию
+// @Summary Подтвердить TOTP
+// @Description Проверяет код из приложения-аутентификатора, включает двухфакторную аутентификацию и возвращает одноразовые коды восстановления. Коды показываются только один раз
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Код TOTP"
+// @Success 200 {object} RecoveryCodesResponse "Двухфакторная аутентификация включена"
+// @Failure 400 {object} ErrorResponse "Подключение не начато"
+// @Failure 401 {object} ErrorResponse "Не авторизован или неверный код"
+// @Failure 409 {object} ErrorResponse "Двухфакторная аутентификация уже включена"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/2fa/confirm [post]
+func (s *Service) confirmTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse confirm two-factor request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	codes, err := s.authService.ConfirmTOTP(c.Context(), userID, req.Code)
+	if err != nil {
+		s.logger.Warn("TOTP confirmation failed", zap.Error(err), zap.String("user_id", userID))
+		return sendError(c, err)
+	}
+
+	return c.JSON(RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// regenerateRecoveryCodes выдает новые коды восстановления
+// @Summary Перевыпустить коды восстановления
+// @Description Заменяет все коды восстановления новыми после проверки кода TOTP
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Код TOTP"
+// @Success 200 {object} RecoveryCodesResponse "Новые коды восстановления"
+// @Failure 400 {object} ErrorResponse "Двухфакторная аутентификация не включена"
+// @Failure 401 {object} ErrorResponse "Не авторизован или неверный код"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов или вход временно заблокирован после неудачных попыток, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (s *Service) regenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse regenerate recovery codes request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	codes, err := s.authService.RegenerateRecoveryCodes(c.Context(), userID, req.Code)
+	if err != nil {
+		s.logger.Warn("Recovery codes regeneration failed", zap.Error(err), zap.String("user_id", userID))
+		return s.sendLoginError(c, err)
+	}
+
+	return c.JSON(RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// disableTwoFactor отключает двухфакторную аутентификацию
+// @Summary Отключить двухфакторную аутентификацию
+// @Description Отключает двухфакторную аутентификацию после проверки пароля и кода TOTP или кода восстановления
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorRequest true "Пароль и код"
+// @Success 200 {object} MessageResponse "Двухфакторная аутентификация отключена"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или двухфакторная аутентификация не включена"
+// @Failure 401 {object} ErrorResponse "Не авторизован, неверный пароль или код"
+// @Failure 429 {object} ErrorResponse "Слишком много запросов или вход временно заблокирован после неудачных попыток, см. Retry-After"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/2fa/disable [post]
+func (s *Service) disableTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse disable two-factor request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	err := s.authService.DisableTwoFactor(c.Context(), auth.DisableTwoFactorInput{
+		UserID:       userID,
+		Password:     req.Password,
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+	})
+	if err != nil {
+		s.logger.Warn("Disable two-factor failed", zap.Error(err), zap.String("user_id", userID))
+		return s.sendLoginError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// setUserRole меняет роль пользователя
+// @Summary Сменить роль пользователя
+// @Description Назначает пользователю роль и завершает все его сессии, чтобы токены с прежней ролью перестали действовать (требуется роль admin, свою роль сменить нельзя)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param role body SetUserRoleRequest true "Новая роль"
+// @Success 200 {object} UserResponse "Роль изменена"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или смена собственной роли"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Требуется роль admin"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{id}/role [put]
+func (s *Service) setUserRole(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req SetUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse set user role request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	adminID, _ := c.Locals("user_id").(string)
+	user, err := s.authService.SetUserRole(c.Context(), auth.SetUserRoleInput{
+		UserID:    id,
+		Role:      domain.UserRole(req.Role),
+		ChangedBy: adminID,
+	})
+	if err != nil {
+		s.logger.Warn("Set user role failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
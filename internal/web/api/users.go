@@ -0,0 +1,367 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/TeDenis/bukhindor-backend/internal/service/users"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// getUsers возвращает список пользователей
+// @Summary Получить список пользователей
+// @Description Возвращает список пользователей с пагинацией, сортировкой и фильтрацией
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(20)
+// @Param sort query string false "Поле сортировки (created_at, updated_at, email, name), префикс '-' для убывания" default(-created_at)
+// @Param status query string false "Фильтр по статусу (active, inactive, banned)"
+// @Param email query string false "Фильтр по началу email"
+// @Success 200 {object} UsersListResponse "Список пользователей"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Router /api/v1/users [get]
+func (s *Service) getUsers(c *fiber.Ctx) error {
+	input := users.ListUsersInput{
+		Page:        c.QueryInt("page", 1),
+		Limit:       c.QueryInt("limit", 0),
+		Sort:        c.Query("sort"),
+		Status:      domain.UserStatus(c.Query("status")),
+		EmailPrefix: c.Query("email"),
+	}
+
+	result, err := s.usersService.ListUsers(c.Context(), input)
+	if err != nil {
+		s.logger.Warn("Failed to list users", zap.Error(err))
+		return sendError(c, err)
+	}
+
+	response := UsersListResponse{
+		Users: make([]UserResponse, 0, len(result.Users)),
+		Total: result.Total,
+		Page:  result.Page,
+		Limit: result.Limit,
+	}
+	for _, user := range result.Users {
+		response.Users = append(response.Users, toUserResponse(user))
+	}
+
+	return c.JSON(response)
+}
+
+// createUser создает нового пользователя
+// @Summary Создать пользователя
+// @Description Создает нового пользователя в системе; роль, отличную от user, может назначить только admin
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body CreateUserRequest true "Данные пользователя"
+// @Success 201 {object} UserResponse "Пользователь создан"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или пароль не соответствует политике (violations)"
+// @Failure 409 {object} ErrorResponse "Пользователь уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или назначение роли не администратором"
+// @Router /api/v1/users [post]
+func (s *Service) createUser(c *fiber.Ctx) error {
+	var req CreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse create user request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	input := users.CreateUserInput{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     domain.UserRole(req.Role),
+		Status:   domain.UserStatus(req.Status),
+	}
+	input.CreatedByRole, _ = c.Locals("role").(domain.UserRole)
+
+	user, err := s.usersService.CreateUser(c.Context(), input)
+	if err != nil {
+		s.logger.Warn("Create user failed", zap.Error(err), zap.String("email", req.Email))
+		return sendError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toUserResponse(user))
+}
+
+// getUser возвращает пользователя по ID
+// @Summary Получить пользователя
+// @Description Возвращает пользователя по его ID
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} UserResponse "Пользователь найден"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Router /api/v1/users/{id} [get]
+func (s *Service) getUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, err := s.usersService.GetUser(c.Context(), id)
+	if err != nil {
+		s.logger.Debug("Get user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
+
+// updateUser обновляет пользователя
+// @Summary Обновить пользователя
+// @Description Обновляет данные пользователя; роль меняется через PUT /api/v1/users/{id}/role. Деактивация завершает все сессии пользователя и отзывает выданные токены
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param user body UpdateUserRequest true "Данные для обновления"
+// @Success 200 {object} UserResponse "Пользователь обновлен"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации или попытка сменить роль"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Email уже занят"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или изменение администратора не администратором"
+// @Router /api/v1/users/{id} [put]
+func (s *Service) updateUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse update user request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	// Смена роли отзывает токены пользователя и требует роли admin, поэтому вынесена в отдельную ручку
+	if req.Role != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role is changed via PUT /api/v1/users/{id}/role",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	input := users.UpdateUserInput{
+		Name:  req.Name,
+		Email: req.Email,
+	}
+	if req.Status != nil {
+		status := domain.UserStatus(*req.Status)
+		input.Status = &status
+	}
+	input.UpdatedByRole, _ = c.Locals("role").(domain.UserRole)
+
+	user, err := s.usersService.UpdateUser(c.Context(), id, input)
+	if err != nil {
+		s.logger.Warn("Update user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
+
+// deleteUser удаляет пользователя
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя из системы и отзывает все его токены
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} MessageResponse "Пользователь удален"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или удаление администратора не администратором"
+// @Router /api/v1/users/{id} [delete]
+func (s *Service) deleteUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	role, _ := c.Locals("role").(domain.UserRole)
+
+	if err := s.usersService.DeleteUser(c.Context(), id, role); err != nil {
+		s.logger.Warn("Delete user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User deleted successfully",
+	})
+}
+
+// revokeUserSessions завершает все сессии пользователя
+// @Summary Завершить сессии пользователя
+// @Description Завершает все сессии пользователя на всех устройствах (требуется право sessions:revoke)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} MessageResponse "Сессии завершены"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{id}/sessions [delete]
+func (s *Service) revokeUserSessions(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := s.authService.RevokeUserSessions(c.Context(), id); err != nil {
+		s.logger.Warn("Revoke user sessions failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User sessions revoked successfully",
+	})
+}
+
+// banUser блокирует пользователя
+// @Summary Заблокировать пользователя
+// @Description Блокирует пользователя бессрочно или до указанного времени и завершает все его сессии (требуется право users:ban)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param ban body BanUserRequest true "Причина и срок блокировки"
+// @Success 200 {object} UserResponse "Пользователь заблокирован"
+// @Failure 400 {object} ErrorResponse "Ошибка валидации"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или блокировка администратора не администратором"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Пользователь деактивирован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{id}/ban [post]
+func (s *Service) banUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req BanUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		s.logger.Warn("Failed to parse ban user request", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"code":  fiber.StatusBadRequest,
+		})
+	}
+
+	bannedBy, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(domain.UserRole)
+	user, err := s.authService.BanUser(c.Context(), auth.BanUserInput{
+		UserID:       id,
+		BannedBy:     bannedBy,
+		BannedByRole: role,
+		Reason:       strings.TrimSpace(req.Reason),
+		Until:        req.Until,
+	})
+	if err != nil {
+		s.logger.Warn("Ban user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
+
+// unbanUser снимает блокировку с пользователя
+// @Summary Разблокировать пользователя
+// @Description Снимает блокировку с пользователя (требуется право users:ban)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} UserResponse "Пользователь разблокирован"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или разблокировка администратора не администратором"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{id}/unban [post]
+func (s *Service) unbanUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	role, _ := c.Locals("role").(domain.UserRole)
+
+	user, err := s.authService.UnbanUser(c.Context(), id, role)
+	if err != nil {
+		s.logger.Warn("Unban user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
+
+// unlockUser снимает блокировку входа после неудачных попыток
+// @Summary Снять блокировку входа
+// @Description Сбрасывает счетчик неудачных попыток входа и снимает временную блокировку входа (требуется право users:ban)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} UserResponse "Блокировка входа снята"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Недостаточно прав или снятие блокировки входа администратора не администратором"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/v1/users/{id}/unlock [post]
+func (s *Service) unlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	role, _ := c.Locals("role").(domain.UserRole)
+
+	user, err := s.authService.UnlockUser(c.Context(), id, role)
+	if err != nil {
+		s.logger.Warn("Unlock user failed", zap.Error(err), zap.String("user_id", id))
+		return sendError(c, err)
+	}
+
+	return c.JSON(toUserResponse(user))
+}
+
+// toUserResponse преобразует доменного пользователя в ответ API
+func toUserResponse(user *domain.User) UserResponse {
+	now := time.Now()
+	response := UserResponse{
+		ID:                  user.ID,
+		Email:               user.Email,
+		Name:                user.Name,
+		Role:                string(user.Role),
+		Status:              string(app.CurrentUserStatus(user, now)),
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		TwoFactorEnabled:    user.TOTPEnabled,
+		CreatedAt:           user.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:           user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+
+	if user.EmailVerifiedAt != nil {
+		response.EmailVerified = true
+		response.EmailVerifiedAt = user.EmailVerifiedAt.UTC().Format(time.RFC3339)
+	}
+
+	// Срок блокировки входа показываем, только пока он не истек
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		response.LockedUntil = user.LockedUntil.UTC().Format(time.RFC3339)
+	}
+
+	// Данные блокировки показываем только для действующей блокировки
+	if response.Status == string(domain.UserStatusBanned) {
+		response.BanReason = user.BanReason
+		response.BannedBy = user.BannedBy
+		if user.BannedUntil != nil {
+			response.BannedUntil = user.BannedUntil.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+)
+
+// tokenStatusCacheSize ограничивает число записей в кеше статусов токенов
+const tokenStatusCacheSize = 10000
+
+// tokenStatusEntry хранит статус токена и момент устаревания записи
+type tokenStatusEntry struct {
+	userID    string
+	status    domain.AccessTokenStatus
+	expiresAt time.Time
+}
+
+// tokenStatusCache кеширует статус отзыва access токенов в памяти процесса,
+// чтобы не обращаться к Redis на каждый запрос
+type tokenStatusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]tokenStatusEntry
+	// evictedAt хранит момент последнего сброса записей пользователя, чтобы не сохранить
+	// статус, прочитанный из Redis до отзыва, но записанный в кеш уже после сброса
+	evictedAt map[string]time.Time
+}
+
+// newTokenStatusCache создает кеш; при ttl <= 0 кеш отключен
+func newTokenStatusCache(ttl time.Duration) *tokenStatusCache {
+	return &tokenStatusCache{
+		ttl:       ttl,
+		entries:   make(map[string]tokenStatusEntry),
+		evictedAt: make(map[string]time.Time),
+	}
+}
+
+// get возвращает закешированный статус токена
+func (c *tokenStatusCache) get(tokenID string, now time.Time) (domain.AccessTokenStatus, bool) {
+	if c.ttl <= 0 {
+		return domain.AccessTokenStatus{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[tokenID]
+	if !ok || now.After(entry.expiresAt) {
+		return domain.AccessTokenStatus{}, false
+	}
+	return entry.status, true
+}
+
+// set сохраняет статус токена, запрошенный в момент now, освобождая место при переполнении
+func (c *tokenStatusCache) set(userID, tokenID string, status domain.AccessTokenStatus, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Статус прочитан до отзыва, выполненного этим процессом, и уже может быть устаревшим
+	if evictedAt, ok := c.evictedAt[userID]; ok && !now.After(evictedAt) {
+		return
+	}
+
+	if len(c.entries) >= tokenStatusCacheSize {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		// Все записи свежие - сбрасываем кеш целиком, это лишь несколько лишних запросов в Redis
+		if len(c.entries) >= tokenStatusCacheSize {
+			c.entries = make(map[string]tokenStatusEntry)
+		}
+	}
+
+	c.entries[tokenID] = tokenStatusEntry{userID: userID, status: status, expiresAt: now.Add(c.ttl)}
+}
+
+// evict удаляет закешированный статус токена tokenID, а при пустом tokenID - всех токенов пользователя
+func (c *tokenStatusCache) evict(userID, tokenID string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.evictedAt) >= tokenStatusCacheSize {
+		// Запросы, начатые раньше ttl назад, уже завершились, их отметки больше не нужны
+		for id, evictedAt := range c.evictedAt {
+			if now.Sub(evictedAt) > c.ttl {
+				delete(c.evictedAt, id)
+			}
+		}
+	}
+	c.evictedAt[userID] = now
+
+	if tokenID != "" {
+		delete(c.entries, tokenID)
+		return
+	}
+	for id, entry := range c.entries {
+		if entry.userID == userID {
+			delete(c.entries, id)
+		}
+	}
+}
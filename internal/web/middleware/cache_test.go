@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenStatusCache_Evict(t *testing.T) {
+	active := domain.AccessTokenStatus{}
+
+	t.Run("token evicted", func(t *testing.T) {
+		cache := newTokenStatusCache(time.Minute)
+		cache.set("u1", "t1", active, time.Now())
+		cache.set("u1", "t2", active, time.Now())
+
+		cache.evict("u1", "t1")
+
+		_, ok := cache.get("t1", time.Now())
+		assert.False(t, ok)
+		_, ok = cache.get("t2", time.Now())
+		assert.True(t, ok)
+	})
+
+	t.Run("all user tokens evicted", func(t *testing.T) {
+		cache := newTokenStatusCache(time.Minute)
+		cache.set("u1", "t1", active, time.Now())
+		cache.set("u1", "t2", active, time.Now())
+		cache.set("u2", "t3", active, time.Now())
+
+		cache.evict("u1", "")
+
+		_, ok := cache.get("t1", time.Now())
+		assert.False(t, ok)
+		_, ok = cache.get("t2", time.Now())
+		assert.False(t, ok)
+		_, ok = cache.get("t3", time.Now())
+		assert.True(t, ok)
+	})
+
+	t.Run("status read before eviction not cached", func(t *testing.T) {
+		cache := newTokenStatusCache(time.Minute)
+		requestedAt := time.Now()
+
+		cache.evict("u1", "")
+		cache.set("u1", "t1", active, requestedAt)
+
+		_, ok := cache.get("t1", time.Now())
+		assert.False(t, ok)
+	})
+}
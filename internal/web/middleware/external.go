@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenKeyProvider определяет интерфейс поиска ключа проверки подписи JWT
+type TokenKeyProvider interface {
+	Keyfunc(token *jwt.Token) (interface{}, error)
+}
+
+// TokenRevocationChecker определяет интерфейс проверки отзыва access токенов и блокировки пользователей.
+// OnAccessTokenRevoked подписывает на отзывы, выполненные этим процессом, чтобы сбросить их в локальном кеше
+type TokenRevocationChecker interface {
+	AccessTokenStatus(ctx context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error)
+	OnAccessTokenRevoked(fn func(userID, tokenID string))
+}
+
+// PermissionChecker определяет интерфейс проверки прав доступа пользователя
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, role domain.UserRole, permission domain.Permission) (bool, error)
+}
+
+// RateLimiter определяет интерфейс учета запросов в скользящем окне
+type RateLimiter interface {
+	AllowRequest(ctx context.Context, key string, limit int, window time.Duration) (*domain.RateLimitResult, error)
+}
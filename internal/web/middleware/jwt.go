@@ -1,17 +1,24 @@
 package middleware
 
 import (
+	"math"
 	"strings"
+	"time"
 
 	"github.com/TeDenis/bukhindor-backend/internal/app"
 	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
-// JWTAuth middleware проверяет JWT токен
-func JWTAuth(cfg *config.Config, logger *zap.Logger) fiber.Handler {
+// JWTAuth middleware проверяет JWT токен, его отсутствие в denylist и блокировку пользователя
+func JWTAuth(cfg *config.Config, logger *zap.Logger, keys TokenKeyProvider, revocations TokenRevocationChecker) fiber.Handler {
+	statusCache := newTokenStatusCache(cfg.JWTRevocationCacheTTL)
+	// Logout и блокировка в этом процессе сбрасывают кеш сразу; другие реплики ждут истечения ttl
+	revocations.OnAccessTokenRevoked(statusCache.evict)
+
 	return func(c *fiber.Ctx) error {
 		// Получаем токен из куки или заголовка Authorization
 		tokenString := c.Cookies(app.JWTCookieName)
@@ -33,13 +40,8 @@ func JWTAuth(cfg *config.Config, logger *zap.Logger) fiber.Handler {
 		}
 
 		// Парсим и валидируем токен
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Проверяем алгоритм подписи
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
+		// Ключ выбирается по kid, алгоритм подписи должен соответствовать типу ключа
+		token, err := jwt.Parse(tokenString, keys.Keyfunc)
 
 		if err != nil {
 			logger.Debug("JWT token validation failed", zap.Error(err))
@@ -88,10 +90,80 @@ func JWTAuth(cfg *config.Config, logger *zap.Logger) fiber.Handler {
 			})
 		}
 
-		// Сохраняем user_id в контексте
+		// Идентификатор токена для denylist; у токенов без jti используем хеш самого токена
+		tokenID, _ := claims["jti"].(string)
+		if tokenID == "" {
+			tokenID = app.HashToken(tokenString)
+		}
+
+		// Сессия, на которую выписан токен; пустая у токенов, выданных до появления claim sid
+		sessionID, _ := claims["sid"].(string)
+
+		// Проверяем отзыв токена (logout, отзыв сессии, блокировка, смена пароля). При недоступности хранилища отказываем в доступе
+		now := time.Now()
+		status, cached := statusCache.get(tokenID, now)
+		if !cached {
+			fresh, err := revocations.AccessTokenStatus(c.Context(), userID, sessionID, tokenID)
+			if err != nil {
+				logger.Error("Failed to check token revocation", zap.Error(err))
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Internal server error",
+					"code":  fiber.StatusInternalServerError,
+				})
+			}
+			status = *fresh
+			statusCache.set(userID, tokenID, status, now)
+		}
+
+		// Заблокированный пользователь теряет доступ сразу, не дожидаясь истечения токена
+		if status.UserBanned {
+			logger.Debug("Banned user token used", zap.String("user_id", userID))
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": app.ErrUserBanned.Error(),
+				"code":  fiber.StatusForbidden,
+			})
+		}
+
+		if status.Revoked || issuedBefore(claims, status.ValidAfter) {
+			logger.Debug("Revoked JWT token used", zap.String("user_id", userID))
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token has been revoked",
+				"code":  fiber.StatusUnauthorized,
+			})
+		}
+
+		// Сохраняем данные токена в контексте
 		c.Locals("user_id", userID)
+		c.Locals("token_id", tokenID)
+		if sessionID != "" {
+			c.Locals("session_id", sessionID)
+		}
+		// Токены, выданные до появления claim role, не получают роль и не проходят RequireRole
+		if role, ok := claims["role"].(string); ok {
+			c.Locals("role", domain.UserRole(role))
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Locals("token_expires_at", exp.Time)
+		} else {
+			c.Locals("token_expires_at", time.Now().Add(cfg.JWTExpiration))
+		}
 
 		logger.Debug("JWT authentication successful", zap.String("user_id", userID))
 		return c.Next()
 	}
 }
+
+// issuedBefore сообщает, выдан ли токен не позже отметки отзыва всех токенов пользователя.
+// Сравнение идет в миллисекундах: токен, выданный в ту же секунду до отзыва, тоже отзывается
+func issuedBefore(claims jwt.MapClaims, validAfter time.Time) bool {
+	if validAfter.IsZero() {
+		return false
+	}
+	// GetIssuedAt отбрасывает доли секунды, поэтому iat читается напрямую
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		// Без iat нельзя доказать, что токен выдан после отзыва
+		return true
+	}
+	return int64(math.Round(iat*1000)) <= validAfter.UnixMilli()
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testJWTSecret ключ подписи access токенов в тестах
+var testJWTSecret = []byte("test-secret")
+
+// hmacKeys проверяет подпись токенов ключом testJWTSecret
+type hmacKeys struct{}
+
+func (hmacKeys) Keyfunc(*jwt.Token) (interface{}, error) {
+	return testJWTSecret, nil
+}
+
+// fakeRevocations отзывает токены по jti и сессии и блокирует пользователей
+type fakeRevocations struct {
+	revokedTokens   map[string]bool
+	revokedSessions map[string]bool
+	bannedUsers     map[string]bool
+	onRevoked       func(userID, tokenID string)
+}
+
+func (f *fakeRevocations) AccessTokenStatus(_ context.Context, userID, sessionID, tokenID string) (*domain.AccessTokenStatus, error) {
+	return &domain.AccessTokenStatus{
+		Revoked:    f.revokedTokens[tokenID] || f.revokedSessions[sessionID],
+		UserBanned: f.bannedUsers[userID],
+	}, nil
+}
+
+func (f *fakeRevocations) OnAccessTokenRevoked(fn func(userID, tokenID string)) {
+	f.onRevoked = fn
+}
+
+// signAccessToken подписывает access токен пользователя u1 на сессию sid
+func signAccessToken(t *testing.T, jti, sid string) string {
+	t.Helper()
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "u1",
+		"sid":     sid,
+		"jti":     jti,
+		"type":    "access",
+		"iat":     now.Unix(),
+		"exp":     now.Add(time.Hour).Unix(),
+	}).SignedString(testJWTSecret)
+	require.NoError(t, err)
+	return token
+}
+
+// newJWTApp создает приложение с JWTAuth и защищенной ручкой
+func newJWTApp(revocations *fakeRevocations) *fiber.App {
+	app := fiber.New()
+	cfg := &config.Config{JWTExpiration: time.Hour, JWTRevocationCacheTTL: time.Minute}
+	app.Get("/", JWTAuth(cfg, zap.NewNop(), hmacKeys{}, revocations), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+// requestWithToken выполняет запрос к защищенной ручке и возвращает код ответа
+func requestWithToken(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestJWTAuth_Revocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		revocations *fakeRevocations
+		wantStatus  int
+	}{
+		{name: "active token", revocations: &fakeRevocations{}, wantStatus: fiber.StatusNoContent},
+		{
+			name:        "token in denylist",
+			revocations: &fakeRevocations{revokedTokens: map[string]bool{"t1": true}},
+			wantStatus:  fiber.StatusUnauthorized,
+		},
+		{
+			name:        "session revoked",
+			revocations: &fakeRevocations{revokedSessions: map[string]bool{"s1": true}},
+			wantStatus:  fiber.StatusUnauthorized,
+		},
+		{
+			name:        "other session revoked",
+			revocations: &fakeRevocations{revokedSessions: map[string]bool{"s2": true}},
+			wantStatus:  fiber.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newJWTApp(tt.revocations)
+
+			assert.Equal(t, tt.wantStatus, requestWithToken(t, app, signAccessToken(t, "t1", "s1")))
+		})
+	}
+}
+
+func TestJWTAuth_RevocationCacheEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		revoke     func(f *fakeRevocations)
+		wantStatus int
+	}{
+		{
+			name: "logout evicts revoked token",
+			revoke: func(f *fakeRevocations) {
+				f.revokedTokens["t1"] = true
+				f.onRevoked("u1", "t1")
+			},
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name: "ban evicts all user tokens",
+			revoke: func(f *fakeRevocations) {
+				f.bannedUsers["u1"] = true
+				f.onRevoked("u1", "")
+			},
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name: "other user's revocation keeps cached status",
+			revoke: func(f *fakeRevocations) {
+				f.revokedTokens["t1"] = true
+				f.onRevoked("u2", "")
+			},
+			wantStatus: fiber.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocations := &fakeRevocations{revokedTokens: map[string]bool{}, bannedUsers: map[string]bool{}}
+			app := newJWTApp(revocations)
+			token := signAccessToken(t, "t1", "s1")
+			require.Equal(t, fiber.StatusNoContent, requestWithToken(t, app, token))
+
+			tt.revoke(revocations)
+
+			assert.Equal(t, tt.wantStatus, requestWithToken(t, app, token))
+		})
+	}
+}
+
+func TestIssuedBefore(t *testing.T) {
+	validAfter := time.UnixMilli(1_700_000_000_500)
+	seconds := func(millis int64) float64 { return float64(millis) / 1000 }
+
+	tests := []struct {
+		name       string
+		claims     jwt.MapClaims
+		validAfter time.Time
+		want       bool
+	}{
+		{name: "no watermark", claims: jwt.MapClaims{"iat": seconds(1_700_000_000_000)}, want: false},
+		{name: "issued in an earlier second", claims: jwt.MapClaims{"iat": seconds(1_699_999_999_900)}, validAfter: validAfter, want: true},
+		{name: "same second before watermark", claims: jwt.MapClaims{"iat": seconds(1_700_000_000_499)}, validAfter: validAfter, want: true},
+		{name: "exactly at watermark", claims: jwt.MapClaims{"iat": seconds(1_700_000_000_500)}, validAfter: validAfter, want: true},
+		{name: "same second after watermark", claims: jwt.MapClaims{"iat": seconds(1_700_000_000_501)}, validAfter: validAfter, want: false},
+		{name: "whole-second iat of the watermark second", claims: jwt.MapClaims{"iat": float64(1_700_000_000)}, validAfter: validAfter, want: true},
+		{name: "issued in a later second", claims: jwt.MapClaims{"iat": float64(1_700_000_001)}, validAfter: validAfter, want: false},
+		{name: "missing iat", claims: jwt.MapClaims{}, validAfter: validAfter, want: true},
+		{name: "non-numeric iat", claims: jwt.MapClaims{"iat": "1700000001"}, validAfter: validAfter, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, issuedBefore(tt.claims, tt.validAfter))
+		})
+	}
+}
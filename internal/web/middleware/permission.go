@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequirePermission middleware пропускает запрос, только если у пользователя есть все перечисленные права.
+// Должен подключаться после JWTAuth
+func RequirePermission(logger *zap.Logger, checker PermissionChecker, permissions ...domain.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("user_id").(string)
+		role, _ := c.Locals("role").(domain.UserRole)
+
+		for _, permission := range permissions {
+			allowed, err := checker.HasPermission(c.Context(), userID, role, permission)
+			if err != nil {
+				logger.Error("Failed to check permission", zap.Error(err), zap.String("permission", string(permission)))
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Internal server error",
+					"code":  fiber.StatusInternalServerError,
+				})
+			}
+
+			if !allowed {
+				logger.Debug("Access denied by permission",
+					zap.String("user_id", userID),
+					zap.String("permission", string(permission)),
+					zap.String("path", c.Path()),
+				)
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Insufficient permissions",
+					"code":  fiber.StatusForbidden,
+				})
+			}
+		}
+
+		return c.Next()
+	}
+}
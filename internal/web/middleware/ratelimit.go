@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RateLimitKey определяет, по какому признаку считаются запросы
+type RateLimitKey string
+
+// Признаки для учета запросов
+const (
+	RateLimitByIP     RateLimitKey = "ip"
+	RateLimitByDevice RateLimitKey = "device"
+	RateLimitByEmail  RateLimitKey = "email" // email из JSON или формы в теле запроса, приведенный к нижнему регистру
+)
+
+// RateLimitRule описывает лимит ручки; лимит применяется к каждому признаку отдельно
+type RateLimitRule struct {
+	Name  string
+	Limit config.RateLimit
+	Keys  []RateLimitKey
+}
+
+// RateLimit middleware ограничивает частоту запросов в скользящем окне и отвечает 429 с Retry-After.
+// При недоступности Redis пропускает или отклоняет запросы в зависимости от RATE_LIMIT_FAIL_OPEN
+func RateLimit(cfg *config.Config, logger *zap.Logger, limiter RateLimiter, rule RateLimitRule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !rule.Limit.Enabled() || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		var strictest *domain.RateLimitResult
+		for _, key := range rule.Keys {
+			value := rateLimitValue(c, key)
+			if value == "" {
+				continue
+			}
+
+			result, err := limiter.AllowRequest(c.Context(), rule.Name+":"+string(key)+":"+value, rule.Limit.Requests, rule.Limit.Window)
+			if err != nil {
+				if cfg.RateLimitFailOpen {
+					logger.Warn("Rate limiter unavailable, request allowed", zap.Error(err), zap.String("rule", rule.Name))
+					return c.Next()
+				}
+				logger.Error("Rate limiter unavailable, request rejected", zap.Error(err), zap.String("rule", rule.Name))
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+					"error": "Service temporarily unavailable",
+					"code":  fiber.StatusServiceUnavailable,
+				})
+			}
+
+			if strictest == nil || !result.Allowed || result.Remaining < strictest.Remaining {
+				strictest = result
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+
+		if strictest == nil {
+			return c.Next()
+		}
+
+		setRateLimitHeaders(c, strictest)
+
+		if !strictest.Allowed {
+			logger.Warn("Rate limit exceeded", zap.String("rule", rule.Name), zap.String("ip", c.IP()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(strictest.ResetAfter)))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": app.ErrTooManyRequests.Error(),
+				"code":  fiber.StatusTooManyRequests,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// rateLimitValue возвращает значение признака запроса; пустая строка - признак отсутствует
+func rateLimitValue(c *fiber.Ctx, key RateLimitKey) string {
+	switch key {
+	case RateLimitByIP:
+		return c.IP()
+	case RateLimitByDevice:
+		return c.Get(app.HeaderDeviceID)
+	case RateLimitByEmail:
+		var body struct {
+			Email string `json:"email"`
+		}
+		// Ручки принимают и формы, поэтому без email в JSON читаем поле формы
+		if err := json.Unmarshal(c.Body(), &body); err != nil || body.Email == "" {
+			body.Email = c.FormValue("email")
+		}
+		email := strings.ToLower(strings.TrimSpace(body.Email))
+		if email == "" {
+			return ""
+		}
+		// Хеш ограничивает длину ключа и не хранит email в Redis открытым текстом
+		return app.HashToken(email)
+	default:
+		return ""
+	}
+}
+
+// setRateLimitHeaders выставляет заголовки RateLimit-* (draft-ietf-httpapi-ratelimit-headers)
+func setRateLimitHeaders(c *fiber.Ctx, result *domain.RateLimitResult) {
+	c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
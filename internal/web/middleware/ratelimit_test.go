@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/app"
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeRateLimiter возвращает заданные результаты по префиксу ключа и запоминает запрошенные ключи
+type fakeRateLimiter struct {
+	results map[string]*domain.RateLimitResult // Префикс ключа "<rule>:<признак>" -> результат
+	err     error
+	keys    []string
+}
+
+func (f *fakeRateLimiter) AllowRequest(_ context.Context, key string, limit int, _ time.Duration) (*domain.RateLimitResult, error) {
+	f.keys = append(f.keys, key)
+	if f.err != nil {
+		return nil, f.err
+	}
+	for prefix, result := range f.results {
+		if strings.HasPrefix(key, prefix+":") {
+			return result, nil
+		}
+	}
+	return &domain.RateLimitResult{Allowed: true, Limit: limit, Remaining: limit - 1, ResetAfter: time.Minute}, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := config.RateLimit{Requests: 5, Window: time.Minute}
+
+	tests := []struct {
+		name        string
+		failOpen    bool
+		rule        RateLimitRule
+		limiter     *fakeRateLimiter
+		method      string
+		body        string
+		deviceID    string
+		contentType string       // Content-Type запроса; по умолчанию JSON
+		proxy       fiber.Config // Настройки доверенного прокси приложения
+		realIP      string       // Значение X-Real-IP
+		wantStatus  int
+		wantHeaders map[string]string
+		wantKeys    []string
+	}{
+		{
+			name:       "allowed request gets rate limit headers",
+			rule:       RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter:    &fakeRateLimiter{},
+			wantStatus: fiber.StatusOK,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "4",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "",
+			},
+			wantKeys: []string{"login:ip:0.0.0.0"},
+		},
+		{
+			name: "rejected request gets 429 and Retry-After rounded up",
+			rule: RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter: &fakeRateLimiter{results: map[string]*domain.RateLimitResult{
+				"login:ip": {Allowed: false, Limit: 5, Remaining: 0, ResetAfter: 1500 * time.Millisecond},
+			}},
+			wantStatus: fiber.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "2",
+				"Retry-After":         "2",
+			},
+			wantKeys: []string{"login:ip:0.0.0.0"},
+		},
+		{
+			name: "strictest key is reported",
+			rule: RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP, RateLimitByDevice}},
+			limiter: &fakeRateLimiter{results: map[string]*domain.RateLimitResult{
+				"login:ip":     {Allowed: true, Limit: 5, Remaining: 3, ResetAfter: time.Minute},
+				"login:device": {Allowed: true, Limit: 5, Remaining: 1, ResetAfter: 30 * time.Second},
+			}},
+			deviceID:    "device-1",
+			wantStatus:  fiber.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Remaining": "1", "RateLimit-Reset": "30"},
+			wantKeys:    []string{"login:ip:0.0.0.0", "login:device:device-1"},
+		},
+		{
+			name: "first exceeded key stops checking",
+			rule: RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP, RateLimitByDevice}},
+			limiter: &fakeRateLimiter{results: map[string]*domain.RateLimitResult{
+				"login:ip": {Allowed: false, Limit: 5, Remaining: 0, ResetAfter: 10 * time.Second},
+			}},
+			deviceID:    "device-1",
+			wantStatus:  fiber.StatusTooManyRequests,
+			wantHeaders: map[string]string{"Retry-After": "10"},
+			wantKeys:    []string{"login:ip:0.0.0.0"},
+		},
+		{
+			name: "client IP taken from trusted proxy header",
+			rule: RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			proxy: fiber.Config{
+				ProxyHeader:             "X-Real-IP",
+				EnableTrustedProxyCheck: true,
+				TrustedProxies:          []string{"0.0.0.0"},
+				EnableIPValidation:      true,
+			},
+			realIP:     "203.0.113.7",
+			limiter:    &fakeRateLimiter{},
+			wantStatus: fiber.StatusOK,
+			wantKeys:   []string{"login:ip:203.0.113.7"},
+		},
+		{
+			name: "proxy header ignored from untrusted address",
+			rule: RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			proxy: fiber.Config{
+				ProxyHeader:             "X-Real-IP",
+				EnableTrustedProxyCheck: true,
+				TrustedProxies:          []string{"10.0.0.0/8"},
+				EnableIPValidation:      true,
+			},
+			realIP:     "203.0.113.7",
+			limiter:    &fakeRateLimiter{},
+			wantStatus: fiber.StatusOK,
+			wantKeys:   []string{"login:ip:0.0.0.0"},
+		},
+		{
+			name:       "email key is hashed and normalized",
+			rule:       RateLimitRule{Name: "reset", Limit: limit, Keys: []RateLimitKey{RateLimitByEmail}},
+			limiter:    &fakeRateLimiter{},
+			body:       `{"email":"  User@Example.COM "}`,
+			wantStatus: fiber.StatusOK,
+			wantKeys:   []string{"reset:email:" + app.HashToken("user@example.com")},
+		},
+		{
+			name:        "email key read from form",
+			rule:        RateLimitRule{Name: "reset", Limit: limit, Keys: []RateLimitKey{RateLimitByEmail}},
+			limiter:     &fakeRateLimiter{},
+			contentType: fiber.MIMEApplicationForm,
+			body:        "email=+User%40Example.COM+",
+			wantStatus:  fiber.StatusOK,
+			wantKeys:    []string{"reset:email:" + app.HashToken("user@example.com")},
+		},
+		{
+			name:        "missing keys skip the limiter",
+			rule:        RateLimitRule{Name: "reset", Limit: limit, Keys: []RateLimitKey{RateLimitByDevice, RateLimitByEmail}},
+			limiter:     &fakeRateLimiter{},
+			body:        `not json`,
+			wantStatus:  fiber.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Limit": ""},
+		},
+		{
+			name:       "disabled limit skips the limiter",
+			rule:       RateLimitRule{Name: "login", Limit: config.RateLimit{}, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter:    &fakeRateLimiter{},
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:       "preflight request skips the limiter",
+			rule:       RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter:    &fakeRateLimiter{},
+			method:     fiber.MethodOptions,
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:       "limiter failure with fail-open allows request",
+			failOpen:   true,
+			rule:       RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter:    &fakeRateLimiter{err: errors.New("redis down")},
+			wantStatus: fiber.StatusOK,
+			wantKeys:   []string{"login:ip:0.0.0.0"},
+		},
+		{
+			name:        "limiter failure with fail-closed rejects request",
+			failOpen:    false,
+			rule:        RateLimitRule{Name: "login", Limit: limit, Keys: []RateLimitKey{RateLimitByIP}},
+			limiter:     &fakeRateLimiter{err: errors.New("redis down")},
+			wantStatus:  fiber.StatusServiceUnavailable,
+			wantHeaders: map[string]string{"Retry-After": "", "RateLimit-Limit": ""},
+			wantKeys:    []string{"login:ip:0.0.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{RateLimitFailOpen: tt.failOpen}
+
+			server := fiber.New(tt.proxy)
+			server.Use(RateLimit(cfg, zap.NewNop(), tt.limiter, tt.rule))
+			server.All("/", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			method := tt.method
+			if method == "" {
+				method = fiber.MethodPost
+			}
+			req := httptest.NewRequest(method, "/", strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			if tt.deviceID != "" {
+				req.Header.Set(app.HeaderDeviceID, tt.deviceID)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			resp, err := server.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			for header, want := range tt.wantHeaders {
+				assert.Equal(t, want, resp.Header.Get(header), header)
+			}
+			assert.Equal(t, tt.wantKeys, tt.limiter.keys)
+
+			if tt.wantStatus == fiber.StatusTooManyRequests {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"error":"too many requests","code":429}`, string(body))
+			}
+		})
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		want int
+	}{
+		{name: "zero", d: 0, want: 0},
+		{name: "whole seconds", d: 3 * time.Second, want: 3},
+		{name: "fraction rounds up", d: 2001 * time.Millisecond, want: 3},
+		{name: "below one second", d: time.Millisecond, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ceilSeconds(tt.d))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/TeDenis/bukhindor-backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequireRole middleware пропускает запрос, только если роль из access токена входит в список разрешенных.
+// Должен подключаться после JWTAuth
+func RequireRole(logger *zap.Logger, roles ...domain.UserRole) fiber.Handler {
+	allowed := make(map[domain.UserRole]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(domain.UserRole)
+		if !allowed[role] {
+			logger.Debug("Access denied by role",
+				zap.Any("user_id", c.Locals("user_id")),
+				zap.String("role", string(role)),
+				zap.String("path", c.Path()),
+			)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+				"code":  fiber.StatusForbidden,
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startCollector запускает периодический сбор показателей пулов PostgreSQL и Redis и числа активных сессий
+func (s *Server) startCollector() {
+	interval := s.config.MetricsCollectInterval
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.collectorCancel = cancel
+	s.collectorDone = make(chan struct{})
+
+	go func() {
+		defer close(s.collectorDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.collectStats(ctx, interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.collectStats(ctx, interval)
+			}
+		}
+	}()
+}
+
+// stopCollector останавливает сбор показателей и дожидается завершения текущего опроса
+func (s *Server) stopCollector(ctx context.Context) {
+	if s.collectorCancel == nil {
+		return
+	}
+
+	s.collectorCancel()
+	select {
+	case <-s.collectorDone:
+	case <-ctx.Done():
+		s.logger.Warn("Stats collector did not stop before shutdown")
+	}
+}
+
+// collectStats снимает показатели; запрос к БД ограничен интервалом, чтобы опросы не накапливались
+func (s *Server) collectStats(ctx context.Context, interval time.Duration) {
+	dbStat := s.db.Stat()
+	s.metrics.SetDatabaseConnections(int(dbStat.AcquiredConns()), int(dbStat.IdleConns()))
+
+	redisStat := s.redis.PoolStats()
+	s.metrics.SetRedisConnections(int(redisStat.TotalConns - redisStat.IdleConns))
+
+	queryCtx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	count, err := s.storage.CountActiveSessions(queryCtx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Failed to collect active sessions", zap.Error(err))
+		}
+		return
+	}
+	s.metrics.SetActiveSessions(count)
+}
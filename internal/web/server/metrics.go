@@ -0,0 +1,31 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/monitoring"
+	"go.uber.org/zap"
+)
+
+// newMetricsServer создает HTTP сервер с /metrics; он слушает отдельный порт и не публикуется наружу вместе с API
+func newMetricsServer(cfg *config.Config, metrics *monitoring.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	return &http.Server{
+		Addr:              ":" + cfg.MetricsPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// serveMetrics запускает сервер метрик; его падение не останавливает API
+func (s *Server) serveMetrics() {
+	s.logger.Info("Starting metrics server", zap.String("port", s.config.MetricsPort))
+	if err := s.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("Metrics server failed", zap.Error(err))
+	}
+}
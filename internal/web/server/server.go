@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -11,9 +13,15 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/jwtkeys"
+	"github.com/TeDenis/bukhindor-backend/internal/adapters/mailer"
 	"github.com/TeDenis/bukhindor-backend/internal/adapters/storage"
 	"github.com/TeDenis/bukhindor-backend/internal/config"
+	"github.com/TeDenis/bukhindor-backend/internal/monitoring"
 	"github.com/TeDenis/bukhindor-backend/internal/service/auth"
+	"github.com/TeDenis/bukhindor-backend/internal/service/authz"
+	"github.com/TeDenis/bukhindor-backend/internal/service/maintenance"
+	"github.com/TeDenis/bukhindor-backend/internal/service/users"
 	"github.com/TeDenis/bukhindor-backend/internal/web/api"
 )
 
@@ -24,31 +32,72 @@ type Server struct {
 	logger *zap.Logger
 	db     *pgxpool.Pool
 	redis  *redis.Client
+	mailer *mailer.Service
+
+	storage *storage.Service
+
+	metrics       *monitoring.Metrics
+	metricsServer *http.Server
+
+	collectorCancel context.CancelFunc
+	collectorDone   chan struct{}
+
+	maintenance *maintenance.Service
 }
 
 // New создает новый сервер
 func New(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 	// Подключаемся к базе данных (pgxpool)
-	db, err := connectDB(cfg)
+	db, err := storage.ConnectPostgres(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Подключаемся к Redis
-	redisClient, err := connectRedis(cfg)
+	redisClient, err := storage.ConnectRedis(context.Background(), cfg)
 	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	// Если сервер не удалось собрать, освобождаем уже открытые соединения и останавливаем отправку писем
+	var mailService *mailer.Service
+	started := false
+	defer func() {
+		if started {
+			return
+		}
+		if mailService != nil {
+			_ = mailService.Close(context.Background())
+		}
+		_ = redisClient.Close()
+		db.Close()
+	}()
+
+	// Запускаем сервис отправки писем
+	mailService, err = mailer.NewService(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mailer: %w", err)
+	}
+
 	// Создаем Fiber приложение
 	app := fiber.New(fiber.Config{
 		ErrorHandler: errorHandler,
+		// За обратным прокси все клиенты пришли бы с его IP и делили бы один лимит запросов
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.ProxyHeader != "",
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
+	// Метрики собираются первыми, чтобы учесть и запросы, завершившиеся паникой
+	metrics := monitoring.NewMetrics(logger)
+	app.Use(metrics.FiberMiddleware())
+
 	// Добавляем middleware
 	app.Use(recover.New())
 	// Безопасная настройка CORS: если указаны wildcard-источники, запрещаем креды
-	allowOrigins := cfg.CORSAllowedOrigins
+	allowOrigins := strings.Join(cfg.CORSAllowedOrigins, ",")
 	var allowCredentials bool
 	if allowOrigins != "*" {
 		allowCredentials = true
@@ -68,6 +117,12 @@ func New(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 		logger: logger,
 		db:     db,
 		redis:  redisClient,
+		mailer: mailService,
+
+		storage: storage.NewService(db, redisClient, cfg, logger),
+
+		metrics:       metrics,
+		metricsServer: newMetricsServer(cfg, metrics),
 	}
 
 	// Настраиваем роуты
@@ -75,26 +130,56 @@ func New(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 		return nil, err
 	}
 
+	// Запускаем сбор показателей пулов соединений и сессий
+	server.startCollector()
+
+	// Запускаем задачи обслуживания БД
+	server.maintenance = maintenance.NewService(server.storage, server.storage, server.storage, server.storage, server.storage, server.storage, metrics, cfg, logger)
+	server.maintenance.Start()
+
+	started = true
 	return server, nil
 }
 
 // setupRoutes настраивает все роуты приложения
 func (s *Server) setupRoutes() error {
-	// Создаем storage сервис
-	storageService := storage.NewService(s.db, s.redis, s.config, s.logger)
+	// Загружаем ключи подписи JWT
+	tokenKeys, err := jwtkeys.Load(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	// Политика паролей общая для самостоятельной и административной смены пароля
+	passwordPolicy := s.config.PasswordPolicy()
 
 	// Создаем auth сервис
 	authService := auth.NewService(
-		storageService,
-		storageService,
-		storageService,
-		storageService,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.storage,
+		s.mailer,
+		tokenKeys,
+		s.metrics,
+		passwordPolicy,
 		s.config,
 		s.logger,
 	)
 
+	// Создаем сервис управления пользователями
+	usersService := users.NewService(s.storage, s.storage, s.storage, s.storage, authService, passwordPolicy, s.config, s.logger)
+
+	// Создаем сервис прав доступа
+	authzService := authz.NewService(s.storage, s.storage, s.config, s.logger)
+
 	// API роуты
-	apiService := api.NewService(s.config, s.logger, authService)
+	apiService := api.NewService(s.config, s.logger, authService, usersService, authzService, s.storage)
 	apiService.SetupRoutes(s.app)
 
 	// Health check
@@ -108,68 +193,48 @@ func (s *Server) setupRoutes() error {
 	return nil
 }
 
-// Listen запускает сервер
+// Listen запускает сервер и отдельный сервер метрик на METRICS_PORT
 func (s *Server) Listen(addr string) error {
+	go s.serveMetrics()
 	return s.app.Listen(addr)
 }
 
 // ShutdownWithContext завершает работу сервера
 func (s *Server) ShutdownWithContext(ctx context.Context) error {
-	// Закрываем соединения с БД
-	if s.db != nil {
-		s.db.Close()
-	}
-
-	// Закрываем соединение с Redis
-	if s.redis != nil {
-		_ = s.redis.Close()
-	}
+	// Останавливаем HTTP сервер, чтобы не принимать новые запросы
+	err := s.app.ShutdownWithContext(ctx)
 
-	return s.app.ShutdownWithContext(ctx)
-}
+	s.stopCollector(ctx)
 
-// connectDB подключается к базе данных PostgreSQL через pgxpool
-func connectDB(cfg *config.Config) (*pgxpool.Pool, error) {
-	dsn := cfg.GetPostgresDSN()
-	var pool *pgxpool.Pool
-	var err error
-	pool, err = pgxpool.New(context.Background(), dsn)
-	if err != nil {
-		return nil, err
+	// Дожидаемся задач обслуживания до закрытия соединений с БД
+	if s.maintenance != nil {
+		if maintenanceErr := s.maintenance.Stop(ctx); maintenanceErr != nil {
+			s.logger.Warn("Maintenance jobs did not stop before shutdown", zap.Error(maintenanceErr))
+		}
 	}
-	err = pool.Ping(context.Background())
-	if err != nil {
-		pool.Close()
-		return nil, err
-	}
-	return pool, nil
-}
 
-// connectRedis подключается к Redis
-func connectRedis(cfg *config.Config) (*redis.Client, error) {
-	// Парсим Redis URL
-	opt, err := redis.ParseURL(cfg.RedisURL)
-	if err != nil {
-		return nil, err
+	if metricsErr := s.metricsServer.Shutdown(ctx); metricsErr != nil {
+		s.logger.Warn("Metrics server shutdown failed", zap.Error(metricsErr))
 	}
 
-	// Устанавливаем пароль если есть
-	if cfg.RedisPassword != "" {
-		opt.Password = cfg.RedisPassword
+	// Дожидаемся отправки писем из очереди
+	if s.mailer != nil {
+		if mailErr := s.mailer.Close(ctx); mailErr != nil {
+			s.logger.Warn("Mail queue was not drained before shutdown", zap.Error(mailErr))
+		}
 	}
 
-	// Устанавливаем номер БД
-	opt.DB = cfg.RedisDB
-
-	client := redis.NewClient(opt)
+	// Закрываем соединения с БД
+	if s.db != nil {
+		s.db.Close()
+	}
 
-	// Проверяем соединение
-	ctx := context.Background()
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, err
+	// Закрываем соединение с Redis
+	if s.redis != nil {
+		_ = s.redis.Close()
 	}
 
-	return client, nil
+	return err
 }
 
 // errorHandler обрабатывает ошибки приложения